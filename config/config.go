@@ -4,6 +4,7 @@ import (
         "log"
         "os"
         "strconv"
+        "strings"
 
         "github.com/joho/godotenv"
 )
@@ -13,8 +14,34 @@ type Config struct {
         TelegramBotToken    string
         EncryptionKey       string
         UpbitCheckInterval  int  // seconds
+        UpbitMarketCheckInterval int // seconds, market list diff detector (0 disables)
         PNLUpdateInterval   int  // seconds
         Port               string
+        AdminTelegramIDs   []int64 // Telegram users allowed to run admin commands
+        AnnouncementRulesFile string // JSON extraction rules, hot-reloaded on change (empty uses built-in rules)
+        BithumbCheckInterval int    // seconds, Bithumb notice polling (0 disables)
+        BithumbRulesFile   string   // JSON extraction rules for Bithumb notices (empty uses built-in rules)
+        UpbitProxyURLs     []string // Proxy pool for Upbit polling ("direct" entry = no proxy)
+        UpbitHotWindows    string  // Weekday KST windows polled faster, e.g. "09:00-12:00,14:00-18:00"
+        UpbitHotFactor     float64 // Interval multiplier inside hot windows
+        UpbitOvernight     string  // KST window polled slower, e.g. "01:00-07:00"
+        UpbitOvernightFactor float64 // Interval multiplier overnight
+        UpbitWeekendFactor float64 // Interval multiplier on weekends
+        UpbitHourlyBudget  int     // Max HTTP requests per listing source per hour, retries included (0 = unlimited)
+        SymbolSyncInterval int     // Seconds between Bitget contract list syncs for symbol mappings
+        ContractRefreshInterval int // Seconds between contract metadata (precision, minimums, max leverage) refreshes
+        BitgetStreamURL    string   // Private WebSocket for order, position and fill pushes ("off" = REST polling only)
+        WatchlistDeadlineMinutes int // How long coins missing on Bitget stay on the watchlist
+        WatchlistPollInterval int  // Seconds between watchlist contract checks
+        WatchlistMaxPriceMove float64 // Max % rise from contract open for watchlist entries (0 = no limit)
+        SignalWebhookSecret string  // HMAC key for POST /signals (empty disables the endpoint)
+        SignalSources      []string // External signal sources, "name" or "name:off"
+        SignalMaxSkew      int      // Seconds a signed signal stays valid
+        SignalMinConfidence float64 // Signals below this confidence (0-1) are not traded
+        DetectionQuorum    float64  // Source weight score a new listing needs before it is traded (1 = any single source)
+        DetectionQuorumWindow int   // Seconds confirmations may take after the first detection
+        DetectionSourceWeights []string // Per-source quorum weights, "source:weight" ("*:weight" for the default)
+        NoticeMaxAgeMinutes int     // Listings and risk notices announced longer ago are ignored (0 = no limit)
 }
 
 func Load() *Config {
@@ -26,8 +53,39 @@ func Load() *Config {
                 TelegramBotToken:     getEnv("TELEGRAM_BOT_TOKEN", ""),
                 EncryptionKey:        getEnv("ENCRYPTION_KEY", ""),
                 UpbitCheckInterval:   getEnvInt("UPBIT_CHECK_INTERVAL", 90), // Increased from 30s to 90s to prevent IP bans
+                UpbitMarketCheckInterval: getEnvInt("UPBIT_MARKET_CHECK_INTERVAL", 30),
                 PNLUpdateInterval:    getEnvInt("PNL_UPDATE_INTERVAL", 60),
                 Port:                getEnv("PORT", "5000"),
+                AdminTelegramIDs:     getEnvInt64List("ADMIN_TELEGRAM_IDS"),
+                AnnouncementRulesFile: getEnv("ANNOUNCEMENT_RULES_FILE", ""),
+                BithumbCheckInterval: getEnvInt("BITHUMB_CHECK_INTERVAL", 60),
+                BithumbRulesFile:     getEnv("BITHUMB_RULES_FILE", ""),
+                UpbitProxyURLs:       getEnvList("UPBIT_PROXY_URLS"),
+                UpbitHotWindows:      getEnv("UPBIT_HOT_WINDOWS", "09:00-12:00,14:00-18:00"),
+                UpbitHotFactor:       getEnvFloat("UPBIT_HOT_FACTOR", 0.33),
+                UpbitOvernight:       getEnv("UPBIT_OVERNIGHT", "01:00-07:00"),
+                UpbitOvernightFactor: getEnvFloat("UPBIT_OVERNIGHT_FACTOR", 3),
+                UpbitWeekendFactor:   getEnvFloat("UPBIT_WEEKEND_FACTOR", 2),
+                UpbitHourlyBudget:    getEnvInt("UPBIT_HOURLY_REQUEST_BUDGET", 480), // Market list at 30s x0.33 in hot windows is ~360 req/h before retries
+                SymbolSyncInterval:   getEnvInt("SYMBOL_SYNC_INTERVAL", 3600),
+                ContractRefreshInterval: getEnvInt("CONTRACT_REFRESH_INTERVAL", 300),
+                BitgetStreamURL:      getEnv("BITGET_STREAM_URL", "wss://ws.bitget.com/v2/ws/private"),
+                WatchlistDeadlineMinutes: getEnvInt("WATCHLIST_DEADLINE_MINUTES", 1440),
+                WatchlistPollInterval: getEnvInt("WATCHLIST_POLL_INTERVAL", 30),
+                WatchlistMaxPriceMove: getEnvFloat("WATCHLIST_MAX_PRICE_MOVE", 30),
+                SignalWebhookSecret:  getEnv("SIGNAL_WEBHOOK_SECRET", ""),
+                SignalSources:        getEnvList("SIGNAL_SOURCES"),
+                SignalMaxSkew:        getEnvInt("SIGNAL_MAX_SKEW", 300),
+                SignalMinConfidence:  getEnvFloat("SIGNAL_MIN_CONFIDENCE", 0),
+                DetectionQuorum:      getEnvFloat("DETECTION_QUORUM", 1),
+                DetectionQuorumWindow: getEnvInt("DETECTION_QUORUM_WINDOW", 180),
+                DetectionSourceWeights: getEnvList("DETECTION_SOURCE_WEIGHTS"),
+                NoticeMaxAgeMinutes:  getEnvInt("NOTICE_MAX_AGE_MINUTES", 60),
+        }
+        
+        // Single proxy setting from before the pool existed
+        if len(cfg.UpbitProxyURLs) == 0 && os.Getenv("UPBIT_PROXY_URL") != "" {
+                cfg.UpbitProxyURLs = []string{os.Getenv("UPBIT_PROXY_URL")}
         }
 
         if cfg.DatabaseURL == "" {
@@ -56,3 +114,38 @@ func getEnvInt(key string, defaultValue int) int {
         }
         return defaultValue
 }
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+        if value := os.Getenv(key); value != "" {
+                if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+                        return floatValue
+                }
+        }
+        return defaultValue
+}
+
+func getEnvList(key string) []string {
+        var values []string
+        for _, part := range strings.Split(os.Getenv(key), ",") {
+                if part = strings.TrimSpace(part); part != "" {
+                        values = append(values, part)
+                }
+        }
+        return values
+}
+
+func getEnvInt64List(key string) []int64 {
+        var values []int64
+        for _, part := range strings.Split(os.Getenv(key), ",") {
+                part = strings.TrimSpace(part)
+                if part == "" {
+                        continue
+                }
+                if intValue, err := strconv.ParseInt(part, 10, 64); err == nil {
+                        values = append(values, intValue)
+                } else {
+                        log.Printf("⚠️ Ignoring invalid value %q in %s", part, key)
+                }
+        }
+        return values
+}
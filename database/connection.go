@@ -69,6 +69,13 @@ func AutoMigrate() error {
         err := DB.AutoMigrate(
                 &models.User{},
                 &models.Position{},
+                &models.PositionTranche{},
+                &models.ListingEvent{},
+                &models.UpbitMarket{},
+                &models.SymbolMapping{},
+                &models.WatchlistEntry{},
+                &models.DetectionDecision{},
+                &models.TradeLatency{},
         )
         
         if err != nil {
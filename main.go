@@ -1,11 +1,14 @@
 package main
 
 import (
+        "bufio"
+        "encoding/json"
         "fmt"
         "log"
         "net/http"
         "os"
         "os/signal"
+        "strings"
         "syscall"
         "time"
         
@@ -42,7 +45,51 @@ func safeGo(name string, fn func()) {
         }()
 }
 
+// runParseCommand explains how announcement titles are parsed: `bot parse [--rules file] "<title>"` or titles on stdin, one per line
+func runParseCommand(args []string) int {
+        // --rules <file> parses with a rules file instead of the built-in rules (and validates it)
+        if len(args) >= 2 && args[0] == "--rules" {
+                if err := services.LoadAnnouncementRules(args[1]); err != nil {
+                        fmt.Fprintf(os.Stderr, "❌ Invalid rules file %s: %v\n", args[1], err)
+                        return 1
+                }
+                args = args[2:]
+        }
+        
+        titles := args
+        if len(titles) == 0 {
+                scanner := bufio.NewScanner(os.Stdin)
+                for scanner.Scan() {
+                        if title := strings.TrimSpace(scanner.Text()); title != "" {
+                                titles = append(titles, title)
+                        }
+                }
+                if err := scanner.Err(); err != nil {
+                        fmt.Fprintf(os.Stderr, "❌ Failed to read titles: %v\n", err)
+                        return 1
+                }
+        }
+        
+        if len(titles) == 0 {
+                fmt.Fprintln(os.Stderr, "usage: bot parse [--rules file] \"<announcement title>\" (or titles on stdin, one per line)")
+                return 2
+        }
+        
+        for i, title := range titles {
+                if i > 0 {
+                        fmt.Println()
+                }
+                fmt.Print(services.ExplainAnnouncement(title).String())
+        }
+        return 0
+}
+
 func main() {
+        // Developer subcommand: no config, database or Telegram needed
+        if len(os.Args) > 1 && os.Args[1] == "parse" {
+                os.Exit(runParseCommand(os.Args[2:]))
+        }
+        
         fmt.Println("🚀 Upbit-Bitget Trading Bot Starting...")
         
         // Load configuration
@@ -78,10 +125,39 @@ func main() {
                 }
         }
         
+        // Load announcement extraction rules (embedded defaults stay active if the file is missing or invalid)
+        if cfg.AnnouncementRulesFile != "" {
+                if err := services.LoadAnnouncementRules(cfg.AnnouncementRulesFile); err != nil {
+                        log.Printf("⚠️ Announcement rules file not loaded, using built-in rules: %v", err)
+                }
+                safeGo("AnnouncementRulesWatcher", func() {
+                        services.WatchAnnouncementRules(cfg.AnnouncementRulesFile, 10*time.Second, nil)
+                })
+        }
+        if cfg.BithumbRulesFile != "" {
+                if err := services.LoadBithumbAnnouncementRules(cfg.BithumbRulesFile); err != nil {
+                        log.Printf("⚠️ Bithumb rules file not loaded, using built-in rules: %v", err)
+                }
+                safeGo("BithumbRulesWatcher", func() {
+                        services.WatchBithumbAnnouncementRules(cfg.BithumbRulesFile, 10*time.Second, nil)
+                })
+        }
+        
         // Create channels for graceful shutdown
         quit := make(chan os.Signal, 1)
         signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
         
+        // Upbit requests rotate over this pool on this schedule; both are shared with the health endpoint
+        proxyPool := services.NewProxyPool(cfg.UpbitProxyURLs)
+        schedule := services.NewPollSchedule(services.PollScheduleConfig{
+                HotWindows:      cfg.UpbitHotWindows,
+                HotFactor:       cfg.UpbitHotFactor,
+                Overnight:       cfg.UpbitOvernight,
+                OvernightFactor: cfg.UpbitOvernightFactor,
+                WeekendFactor:   cfg.UpbitWeekendFactor,
+                HourlyBudget:    cfg.UpbitHourlyBudget,
+        })
+        
         // Start HTTP health check server for Replit deployment with panic recovery
         safeGo("HTTP-Server", func() {
                 http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
@@ -92,9 +168,17 @@ func main() {
                 http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
                         w.Header().Set("Content-Type", "application/json")
                         w.WriteHeader(http.StatusOK)
-                        w.Write([]byte(`{"healthy":true,"timestamp":"` + time.Now().Format(time.RFC3339) + `"}`))
+                        json.NewEncoder(w).Encode(map[string]interface{}{
+                                "healthy":   true,
+                                "timestamp": time.Now().Format(time.RFC3339),
+                                "proxies":   proxyPool.Stats(),
+                                "schedule":  schedule.Status(time.Now()),
+                        })
                 })
                 
+                // Detection and entry latency percentiles (?hours=N, default 7 days)
+                http.HandleFunc("/metrics", services.ServeLatencyMetrics)
+                
                 log.Println("🌐 HTTP health server starting on :5000")
                 if err := http.ListenAndServe(":5000", nil); err != nil {
                         log.Printf("❌ HTTP server error: %v", err)
@@ -106,15 +190,55 @@ func main() {
                 log.Println("🚀 Initializing trading services...")
                 
                 // Initialize services
-                upbitMonitor := services.NewUpbitMonitor(time.Duration(cfg.UpbitCheckInterval) * time.Second)
+                upbitMonitor := services.NewUpbitMonitor(time.Duration(cfg.UpbitCheckInterval) * time.Second, proxyPool)
+                upbitMonitor.SetSchedule(schedule)
+                upbitMonitor.SetMaxNoticeAge(time.Duration(cfg.NoticeMaxAgeMinutes) * time.Minute)
+                upbitMonitor.SetQuorum(services.NewDetectionQuorum(services.QuorumConfig{
+                        Threshold: cfg.DetectionQuorum,
+                        Window:    time.Duration(cfg.DetectionQuorumWindow) * time.Second,
+                        Weights:   services.ParseSourceWeights(cfg.DetectionSourceWeights),
+                }))
+                if cfg.UpbitMarketCheckInterval > 0 {
+                        upbitMonitor.AddMarketListSource(time.Duration(cfg.UpbitMarketCheckInterval) * time.Second)
+                }
+                if cfg.BithumbCheckInterval > 0 {
+                        upbitMonitor.AddBithumbNoticeSource(time.Duration(cfg.BithumbCheckInterval) * time.Second)
+                }
+                
+                // External scrapers push listing signals through the monitor's dedupe and trading path
+                if cfg.SignalWebhookSecret != "" {
+                        http.Handle("/signals", services.NewSignalWebhook(services.SignalWebhookConfig{
+                                Secret:        cfg.SignalWebhookSecret,
+                                Sources:       services.ParseSignalSources(cfg.SignalSources),
+                                MaxSkew:       time.Duration(cfg.SignalMaxSkew) * time.Second,
+                                MinConfidence: cfg.SignalMinConfidence,
+                        }, upbitMonitor))
+                }
                 
-                telegramBot, err := services.NewTelegramBot(cfg.TelegramBotToken, cfg.EncryptionKey, upbitMonitor)
+                // Upbit -> Bitget symbol mappings, seeded from Bitget's contract list
+                symbolMapper := services.NewSymbolMapper()
+                
+                telegramBot, err := services.NewTelegramBot(cfg.TelegramBotToken, cfg.EncryptionKey, cfg.AdminTelegramIDs, upbitMonitor, symbolMapper)
                 if err != nil {
                         log.Printf("❌ Failed to initialize Telegram bot: %v", err)
                 } else {
-                        tradingEngine := services.NewTradingEngine(upbitMonitor, telegramBot, cfg.EncryptionKey)
+                        tradingEngine := services.NewTradingEngine(upbitMonitor, telegramBot, symbolMapper, cfg.EncryptionKey)
+                        tradingEngine.SetWatchlistConfig(services.WatchlistConfig{
+                                Deadline:     time.Duration(cfg.WatchlistDeadlineMinutes) * time.Minute,
+                                PollInterval: time.Duration(cfg.WatchlistPollInterval) * time.Second,
+                                MaxPriceMove: cfg.WatchlistMaxPriceMove,
+                        })
+                        if cfg.BitgetStreamURL != "off" {
+                                tradingEngine.SetStreamURL(cfg.BitgetStreamURL)
+                        }
                         
                         // Start all services with panic recovery
+                        safeGo("SymbolMapperSync", func() {
+                                symbolMapper.StartSync(time.Duration(cfg.SymbolSyncInterval) * time.Second)
+                        })
+                        safeGo("ContractCache", func() {
+                                services.BitgetContracts.StartRefresh(time.Duration(cfg.ContractRefreshInterval) * time.Second)
+                        })
                         safeGo("UpbitMonitor", upbitMonitor.Start)
                         safeGo("TelegramBot", telegramBot.Start)
                         safeGo("TradingEngine", tradingEngine.Start)
@@ -129,15 +253,20 @@ func main() {
                         log.Printf("📊 Starting Upbit monitoring service (checking every %d seconds)...", cfg.UpbitCheckInterval)
                         
                         // Create basic UpbitMonitor for fallback mode
-                        fallbackMonitor := services.NewUpbitMonitor(time.Duration(cfg.UpbitCheckInterval) * time.Second)
+                        fallbackMonitor := services.NewUpbitMonitor(time.Duration(cfg.UpbitCheckInterval) * time.Second, proxyPool)
+                        fallbackMonitor.SetSchedule(schedule)
+                        fallbackMonitor.SetMaxNoticeAge(time.Duration(cfg.NoticeMaxAgeMinutes) * time.Minute)
+                        if cfg.UpbitMarketCheckInterval > 0 {
+                                fallbackMonitor.AddMarketListSource(time.Duration(cfg.UpbitMarketCheckInterval) * time.Second)
+                        }
                         fallbackMonitor.Start()
                 })
         }
         
         log.Printf("✅ Trading bot is running")
         log.Printf("🔗 Database: Connected and migrated")
-        log.Printf("📈 Upbit monitoring: Every %d seconds", cfg.UpbitCheckInterval)
-        log.Printf("💰 P&L updates: Every 3 minutes")
+        log.Printf("📈 Upbit monitoring: Every %d seconds (KST schedule: hot %s, overnight %s)", cfg.UpbitCheckInterval, cfg.UpbitHotWindows, cfg.UpbitOvernight)
+        log.Printf("💰 P&L updates: Every 3 minutes (REST fallback when the Bitget stream is down)")
         log.Println("Press Ctrl+C to shutdown...")
         
         // Wait for shutdown signal
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// Detection decision outcomes
+const (
+	DetectionConfirmed = "confirmed" // Quorum reached, the listing was emitted for trading
+	DetectionExpired   = "expired"   // Quorum not reached within the window, nothing traded
+)
+
+// DetectionDecision is the audit record of why a detected coin did or did not go on to trade
+type DetectionDecision struct {
+	ID                uint      `json:"id" gorm:"primaryKey"`
+	Symbol            string    `json:"symbol" gorm:"size:20;not null;index"`
+	Markets           string    `json:"markets" gorm:"size:50"` // Comma separated, merged over all confirming sources
+	Decision          string    `json:"decision" gorm:"size:20;not null;index"`
+	Score             float64   `json:"score"`     // Sum of confirming source weights
+	Threshold         float64   `json:"threshold"` // Score required at decision time
+	SourceCount       int       `json:"source_count"`
+	Sources           string    `json:"sources" gorm:"type:text"` // "source=weight" pairs in confirmation order
+	AnnouncementID    string    `json:"announcement_id" gorm:"size:100"`
+	AnnouncementTitle string    `json:"announcement_title" gorm:"type:text"`
+	FirstSeenAt       time.Time `json:"first_seen_at"`
+	DecidedAt         time.Time `json:"decided_at"`
+	WaitMs            int64     `json:"wait_ms"` // DecidedAt - FirstSeenAt
+	Reason            string    `json:"reason" gorm:"type:text"`
+	CreatedAt         time.Time `json:"created_at"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// ListingEvent records a listing (or delisting/warning) event the monitor has already emitted, so restarts don't re-trade it
+type ListingEvent struct {
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	AnnouncementID    string     `json:"announcement_id" gorm:"size:100;not null;uniqueIndex:idx_listing_events_announcement_symbol"`
+	Symbol            string     `json:"symbol" gorm:"size:20;not null;uniqueIndex:idx_listing_events_announcement_symbol;index"`
+	Exchange          string     `json:"exchange" gorm:"size:20;default:'upbit'"` // Exchange that announced it
+	Kind              string     `json:"kind" gorm:"size:20;default:'listing'"` // listing, delisting, warning
+	AnnouncementTitle string     `json:"announcement_title" gorm:"type:text"`
+	AnnouncementURL   string     `json:"announcement_url" gorm:"size:255"`
+	Source            string     `json:"source" gorm:"size:50"`
+	MarketType        string     `json:"market_type" gorm:"size:10"` // KRW, USDT, BTC
+	Markets           string     `json:"markets" gorm:"size:50"`     // Comma separated, e.g. "KRW,USDT"
+	AnnouncedAt       *time.Time `json:"announced_at,omitempty"`
+	DetectedAt        time.Time  `json:"detected_at"`
+	LatencyMs         int64      `json:"latency_ms"` // Detection latency (detected - announced)
+	FromMarketList    bool       `json:"from_market_list"`  // Detected by the market list diff
+	AnnouncementSeen  bool       `json:"announcement_seen"` // Market list detection was also seen in an announcement
+	AddedMarket       bool       `json:"added_market"`      // Coin already traded on Upbit in another market
+	TradingStartsAt   *time.Time `json:"trading_starts_at,omitempty"` // Trading start from the notice body
+	Confidence        float64    `json:"confidence,omitempty"`        // Confidence reported by an external signal source
+	CreatedAt         time.Time  `json:"created_at"`
+}
@@ -12,20 +12,42 @@ const (
 	PositionClosed PositionStatus = "closed"
 )
 
+// Reasons a position was closed
+const (
+	ExitReasonTakeProfit = "take_profit" // Take profit, on Bitget or by the monitor
+	ExitReasonStopLoss   = "stop_loss"   // Stop loss, on Bitget or by the monitor
+	ExitReasonTrailingStop = "trailing_stop" // Trailing stop, on Bitget or by the trailing watcher
+	ExitReasonRiskEvent  = "risk_event"  // Delisting or investment warning policy
+	ExitReasonManual     = "manual"      // Closed from Telegram
+	ExitReasonExternal   = "external"    // Gone on Bitget for another reason (closed in the app, liquidated)
+)
+
 type Position struct {
 	ID             uint           `json:"id" gorm:"primaryKey"`
 	PositionID     string         `json:"position_id" gorm:"uniqueIndex;size:100"` // Bitget position ID
 	UserID         uint           `json:"user_id" gorm:"not null"`
 	CoinSymbol     string         `json:"coin_symbol" gorm:"size:20;not null"`      // TOSHI, OPEN, etc.
 	Symbol         string         `json:"symbol" gorm:"size:30;not null"`           // TOSHIUSDT, OPENUSDT
+	ListingSource  string         `json:"listing_source" gorm:"size:50"`            // Source that detected the listing
+	AnnouncementID string         `json:"announcement_id" gorm:"size:100"`          // Exchange notice ID that triggered the trade
+	MarketType     string         `json:"market_type" gorm:"size:10"`               // KRW, USDT, BTC
 	EntryPrice     float64        `json:"entry_price" gorm:"type:decimal(20,8)"`
 	CurrentPrice   float64        `json:"current_price" gorm:"type:decimal(20,8)"`
-	Quantity       float64        `json:"quantity" gorm:"type:decimal(20,8)"`
+	Quantity       float64        `json:"quantity" gorm:"type:decimal(20,8)"` // Open size, reduced as take profit tranches fill
 	Leverage       int            `json:"leverage"`
 	TakeProfitPrice float64       `json:"take_profit_price" gorm:"type:decimal(20,8)"`
+	TakeProfitOrderID string      `json:"take_profit_order_id" gorm:"size:100"` // Bitget pos_profit plan order, empty when not placed
+	StopLossPrice  float64        `json:"stop_loss_price" gorm:"type:decimal(20,8);default:0"` // 0 = no stop loss
+	StopLossOrderID string        `json:"stop_loss_order_id" gorm:"size:100"`   // Bitget pos_loss plan order, empty when not placed
+	TrailingActivationPrice float64 `json:"trailing_activation_price" gorm:"type:decimal(20,8);default:0"` // Price that arms the trail
+	TrailingCallbackRate float64  `json:"trailing_callback_rate" gorm:"type:decimal(10,4);default:0"` // % below the high; 0 = no trailing stop
+	TrailingOrderID string        `json:"trailing_order_id" gorm:"size:100"`    // Bitget moving_plan order, empty when trailed locally
+	HighWaterPrice float64        `json:"high_water_price" gorm:"type:decimal(20,8);default:0"` // Highest price seen since entry
 	CurrentPNL     float64        `json:"current_pnl" gorm:"type:decimal(20,8);default:0"`
+	RealizedPNL    float64        `json:"realized_pnl" gorm:"type:decimal(20,8);default:0"` // Sum of the filled take profit tranches
 	ROE            float64        `json:"roe" gorm:"type:decimal(10,4);default:0"` // Return on Equity %
 	Status         PositionStatus `json:"status" gorm:"type:varchar(20);default:'open'"`
+	ExitReason     string         `json:"exit_reason" gorm:"size:20"` // Set when closed, see ExitReason*
 	OpenedAt       time.Time      `json:"opened_at"`
 	ClosedAt       *time.Time     `json:"closed_at,omitempty"`
 	CreatedAt      time.Time      `json:"created_at"`
@@ -33,6 +55,7 @@ type Position struct {
 	
 	// Relations
 	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Tranches []PositionTranche `json:"tranches,omitempty" gorm:"foreignKey:PositionID"`
 }
 
 // CalculatePNL calculates the current P&L and ROE
@@ -53,9 +76,57 @@ func (p *Position) CalculatePNL() {
 	}
 }
 
-// ShouldTakeProfit checks if position should be closed for take profit
+// ShouldTakeProfit checks if position should be closed for take profit (trailing positions have no fixed target)
 func (p *Position) ShouldTakeProfit() bool {
-	return p.Status == PositionOpen && p.CurrentPrice >= p.TakeProfitPrice
+	return p.Status == PositionOpen && p.TakeProfitPrice > 0 && p.CurrentPrice >= p.TakeProfitPrice
+}
+
+// UpdateHighWater records a new high, returns true when it changed
+func (p *Position) UpdateHighWater(price float64) bool {
+	if price <= p.HighWaterPrice {
+		return false
+	}
+	p.HighWaterPrice = price
+	return true
+}
+
+// TrailingArmed reports whether the price reached the trailing stop's activation price
+func (p *Position) TrailingArmed() bool {
+	return p.TrailingCallbackRate > 0 && p.HighWaterPrice >= p.TrailingActivationPrice
+}
+
+// TrailingStopPrice is the price that closes an armed trailing stop
+func (p *Position) TrailingStopPrice() float64 {
+	return p.HighWaterPrice * (1 - p.TrailingCallbackRate/100)
+}
+
+// ShouldTrailingStop checks if an armed trailing stop was hit
+func (p *Position) ShouldTrailingStop() bool {
+	return p.Status == PositionOpen && p.TrailingArmed() && p.CurrentPrice > 0 && p.CurrentPrice <= p.TrailingStopPrice()
+}
+
+// ShouldStopLoss checks if position should be closed for stop loss
+func (p *Position) ShouldStopLoss() bool {
+	return p.Status == PositionOpen && p.StopLossPrice > 0 && p.CurrentPrice > 0 && p.CurrentPrice <= p.StopLossPrice
+}
+
+// PendingTranches returns the take profit tranches that have not filled yet
+func (p *Position) PendingTranches() []*PositionTranche {
+	var pending []*PositionTranche
+	for i := range p.Tranches {
+		if p.Tranches[i].Status == TranchePending {
+			pending = append(pending, &p.Tranches[i])
+		}
+	}
+	return pending
+}
+
+// MarkClosed closes the position record with an exit reason
+func (p *Position) MarkClosed(reason string) {
+	now := time.Now()
+	p.Status = PositionClosed
+	p.ClosedAt = &now
+	p.ExitReason = reason
 }
 
 // BeforeCreate GORM hook
@@ -63,3 +134,13 @@ func (p *Position) BeforeCreate(tx *gorm.DB) error {
 	p.OpenedAt = time.Now()
 	return nil
 }
+
+// AfterSave GORM hook: tranches still pending when the position closes will never fill
+func (p *Position) AfterSave(tx *gorm.DB) error {
+	if p.Status != PositionClosed || p.ID == 0 {
+		return nil
+	}
+	return tx.Model(&PositionTranche{}).
+		Where("position_id = ? AND status = ?", p.ID, TranchePending).
+		Update("status", TrancheCancelled).Error
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+)
+
+type TrancheStatus string
+
+const (
+	TranchePending   TrancheStatus = "pending"   // Waiting for its trigger price
+	TrancheFilled    TrancheStatus = "filled"    // Closed, RealizedPNL is set
+	TrancheCancelled TrancheStatus = "cancelled" // Position closed before the trigger
+)
+
+// PositionTranche is one take profit ladder step of a position: a part of the opened size closed at its own price
+type PositionTranche struct {
+	ID           uint          `json:"id" gorm:"primaryKey"`
+	PositionID   uint          `json:"position_id" gorm:"not null;index"`
+	Step         int           `json:"step"`                                   // 1-based ladder step
+	SizePercent  float64       `json:"size_percent" gorm:"type:decimal(10,4)"` // Share of the opened size
+	Quantity     float64       `json:"quantity" gorm:"type:decimal(20,8)"`
+	TriggerPrice float64       `json:"trigger_price" gorm:"type:decimal(20,8)"`
+	OrderID      string        `json:"order_id" gorm:"size:100"` // Bitget profit_plan order, empty when closed by the monitor
+	Status       TrancheStatus `json:"status" gorm:"type:varchar(20);default:'pending'"`
+	FillPrice    float64       `json:"fill_price" gorm:"type:decimal(20,8);default:0"`
+	RealizedPNL  float64       `json:"realized_pnl" gorm:"type:decimal(20,8);default:0"`
+	FilledAt     *time.Time    `json:"filled_at,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+// MarkFilled records the tranche closed at price and returns its realized P&L (long position from entryPrice)
+func (t *PositionTranche) MarkFilled(entryPrice, price float64) float64 {
+	now := time.Now()
+	t.Status = TrancheFilled
+	t.FillPrice = price
+	t.RealizedPNL = (price - entryPrice) * t.Quantity
+	t.FilledAt = &now
+	return t.RealizedPNL
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// Symbol mapping sources, in order of precedence
+const (
+	SymbolMappingAdmin     = "admin"     // Set by an admin via Telegram, never overwritten by a sync
+	SymbolMappingContracts = "contracts" // Seeded from Bitget's USDT-M contract list
+)
+
+// SymbolMapping maps an Upbit ticker to the Bitget futures contract that trades the same coin
+type SymbolMapping struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	UpbitSymbol  string    `json:"upbit_symbol" gorm:"size:20;not null;uniqueIndex"` // TOSHI, SATS
+	BitgetSymbol string    `json:"bitget_symbol" gorm:"size:30"`                     // TOSHIUSDT, 1000SATSUSDT
+	Multiplier   float64   `json:"multiplier" gorm:"default:1"`                      // Coins per contract unit, e.g. 1000 for 1000SATS
+	Source       string    `json:"source" gorm:"size:20;not null;index"`             // admin, contracts
+	Disabled     bool      `json:"disabled"`                                         // Admin blocked trading (e.g. ticker collision)
+	Note         string    `json:"note" gorm:"size:255"`
+	UpdatedBy    int64     `json:"updated_by"` // Telegram ID of the admin who set the override
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// TradeLatency records how long a user's entry took, from the announcement to the acknowledged order,
+// and how far the fill was from the price before the announcement
+type TradeLatency struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	UserID         uint       `json:"user_id" gorm:"not null;index"`
+	OrderID        string     `json:"order_id" gorm:"size:100;index"`
+	Symbol         string     `json:"symbol" gorm:"size:20;not null;index"` // Upbit/Bithumb ticker
+	BitgetSymbol   string     `json:"bitget_symbol" gorm:"size:30"`
+	Exchange       string     `json:"exchange" gorm:"size:20"`
+	Source         string     `json:"source" gorm:"size:50"` // Listing source that triggered the trade
+	AnnouncementID string     `json:"announcement_id" gorm:"size:100"`
+	AnnouncedAt    *time.Time `json:"announced_at,omitempty"`
+	DetectedAt     time.Time  `json:"detected_at"`
+	OrderSentAt    time.Time  `json:"order_sent_at"`
+	OrderAckAt     time.Time  `json:"order_ack_at"`
+	DetectionMs    int64      `json:"detection_ms"` // DetectedAt - AnnouncedAt (0 without announcement time)
+	WaitMs         int64      `json:"wait_ms"`      // Scheduled wait for the entry time or the watchlist contract
+	DecisionMs     int64      `json:"decision_ms"`  // OrderSentAt - DetectedAt without WaitMs (quorum, mapping, leverage)
+	AckMs          int64      `json:"ack_ms"`       // OrderAckAt - OrderSentAt
+	TotalMs        int64      `json:"total_ms"`     // OrderAckAt - AnnouncedAt (or DetectedAt) without WaitMs
+	ReferencePrice float64    `json:"reference_price"` // Last Bitget price before the announcement (1m candles)
+	FillPrice      float64    `json:"fill_price"`      // Average fill price of the entry order
+	SlippagePct    float64    `json:"slippage_pct"`    // Fill price vs reference price, in percent
+	CreatedAt      time.Time  `json:"created_at" gorm:"index"`
+}
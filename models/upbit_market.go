@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// UpbitMarket is one entry of the stored Upbit market list snapshot used for diffing
+type UpbitMarket struct {
+	Market        string    `json:"market" gorm:"primaryKey;size:30"` // KRW-BTC, BTC-ETH, USDT-SOL
+	QuoteCurrency string    `json:"quote_currency" gorm:"size:10"`    // KRW, BTC, USDT
+	BaseCurrency  string    `json:"base_currency" gorm:"size:20;index"`
+	KoreanName    string    `json:"korean_name" gorm:"size:100"`
+	EnglishName   string    `json:"english_name" gorm:"size:100"`
+	FirstSeenAt   time.Time `json:"first_seen_at"`
+}
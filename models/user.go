@@ -1,9 +1,44 @@
 package models
 
 import (
+        "fmt"
+        "strconv"
+        "strings"
         "time"
 )
 
+// Risk event actions for delisting and investment warning notices
+const (
+        RiskActionClose  = "close"  // Flash close open positions in the coin
+        RiskActionNotify = "notify" // Only send a Telegram alert
+        RiskActionIgnore = "ignore" // Do nothing
+)
+
+// Entry modes for new listings
+const (
+        EntryModeImmediate    = "immediate"     // Open the position as soon as the listing is detected
+        EntryModeTradingStart = "trading_start" // Wait until trading opens on Upbit
+        EntryModeOffset       = "offset"        // Trading start plus EntryOffsetSeconds (negative = before)
+)
+
+// Stop loss modes: the percentage is a move of the price, or a loss of the position margin
+const (
+        StopLossModePrice = "price" // Stop at entry price minus StopLossPercentage%
+        StopLossModeROE   = "roe"   // Stop when StopLossPercentage% of the margin is lost
+)
+
+// Exit modes for the profit side of a position
+const (
+        ExitModeFixed    = "fixed"    // Close at TakeProfitPercentage
+        ExitModeTrailing = "trailing" // Trail the high once the price is TrailingActivationPercentage up
+)
+
+// Exchanges whose listing announcements are traded
+const (
+        ExchangeUpbit   = "upbit"
+        ExchangeBithumb = "bithumb"
+)
+
 type User struct {
         ID                    uint      `json:"id" gorm:"primaryKey"`
         TelegramID           int64     `json:"telegram_id" gorm:"uniqueIndex;not null"`
@@ -14,7 +49,25 @@ type User struct {
         TradeAmount          float64   `json:"trade_amount" gorm:"default:100"`        // USDT amount
         Leverage             int       `json:"leverage" gorm:"default:10"`             // 5x, 10x, 20x, 50x
         TakeProfitPercentage float64   `json:"take_profit_percentage" gorm:"default:200"` // 100%, 200%, 300%, 500%
+        StopLossPercentage   float64   `json:"stop_loss_percentage" gorm:"default:0"`     // 0 = no stop loss
+        StopLossMode         string    `json:"stop_loss_mode" gorm:"size:10;default:'price'"` // price or roe
+        ExitMode             string    `json:"exit_mode" gorm:"size:10;default:'fixed'"`      // fixed or trailing
+        TrailingActivationPercentage float64 `json:"trailing_activation_percentage" gorm:"default:100"` // Price rise from entry that arms the trail
+        TrailingCallbackPercentage   float64 `json:"trailing_callback_percentage" gorm:"default:10"`    // Drop from the high that closes the position
+        TakeProfitLadder     string    `json:"take_profit_ladder" gorm:"size:100"`       // Partial take profits, e.g. "30@50,30@100"; empty = none
         IsActive             bool      `json:"is_active" gorm:"default:false"`
+        DelistingAction      string    `json:"delisting_action" gorm:"size:10;default:'close'"` // close, notify, ignore
+        WarningAction        string    `json:"warning_action" gorm:"size:10;default:'notify'"`  // close, notify, ignore
+        TradeKRWMarket       bool      `json:"trade_krw_market" gorm:"default:true"`     // Trade listings opening a KRW market
+        TradeBTCMarket       bool      `json:"trade_btc_market" gorm:"default:true"`     // Trade listings opening a BTC market
+        TradeUSDTMarket      bool      `json:"trade_usdt_market" gorm:"default:true"`    // Trade listings opening a USDT market
+        TradeNewListings     bool      `json:"trade_new_listings" gorm:"default:true"`   // Coins not yet on Upbit
+        TradeAddedMarkets    bool      `json:"trade_added_markets" gorm:"default:true"`  // New market for an already listed coin
+        EntryMode            string    `json:"entry_mode" gorm:"size:20;default:'immediate'"` // immediate, trading_start, offset
+        EntryOffsetSeconds   int       `json:"entry_offset_seconds" gorm:"default:0"`         // Used by the offset entry mode
+        TradeUpbit           bool      `json:"trade_upbit" gorm:"default:true"`           // Trade Upbit listing announcements
+        TradeBithumb         bool      `json:"trade_bithumb" gorm:"default:false"`        // Trade Bithumb listing announcements
+        BithumbTradeAmount   float64   `json:"bithumb_trade_amount" gorm:"default:0"`     // USDT amount for Bithumb listings (0 = TradeAmount)
         CreatedAt            time.Time `json:"created_at"`
         UpdatedAt            time.Time `json:"updated_at"`
         
@@ -76,3 +129,179 @@ func (u *User) GetAPICredentials(encryptionKey string) (apiKey, apiSecret, passp
 func (u *User) UpdateAPICredentials(apiKey, apiSecret, passphrase, encryptionKey string) error {
         return u.SetAPICredentials(apiKey, apiSecret, passphrase, encryptionKey)
 }
+
+// RiskAction returns the configured action for a delisting or warning event
+func (u *User) RiskAction(delisting bool) string {
+        action := u.WarningAction
+        if delisting {
+                action = u.DelistingAction
+        }
+        switch action {
+        case RiskActionClose, RiskActionNotify, RiskActionIgnore:
+                return action
+        }
+        if delisting {
+                return RiskActionClose
+        }
+        return RiskActionNotify
+}
+
+// TradesListing reports whether a listing with the given markets and type matches the user's filters
+// Listings without market info pass the market filter since they can't be classified
+func (u *User) TradesListing(markets []string, addedMarket bool) bool {
+        if addedMarket && !u.TradeAddedMarkets {
+                return false
+        }
+        if !addedMarket && !u.TradeNewListings {
+                return false
+        }
+        if len(markets) == 0 {
+                return true
+        }
+        
+        for _, market := range markets {
+                switch market {
+                case "KRW":
+                        if u.TradeKRWMarket {
+                                return true
+                        }
+                case "BTC":
+                        if u.TradeBTCMarket {
+                                return true
+                        }
+                case "USDT":
+                        if u.TradeUSDTMarket {
+                                return true
+                        }
+                }
+        }
+        return false
+}
+
+// EntryTime returns when a position for a listing should be opened, or zero to enter immediately
+// Listings without a known trading start time are always entered immediately
+func (u *User) EntryTime(tradingStartsAt time.Time) time.Time {
+        if tradingStartsAt.IsZero() {
+                return time.Time{}
+        }
+        switch u.EntryMode {
+        case EntryModeTradingStart:
+                return tradingStartsAt
+        case EntryModeOffset:
+                return tradingStartsAt.Add(time.Duration(u.EntryOffsetSeconds) * time.Second)
+        }
+        return time.Time{}
+}
+
+// TradesExchange reports whether the user trades listings announced on an exchange ("" is Upbit)
+func (u *User) TradesExchange(exchange string) bool {
+        if exchange == ExchangeBithumb {
+                return u.TradeBithumb
+        }
+        return u.TradeUpbit
+}
+
+// TradeAmountFor returns the USDT amount to trade for a listing announced on an exchange
+func (u *User) TradeAmountFor(exchange string) float64 {
+        if exchange == ExchangeBithumb && u.BithumbTradeAmount > 0 {
+                return u.BithumbTradeAmount
+        }
+        return u.TradeAmount
+}
+
+// StopLossPrice returns the stop price of a long position, or 0 when the user has no stop loss
+// In ROE mode the price move is the margin loss divided by the leverage
+func (u *User) StopLossPrice(entryPrice float64, leverage int) float64 {
+        if u.StopLossPercentage <= 0 || entryPrice <= 0 {
+                return 0
+        }
+        move := u.StopLossPercentage / 100
+        if u.StopLossMode == StopLossModeROE && leverage > 1 {
+                move /= float64(leverage)
+        }
+        if move >= 1 {
+                return 0
+        }
+        return entryPrice * (1 - move)
+}
+
+// UsesTrailingStop reports whether the user exits with a trailing stop instead of a fixed take profit
+func (u *User) UsesTrailingStop() bool {
+        return u.ExitMode == ExitModeTrailing && u.TrailingCallbackPercentage > 0
+}
+
+// TakeProfitPrice returns the fixed take profit price of a long position, or 0 in trailing mode
+func (u *User) TakeProfitPrice(entryPrice float64) float64 {
+        if u.UsesTrailingStop() {
+                return 0
+        }
+        return entryPrice * (1 + u.TakeProfitPercentage/100)
+}
+
+// TrailingActivationPrice returns the price that arms the trailing stop, or 0 when the user has no trailing stop
+func (u *User) TrailingActivationPrice(entryPrice float64) float64 {
+        if !u.UsesTrailingStop() {
+                return 0
+        }
+        return entryPrice * (1 + u.TrailingActivationPercentage/100)
+}
+
+// TakeProfitStep is one rung of a take profit ladder: close SizePercent% of the opened size once the price is GainPercent% up
+type TakeProfitStep struct {
+        SizePercent float64
+        GainPercent float64
+}
+
+// ParseTakeProfitLadder parses a ladder written as "size@gain" steps, e.g. "30@50, 30@100"
+// Gains must rise from step to step and the sizes may add up to at most 100%; the rest exits with the fixed
+// take profit or the trailing stop
+func ParseTakeProfitLadder(value string) ([]TakeProfitStep, error) {
+        value = strings.TrimSpace(value)
+        if value == "" {
+                return nil, nil
+        }
+        
+        var steps []TakeProfitStep
+        total := 0.0
+        for _, part := range strings.Split(value, ",") {
+                sizeText, gainText, ok := strings.Cut(strings.TrimSpace(part), "@")
+                if !ok {
+                        return nil, fmt.Errorf("step %q is not size@gain", strings.TrimSpace(part))
+                }
+                size, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(sizeText, "%")), 64)
+                if err != nil || size <= 0 {
+                        return nil, fmt.Errorf("invalid size in step %q", strings.TrimSpace(part))
+                }
+                gain, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimPrefix(strings.TrimSuffix(gainText, "%"), "+")), 64)
+                if err != nil || gain <= 0 {
+                        return nil, fmt.Errorf("invalid gain in step %q", strings.TrimSpace(part))
+                }
+                if len(steps) > 0 && gain <= steps[len(steps)-1].GainPercent {
+                        return nil, fmt.Errorf("gains must rise from step to step")
+                }
+                total += size
+                steps = append(steps, TakeProfitStep{SizePercent: size, GainPercent: gain})
+        }
+        if total > 100 {
+                return nil, fmt.Errorf("step sizes add up to %g%%, more than 100%%", total)
+        }
+        return steps, nil
+}
+
+// FormatTakeProfitLadder renders steps in the form ParseTakeProfitLadder reads
+func FormatTakeProfitLadder(steps []TakeProfitStep) string {
+        parts := make([]string, len(steps))
+        for i, step := range steps {
+                parts[i] = fmt.Sprintf("%g@%g", step.SizePercent, step.GainPercent)
+        }
+        return strings.Join(parts, ",")
+}
+
+// TakeProfitSteps returns the user's take profit ladder; a ladder that no longer parses is treated as none
+func (u *User) TakeProfitSteps() []TakeProfitStep {
+        steps, err := ParseTakeProfitLadder(u.TakeProfitLadder)
+        if err != nil {
+                return nil
+        }
+        return steps
+}
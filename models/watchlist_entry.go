@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+)
+
+// Watchlist entry states
+const (
+	WatchlistWaiting = "waiting" // Polling Bitget's contract list for the coin
+	WatchlistFilled  = "filled"  // Contract appeared and the position was opened
+	WatchlistFailed  = "failed"  // Contract appeared but the entry failed
+	WatchlistSkipped = "skipped" // Contract appeared but the price had already moved too far
+	WatchlistExpired = "expired" // Deadline passed without a contract
+)
+
+// WatchlistEntry is a user's pending entry for a listed coin that has no Bitget futures contract yet
+type WatchlistEntry struct {
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	UserID            uint       `json:"user_id" gorm:"not null;uniqueIndex:idx_watchlist_user_coin_announcement"`
+	CoinSymbol        string     `json:"coin_symbol" gorm:"size:20;not null;index;uniqueIndex:idx_watchlist_user_coin_announcement"`
+	AnnouncementID    string     `json:"announcement_id" gorm:"size:100;uniqueIndex:idx_watchlist_user_coin_announcement"`
+	BitgetSymbol      string     `json:"bitget_symbol" gorm:"size:30"` // Symbol tried when queued; resolved again when polling
+	Status            string     `json:"status" gorm:"size:20;not null;default:'waiting';index"`
+	AnnouncementTitle string     `json:"announcement_title" gorm:"type:text"`
+	AnnouncementURL   string     `json:"announcement_url" gorm:"size:255"`
+	Source            string     `json:"source" gorm:"size:50"`
+	Exchange          string     `json:"exchange" gorm:"size:20;default:'upbit'"`
+	MarketType        string     `json:"market_type" gorm:"size:10"`
+	Markets           string     `json:"markets" gorm:"size:50"` // Comma separated
+	AddedMarket       bool       `json:"added_market"`
+	AnnouncedAt       *time.Time `json:"announced_at,omitempty"`
+	TradingStartsAt   *time.Time `json:"trading_starts_at,omitempty"` // Upbit trading start from the notice body, for the user's entry timing
+	DetectedAt        time.Time  `json:"detected_at"`
+	ExpiresAt         time.Time  `json:"expires_at"`
+	ResolvedAt        *time.Time `json:"resolved_at,omitempty"`
+	Note              string     `json:"note" gorm:"size:255"` // Why the entry ended the way it did
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+
+	// Relations
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
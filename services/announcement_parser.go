@@ -0,0 +1,267 @@
+package services
+
+import (
+        "fmt"
+        "regexp"
+        "strings"
+        "sync/atomic"
+)
+
+// announcementParser extracts coin symbols from exchange announcement titles
+type announcementParser struct {
+        ruleSet *atomic.Pointer[compiledRules] // Rules to apply (nil = Upbit rules)
+}
+
+// rules returns the parser's active rule set
+func (p *announcementParser) rules() *compiledRules {
+        if p.ruleSet != nil {
+                return p.ruleSet.Load()
+        }
+        return currentAnnouncementRules()
+}
+
+// ListingKind classifies what an announcement means for a coin
+type ListingKind string
+
+const (
+        ListingKindNew       ListingKind = "listing"   // New market support
+        ListingKindDelisting ListingKind = "delisting" // 거래지원 종료 (trading support ends)
+        ListingKindWarning   ListingKind = "warning"   // 투자유의 종목 지정 (investment warning)
+)
+
+// classifyAnnouncement returns the kind of a notice title, or "" if it is not relevant
+// Delisting and warning phrases are checked first because they also contain listing words (거래 지원)
+func (p *announcementParser) classifyAnnouncement(title string) ListingKind {
+        kind, _ := p.classifyAnnouncementReason(title)
+        return kind
+}
+
+// classifyAnnouncementReason is classifyAnnouncement that also returns the phrase that decided it
+// Phrases come from the active rules file (see announcement_rules.go)
+func (p *announcementParser) classifyAnnouncementReason(title string) (ListingKind, string) {
+        rules := p.rules().rules
+        compactTitle := compactPhrase(title)
+        
+        // Warnings being lifted or extended are not new risk events
+        for _, phrase := range rules.Ignore {
+                if strings.Contains(compactTitle, compactPhrase(phrase)) {
+                        return "", fmt.Sprintf("ignored phrase %q", phrase)
+                }
+        }
+        
+        var excluded []string
+        for _, candidate := range []struct {
+                kind ListingKind
+                rule keywordRule
+        }{
+                {ListingKindDelisting, rules.Delisting},
+                {ListingKindWarning, rules.Warning},
+                {ListingKindNew, rules.Listing},
+        } {
+                include, exclude := candidate.rule.match(compactTitle)
+                if include == "" {
+                        continue
+                }
+                if exclude != "" {
+                        excluded = append(excluded, fmt.Sprintf("%s phrase %q excluded by %q", candidate.kind, include, exclude))
+                        continue
+                }
+                return candidate.kind, fmt.Sprintf("%s phrase %q", candidate.kind, include)
+        }
+        
+        if len(excluded) > 0 {
+                return "", strings.Join(excluded, "; ")
+        }
+        return "", "no listing, delisting or warning phrase"
+}
+
+// isMarketSupportAnnouncement checks if title indicates market support announcement
+func (p *announcementParser) isMarketSupportAnnouncement(title string) bool {
+        include, exclude := p.rules().rules.Listing.match(compactPhrase(title))
+        return include != "" && exclude == ""
+}
+
+// symbolDecision records why a candidate token was accepted or rejected as a coin symbol
+type symbolDecision struct {
+        Token    string
+        Pattern  string
+        Accepted bool
+        Reason   string
+}
+
+// extractCoinSymbols extracts coin symbols from announcement title
+func (p *announcementParser) extractCoinSymbols(title string) []string {
+        coins, _ := p.extractCoinSymbolsExplained(title)
+        return coins
+}
+
+// extractCoinSymbolsExplained extracts coin symbols and records a decision for every candidate token
+// Symbol patterns run in rule order; Korean name aliases are only consulted when no pattern found a symbol
+func (p *announcementParser) extractCoinSymbolsExplained(title string) ([]string, []symbolDecision) {
+        rules := p.rules()
+        var coins []string
+        var decisions []symbolDecision
+        
+        check := func(token string, pattern string) {
+                symbol := strings.ToUpper(strings.TrimSpace(token))
+                decision := symbolDecision{Token: symbol, Pattern: pattern}
+                switch {
+                case !p.isValidCoinSymbol(symbol):
+                        decision.Reason = "not a valid symbol (2-10 chars, mostly letters)"
+                case p.isCommonWord(symbol):
+                        decision.Reason = "stop-list word"
+                default:
+                        decision.Accepted = true
+                        decision.Reason = "accepted"
+                        coins = append(coins, symbol)
+                }
+                decisions = append(decisions, decision)
+        }
+        
+        for _, pattern := range rules.symbolPatterns {
+                for _, match := range pattern.re.FindAllStringSubmatch(title, -1) {
+                        check(match[1], pattern.name)
+                }
+                
+                // e.g. titles using the Name(SYMBOL) form only name coins that way; other caps words are markets or labels
+                if pattern.exclusive && len(coins) > 0 {
+                        return removeDuplicates(coins), decisions
+                }
+        }
+        
+        if len(coins) == 0 {
+                compactTitle := compactPhrase(title)
+                for _, name := range rules.aliasNames {
+                        compactName := compactPhrase(name)
+                        if strings.Contains(compactTitle, compactName) {
+                                check(rules.rules.Aliases[name], "alias "+name)
+                                compactTitle = strings.ReplaceAll(compactTitle, compactName, " ")
+                        }
+                }
+        }
+        
+        return removeDuplicates(coins), decisions
+}
+
+// btcMarketPattern matches BTC listed among the markets of a title, e.g. "(KRW, BTC)"
+var btcMarketPattern = regexp.MustCompile(`[(,]\s*BTC\s*[,)]|BTC,`)
+
+// extractMarkets detects which quote markets (KRW, BTC, USDT) an announcement opens
+func (p *announcementParser) extractMarkets(title string) []string {
+        upperTitle := strings.ToUpper(title)
+        var markets []string
+        
+        if strings.Contains(upperTitle, "KRW") || strings.Contains(title, "원화") {
+                markets = append(markets, MarketKRW)
+        }
+        if strings.Contains(upperTitle, "BTC MARKET") || strings.Contains(upperTitle, "BTC 마켓") ||
+                strings.Contains(title, "비트코인 마켓") || btcMarketPattern.MatchString(upperTitle) {
+                markets = append(markets, MarketBTC)
+        }
+        if strings.Contains(upperTitle, "USDT") || strings.Contains(title, "테더") {
+                markets = append(markets, MarketUSDT)
+        }
+        
+        return markets
+}
+
+// isValidCoinSymbol checks if a symbol looks like a valid cryptocurrency symbol
+func (p *announcementParser) isValidCoinSymbol(symbol string) bool {
+        // Basic validation: 2-10 characters, all uppercase letters/numbers
+        if len(symbol) < 2 || len(symbol) > 10 {
+                return false
+        }
+        
+        // Must be mostly letters
+        letterCount := 0
+        for _, char := range symbol {
+                if (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9') {
+                        if char >= 'A' && char <= 'Z' {
+                                letterCount++
+                        }
+                } else {
+                        return false
+                }
+        }
+        
+        // At least 50% letters
+        return letterCount >= len(symbol)/2
+}
+
+// isCommonWord filters out words on the rules stop-list that aren't crypto symbols
+func (p *announcementParser) isCommonWord(word string) bool {
+        return p.rules().stopList[word]
+}
+
+// removeDuplicates removes duplicate symbols from slice
+func removeDuplicates(symbols []string) []string {
+        seen := make(map[string]bool)
+        var result []string
+        
+        for _, symbol := range symbols {
+                if !seen[symbol] {
+                        seen[symbol] = true
+                        result = append(result, symbol)
+                }
+        }
+        
+        return result
+}
+
+// AnnouncementExplanation is the full parse of one title, used by the parse CLI to debug matches
+type AnnouncementExplanation struct {
+        Title      string
+        Kind       ListingKind
+        KindReason string
+        Markets    []string
+        Symbols    []string
+        Decisions  []symbolDecision
+}
+
+// ExplainAnnouncement parses a title the same way the listing sources do and records every decision
+func ExplainAnnouncement(title string) AnnouncementExplanation {
+        parser := &announcementParser{}
+        kind, reason := parser.classifyAnnouncementReason(title)
+        symbols, decisions := parser.extractCoinSymbolsExplained(title)
+        
+        return AnnouncementExplanation{
+                Title:      title,
+                Kind:       kind,
+                KindReason: reason,
+                Markets:    parser.extractMarkets(title),
+                Symbols:    symbols,
+                Decisions:  decisions,
+        }
+}
+
+// String renders the explanation for terminal output
+func (e AnnouncementExplanation) String() string {
+        var b strings.Builder
+        
+        fmt.Fprintf(&b, "📝 Title: %s\n", e.Title)
+        if e.Kind == "" {
+                fmt.Fprintf(&b, "🚫 Kind: rejected (%s)\n", e.KindReason)
+        } else {
+                fmt.Fprintf(&b, "🏷️ Kind: %s (%s)\n", e.Kind, e.KindReason)
+        }
+        fmt.Fprintf(&b, "🏦 Markets: %s\n", strings.Join(e.Markets, ", "))
+        
+        b.WriteString("🔎 Tokens:\n")
+        if len(e.Decisions) == 0 {
+                b.WriteString("   (no candidate tokens)\n")
+        }
+        for _, decision := range e.Decisions {
+                mark := "❌"
+                if decision.Accepted {
+                        mark = "✅"
+                }
+                fmt.Fprintf(&b, "   %s %-10s %-14s %s\n", mark, decision.Token, decision.Pattern, decision.Reason)
+        }
+        
+        if e.Kind == "" || len(e.Symbols) == 0 {
+                b.WriteString("🎯 Result: no event emitted\n")
+        } else {
+                fmt.Fprintf(&b, "🎯 Result: %s event for %s\n", e.Kind, strings.Join(e.Symbols, ", "))
+        }
+        return b.String()
+}
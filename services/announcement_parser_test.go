@@ -0,0 +1,166 @@
+package services
+
+import (
+        "encoding/json"
+        "flag"
+        "net/http"
+        "os"
+        "path/filepath"
+        "reflect"
+        "strings"
+        "testing"
+        "time"
+
+        "github.com/PuerkitoBio/goquery"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite announcement page golden files")
+
+// titleFixture is one entry of testdata/announcements/titles.json
+type titleFixture struct {
+        Title   string   `json:"title"`
+        Kind    string   `json:"kind"`
+        Symbols []string `json:"symbols"`
+        Markets []string `json:"markets"`
+        Note    string   `json:"note,omitempty"`
+}
+
+// goldenListing is the part of a CoinListing that page golden files pin down
+type goldenListing struct {
+        Symbol         string   `json:"symbol"`
+        Kind           string   `json:"kind"`
+        AnnouncementID string   `json:"announcement_id"`
+        Markets        []string `json:"markets"`
+}
+
+func TestAnnouncementTitleFixtures(t *testing.T) {
+        body, err := os.ReadFile("testdata/announcements/titles.json")
+        if err != nil {
+                t.Fatalf("failed to read title fixtures: %v", err)
+        }
+        
+        var fixtures []titleFixture
+        if err := json.Unmarshal(body, &fixtures); err != nil {
+                t.Fatalf("failed to parse title fixtures: %v", err)
+        }
+        
+        for _, fixture := range fixtures {
+                fixture := fixture
+                t.Run(fixture.Title, func(t *testing.T) {
+                        explanation := ExplainAnnouncement(fixture.Title)
+                        
+                        if string(explanation.Kind) != fixture.Kind {
+                                t.Errorf("kind: got %q (%s), want %q", explanation.Kind, explanation.KindReason, fixture.Kind)
+                        }
+                        if !sameStrings(explanation.Symbols, fixture.Symbols) {
+                                t.Errorf("symbols: got %v, want %v\n%s", explanation.Symbols, fixture.Symbols, explanation)
+                        }
+                        if !sameStrings(explanation.Markets, fixture.Markets) {
+                                t.Errorf("markets: got %v, want %v", explanation.Markets, fixture.Markets)
+                        }
+                })
+        }
+}
+
+func TestAnnouncementPageFixtures(t *testing.T) {
+        pages, err := filepath.Glob("testdata/announcements/pages/*")
+        if err != nil {
+                t.Fatalf("failed to list page fixtures: %v", err)
+        }
+        
+        for _, page := range pages {
+                if strings.HasSuffix(page, ".golden.json") {
+                        continue
+                }
+                
+                page := page
+                t.Run(filepath.Base(page), func(t *testing.T) {
+                        got := goldenListings(t, parsePageFixture(t, page))
+                        goldenPath := strings.TrimSuffix(page, filepath.Ext(page)) + ".golden.json"
+                        
+                        if *updateGolden {
+                                body, err := json.MarshalIndent(got, "", "  ")
+                                if err != nil {
+                                        t.Fatalf("failed to encode golden file: %v", err)
+                                }
+                                if err := os.WriteFile(goldenPath, append(body, '\n'), 0644); err != nil {
+                                        t.Fatalf("failed to write golden file: %v", err)
+                                }
+                                return
+                        }
+                        
+                        body, err := os.ReadFile(goldenPath)
+                        if err != nil {
+                                t.Fatalf("missing golden file %s (run go test -update): %v", goldenPath, err)
+                        }
+                        var want []goldenListing
+                        if err := json.Unmarshal(body, &want); err != nil {
+                                t.Fatalf("failed to parse golden file: %v", err)
+                        }
+                        
+                        if !reflect.DeepEqual(got, want) {
+                                t.Errorf("page %s parsed differently from golden file\ngot:  %+v\nwant: %+v", page, got, want)
+                        }
+                })
+        }
+}
+
+// parsePageFixture runs a recorded page through the source that consumes that format
+func parsePageFixture(t *testing.T, path string) []CoinListing {
+        t.Helper()
+        
+        file, err := os.Open(path)
+        if err != nil {
+                t.Fatalf("failed to open page fixture: %v", err)
+        }
+        defer file.Close()
+        
+        client := &http.Client{Timeout: 5 * time.Second}
+        switch filepath.Ext(path) {
+        case ".html":
+                doc, err := goquery.NewDocumentFromReader(file)
+                if err != nil {
+                        t.Fatalf("failed to parse HTML fixture: %v", err)
+                }
+                return NewUpbitNoticeScraper(time.Minute, client).parseAnnouncements(doc)
+        case ".json":
+                body, err := os.ReadFile(path)
+                if err != nil {
+                        t.Fatalf("failed to read JSON fixture: %v", err)
+                }
+                notices, err := parseUpbitNoticeList(body)
+                if err != nil {
+                        t.Fatalf("failed to parse JSON fixture: %v", err)
+                }
+                return NewUpbitNoticeAPISource(time.Minute, client).listingsFromNotices(notices)
+        }
+        
+        t.Fatalf("unsupported page fixture format: %s", path)
+        return nil
+}
+
+func goldenListings(t *testing.T, listings []CoinListing) []goldenListing {
+        t.Helper()
+        
+        result := []goldenListing{}
+        for _, listing := range listings {
+                markets := listing.Markets
+                if markets == nil {
+                        markets = []string{}
+                }
+                result = append(result, goldenListing{
+                        Symbol:         listing.Symbol,
+                        Kind:           string(listing.Kind),
+                        AnnouncementID: listing.AnnouncementID,
+                        Markets:        markets,
+                })
+        }
+        return result
+}
+
+func sameStrings(got, want []string) bool {
+        if len(got) == 0 && len(want) == 0 {
+                return true
+        }
+        return reflect.DeepEqual(got, want)
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+        _ "embed"
+        "encoding/json"
+        "fmt"
+        "log"
+        "os"
+        "regexp"
+        "sort"
+        "strings"
+        "sync/atomic"
+        "time"
+)
+
+// defaultAnnouncementRules are the built-in extraction rules, used until a rules file is loaded
+//
+//go:embed rules/announcement_rules.json
+var defaultAnnouncementRules []byte
+
+// announcementRules is the on-disk format of the extraction rules file
+type announcementRules struct {
+        Ignore         []string             `json:"ignore"`          // Titles containing these are never events (warnings being lifted, ...)
+        Delisting      keywordRule          `json:"delisting"`       // Checked first: delisting titles also contain listing words
+        Warning        keywordRule          `json:"warning"`         // Investment warning designations
+        Listing        keywordRule          `json:"listing"`         // New market support
+        SymbolPatterns []symbolPatternRule  `json:"symbol_patterns"` // Tried in order, each with one capture group
+        StopList       []string             `json:"stop_list"`       // Upper-case words that are never symbols
+        Aliases        map[string]string    `json:"aliases"`         // Korean coin name -> ticker, used when no pattern finds a symbol
+}
+
+// keywordRule matches a title when any include phrase is present and no exclude phrase is
+// Phrases are compared case-insensitively with spaces removed
+type keywordRule struct {
+        Include []string `json:"include"`
+        Exclude []string `json:"exclude,omitempty"`
+}
+
+// symbolPatternRule is one symbol extraction regex
+type symbolPatternRule struct {
+        Name      string `json:"name"`
+        Pattern   string `json:"pattern"`
+        Exclusive bool   `json:"exclusive,omitempty"` // When it finds symbols, later patterns are skipped
+}
+
+// compiledRules is a validated rule set ready for the parser
+type compiledRules struct {
+        source         string
+        loadedAt       time.Time
+        rules          announcementRules
+        symbolPatterns []compiledSymbolPattern
+        stopList       map[string]bool
+        aliasNames     []string // Alias keys, longest first so "파티클네트워크" wins over shorter names
+}
+
+type compiledSymbolPattern struct {
+        name      string
+        re        *regexp.Regexp
+        exclusive bool
+}
+
+// defaultBithumbAnnouncementRules are the built-in rules for Bithumb notice titles
+//
+//go:embed rules/bithumb_announcement_rules.json
+var defaultBithumbAnnouncementRules []byte
+
+// Active rule sets; each exchange writes its titles differently, so each has its own file
+var (
+        activeRules        atomic.Pointer[compiledRules] // Upbit
+        activeBithumbRules atomic.Pointer[compiledRules]
+)
+
+func init() {
+        for _, set := range []struct {
+                target *atomic.Pointer[compiledRules]
+                data   []byte
+                name   string
+        }{
+                {&activeRules, defaultAnnouncementRules, "announcement"},
+                {&activeBithumbRules, defaultBithumbAnnouncementRules, "Bithumb announcement"},
+        } {
+                rules, err := parseAnnouncementRules(set.data, "embedded defaults")
+                if err != nil {
+                        panic(fmt.Sprintf("invalid embedded %s rules: %v", set.name, err))
+                }
+                set.target.Store(rules)
+        }
+}
+
+// currentAnnouncementRules returns the active rule set
+func currentAnnouncementRules() *compiledRules {
+        return activeRules.Load()
+}
+
+// parseAnnouncementRules decodes and validates a rules file
+func parseAnnouncementRules(data []byte, source string) (*compiledRules, error) {
+        var rules announcementRules
+        decoder := json.NewDecoder(strings.NewReader(string(data)))
+        decoder.DisallowUnknownFields()
+        if err := decoder.Decode(&rules); err != nil {
+                return nil, fmt.Errorf("failed to parse rules: %w", err)
+        }
+        
+        for _, section := range []struct {
+                name string
+                rule keywordRule
+        }{
+                {"delisting", rules.Delisting},
+                {"warning", rules.Warning},
+                {"listing", rules.Listing},
+        } {
+                if len(section.rule.Include) == 0 {
+                        return nil, fmt.Errorf("%s.include must not be empty", section.name)
+                }
+                for _, phrase := range append(append([]string{}, section.rule.Include...), section.rule.Exclude...) {
+                        if compactPhrase(phrase) == "" {
+                                return nil, fmt.Errorf("%s contains an empty phrase", section.name)
+                        }
+                }
+        }
+        
+        if len(rules.SymbolPatterns) == 0 {
+                return nil, fmt.Errorf("symbol_patterns must not be empty")
+        }
+        
+        compiled := &compiledRules{
+                source:   source,
+                loadedAt: time.Now(),
+                rules:    rules,
+                stopList: make(map[string]bool),
+        }
+        
+        for i, pattern := range rules.SymbolPatterns {
+                re, err := regexp.Compile(pattern.Pattern)
+                if err != nil {
+                        return nil, fmt.Errorf("symbol_patterns[%d] %q: %w", i, pattern.Name, err)
+                }
+                if re.NumSubexp() < 1 {
+                        return nil, fmt.Errorf("symbol_patterns[%d] %q needs a capture group for the symbol", i, pattern.Name)
+                }
+                name := pattern.Name
+                if name == "" {
+                        name = fmt.Sprintf("pattern %d", i+1)
+                }
+                compiled.symbolPatterns = append(compiled.symbolPatterns, compiledSymbolPattern{name: name, re: re, exclusive: pattern.Exclusive})
+        }
+        
+        for _, word := range rules.StopList {
+                compiled.stopList[strings.ToUpper(strings.TrimSpace(word))] = true
+        }
+        
+        parser := &announcementParser{}
+        for name, ticker := range rules.Aliases {
+                if compactPhrase(name) == "" {
+                        return nil, fmt.Errorf("alias for %q has an empty name", ticker)
+                }
+                if !parser.isValidCoinSymbol(ticker) {
+                        return nil, fmt.Errorf("alias %q maps to invalid ticker %q", name, ticker)
+                }
+                compiled.aliasNames = append(compiled.aliasNames, name)
+        }
+        sort.Slice(compiled.aliasNames, func(i, j int) bool {
+                a, b := compiled.aliasNames[i], compiled.aliasNames[j]
+                if len(a) != len(b) {
+                        return len(a) > len(b)
+                }
+                return a < b
+        })
+        
+        return compiled, nil
+}
+
+// LoadAnnouncementRules loads and activates an Upbit rules file; on error the previous rules stay active
+func LoadAnnouncementRules(path string) error {
+        return loadRulesFile(&activeRules, "Announcement", path)
+}
+
+// LoadBithumbAnnouncementRules loads and activates a Bithumb rules file; on error the previous rules stay active
+func LoadBithumbAnnouncementRules(path string) error {
+        return loadRulesFile(&activeBithumbRules, "Bithumb announcement", path)
+}
+
+// WatchAnnouncementRules reloads the Upbit rules file whenever its modification time changes (blocking)
+func WatchAnnouncementRules(path string, interval time.Duration, stop <-chan struct{}) {
+        watchRulesFile(&activeRules, "Announcement", path, interval, stop)
+}
+
+// WatchBithumbAnnouncementRules reloads the Bithumb rules file whenever its modification time changes (blocking)
+func WatchBithumbAnnouncementRules(path string, interval time.Duration, stop <-chan struct{}) {
+        watchRulesFile(&activeBithumbRules, "Bithumb announcement", path, interval, stop)
+}
+
+func loadRulesFile(target *atomic.Pointer[compiledRules], label string, path string) error {
+        data, err := os.ReadFile(path)
+        if err != nil {
+                return fmt.Errorf("failed to read rules file: %w", err)
+        }
+        
+        rules, err := parseAnnouncementRules(data, path)
+        if err != nil {
+                return err
+        }
+        
+        target.Store(rules)
+        log.Printf("📜 %s rules loaded from %s (%d listing phrases, %d symbol patterns, %d stop words, %d aliases)",
+                label, path, len(rules.rules.Listing.Include), len(rules.symbolPatterns), len(rules.stopList), len(rules.aliasNames))
+        return nil
+}
+
+func watchRulesFile(target *atomic.Pointer[compiledRules], label string, path string, interval time.Duration, stop <-chan struct{}) {
+        var lastModTime time.Time
+        if info, err := os.Stat(path); err == nil {
+                lastModTime = info.ModTime()
+        }
+        
+        ticker := time.NewTicker(interval)
+        defer ticker.Stop()
+        
+        for {
+                select {
+                case <-stop:
+                        return
+                case <-ticker.C:
+                }
+                
+                info, err := os.Stat(path)
+                if err != nil {
+                        log.Printf("⚠️ %s rules file unavailable, keeping current rules: %v", label, err)
+                        continue
+                }
+                if info.ModTime().Equal(lastModTime) {
+                        continue
+                }
+                lastModTime = info.ModTime()
+                
+                if err := loadRulesFile(target, label, path); err != nil {
+                        log.Printf("❌ Invalid %s rules in %s, keeping previous rules from %s: %v",
+                                strings.ToLower(label), path, target.Load().source, err)
+                }
+        }
+}
+
+// match returns the first include and exclude phrases found in the compacted title
+// The rule matches when include is set and exclude is empty
+func (r keywordRule) match(compactTitle string) (include string, exclude string) {
+        for _, phrase := range r.Exclude {
+                if strings.Contains(compactTitle, compactPhrase(phrase)) {
+                        exclude = phrase
+                        break
+                }
+        }
+        for _, phrase := range r.Include {
+                if strings.Contains(compactTitle, compactPhrase(phrase)) {
+                        include = phrase
+                        break
+                }
+        }
+        return include, exclude
+}
+
+// compactPhrase lower-cases and removes spaces so "거래지원 종료" matches "거래 지원 종료"
+func compactPhrase(phrase string) string {
+        return strings.ReplaceAll(strings.ToLower(phrase), " ", "")
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+        "os"
+        "path/filepath"
+        "strings"
+        "testing"
+        "time"
+)
+
+// withDefaultRules restores the embedded rules after a test swaps them
+func withDefaultRules(t *testing.T) {
+        t.Helper()
+        previous := currentAnnouncementRules()
+        t.Cleanup(func() { activeRules.Store(previous) })
+}
+
+// writeRulesFile writes the embedded defaults with edit applied
+func writeRulesFile(t *testing.T, path string, edit func(string) string) {
+        t.Helper()
+        if err := os.WriteFile(path, []byte(edit(string(defaultAnnouncementRules))), 0644); err != nil {
+                t.Fatalf("failed to write rules file: %v", err)
+        }
+}
+
+func TestLoadAnnouncementRulesKeepsPreviousOnError(t *testing.T) {
+        withDefaultRules(t)
+        path := filepath.Join(t.TempDir(), "rules.json")
+        parser := &announcementParser{}
+        
+        writeRulesFile(t, path, func(rules string) string {
+                return strings.Replace(rules, `"END"`, `"END", "TOSHI"`, 1)
+        })
+        if err := LoadAnnouncementRules(path); err != nil {
+                t.Fatalf("valid rules rejected: %v", err)
+        }
+        if symbols := parser.extractCoinSymbols("Market Support for Toshi(TOSHI) (KRW Market)"); len(symbols) != 0 {
+                t.Fatalf("expected TOSHI on the stop-list after reload, got %v", symbols)
+        }
+        
+        invalid := map[string]func(string) string{
+                "broken json":      func(rules string) string { return rules[:len(rules)/2] },
+                "bad regex":        func(rules string) string { return strings.Replace(rules, `[A-Z0-9]+`, `[A-Z0-9+`, 1) },
+                "no capture group": func(rules string) string { return strings.Replace(rules, `\\(([A-Z0-9]+)\\)`, `\\([A-Z0-9]+\\)`, 1) },
+                "bad alias ticker": func(rules string) string { return strings.Replace(rules, `"TOSHI"`, `"to shi"`, 1) },
+                "unknown field":    func(rules string) string { return strings.Replace(rules, `"ignore"`, `"ignored"`, 1) },
+        }
+        for name, edit := range invalid {
+                writeRulesFile(t, path, edit)
+                if err := LoadAnnouncementRules(path); err == nil {
+                        t.Errorf("%s: expected validation error", name)
+                }
+                if currentAnnouncementRules().source != path || !currentAnnouncementRules().stopList["TOSHI"] {
+                        t.Errorf("%s: previous rules were replaced", name)
+                }
+        }
+}
+
+func TestWatchAnnouncementRulesReloadsOnChange(t *testing.T) {
+        withDefaultRules(t)
+        path := filepath.Join(t.TempDir(), "rules.json")
+        writeRulesFile(t, path, func(rules string) string { return rules })
+        
+        stop := make(chan struct{})
+        defer close(stop)
+        go WatchAnnouncementRules(path, 10*time.Millisecond, stop)
+        time.Sleep(50 * time.Millisecond) // Let the watcher record the initial modification time
+        
+        writeRulesFile(t, path, func(rules string) string {
+                return strings.Replace(rules, `"해제",`, `"해제", "신규",`, 1)
+        })
+        future := time.Now().Add(time.Minute)
+        if err := os.Chtimes(path, future, future); err != nil {
+                t.Fatalf("failed to touch rules file: %v", err)
+        }
+        
+        deadline := time.Now().Add(2 * time.Second)
+        for time.Now().Before(deadline) {
+                if currentAnnouncementRules().source == path {
+                        kind := (&announcementParser{}).classifyAnnouncement("[거래] 오픈렛저(OPEN) 신규 거래지원 안내 (KRW 마켓)")
+                        if kind != "" {
+                                t.Errorf("expected reloaded ignore phrase to reject the title, got %q", kind)
+                        }
+                        return
+                }
+                time.Sleep(10 * time.Millisecond)
+        }
+        t.Fatal("rules file change was not picked up")
+}
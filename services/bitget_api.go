@@ -22,6 +22,7 @@ type BitgetAPI struct {
         Passphrase string
         BaseURL    string
         Client     *http.Client
+        Contracts  *ContractCache // Contract metadata orders are rounded and checked against (nil sends them as is)
 }
 
 // OrderSide represents order side
@@ -82,8 +83,23 @@ type OrderRequest struct {
 
 // OrderResponse represents order response
 type OrderResponse struct {
-        OrderID   string `json:"orderId"`
-        ClientOID string `json:"clientOid"`
+        OrderID   string    `json:"orderId"`
+        ClientOID string    `json:"clientOid"`
+        SentAt    time.Time `json:"-"` // When the order request was sent
+        AckAt     time.Time `json:"-"` // When Bitget acknowledged it
+        Size      float64   `json:"-"` // Size sent, after rounding to the contract step
+        Leverage  int       `json:"-"` // Leverage the position was opened with, after clamping to the contract maximum
+}
+
+// OrderDetail is the state of a placed order
+type OrderDetail struct {
+        OrderID    string `json:"orderId"`
+        Symbol     string `json:"symbol"`
+        State      string `json:"state"`      // live, partially_filled, filled, canceled
+        PriceAvg   string `json:"priceAvg"`   // Average fill price
+        BaseVolume string `json:"baseVolume"` // Filled size
+        CreatedAt  string `json:"cTime"`
+        UpdatedAt  string `json:"uTime"`
 }
 
 // APIResponse represents standard Bitget API response
@@ -94,6 +110,9 @@ type APIResponse struct {
         Data      interface{} `json:"data"`
 }
 
+// bitgetRetryBaseDelay is the first backoff after a rate limited request, doubled on every retry
+var bitgetRetryBaseDelay = 2 * time.Second
+
 // NewBitgetAPI creates a new Bitget API client
 func NewBitgetAPI(apiKey, apiSecret, passphrase string) *BitgetAPI {
         return &BitgetAPI{
@@ -104,43 +123,100 @@ func NewBitgetAPI(apiKey, apiSecret, passphrase string) *BitgetAPI {
                 Client: &http.Client{
                         Timeout: 30 * time.Second,
                 },
+                Contracts: BitgetContracts,
         }
 }
 
+// contractSpec returns the cached metadata of a contract, if any
+func (b *BitgetAPI) contractSpec(symbol string) (ContractSpec, bool) {
+        if b.Contracts == nil {
+                return ContractSpec{}, false
+        }
+        spec, ok := b.Contracts.Spec(symbol)
+        if !ok {
+                fmt.Printf("⚠️ No contract metadata for %s, order size is sent unrounded\n", symbol)
+        }
+        return spec, ok
+}
+
+// GetContractSpec returns the cached precision and limits of a contract, if known
+func (b *BitgetAPI) GetContractSpec(symbol string) (ContractSpec, bool) {
+        return b.contractSpec(symbol)
+}
+
 // PlaceOrder places a futures market order using official v2 API
+// The size is rounded down to the contract step and checked against the minimum order size
 func (b *BitgetAPI) PlaceOrder(symbol string, side OrderSide, size float64, tradeSide string) (*OrderResponse, error) {
+        return b.placeMarketOrder(symbol, side, size, tradeSide, false)
+}
+
+// ReducePosition closes part of a long position with a reduce-only market order
+// The order can only shrink the position, so a size above what is left never opens a short
+func (b *BitgetAPI) ReducePosition(symbol string, size float64) (*OrderResponse, error) {
+        return b.placeMarketOrder(symbol, OrderSideSell, size, "close", true)
+}
+
+// placeMarketOrder sends a market order with its size rounded to the contract step
+// Closing orders skip the status and minimum checks: a restricted or delisting contract, or a remainder below the
+// minimum, must not keep a position open
+func (b *BitgetAPI) placeMarketOrder(symbol string, side OrderSide, size float64, tradeSide string, reduceOnly bool) (*OrderResponse, error) {
+        sizeText := fmt.Sprintf("%.8f", size)
+        if spec, ok := b.contractSpec(symbol); ok {
+                size = spec.RoundSize(size)
+                if reduceOnly || tradeSide == "close" {
+                        if size <= 0 {
+                                return nil, fmt.Errorf("invalid order: close size rounds to 0 on %s", symbol)
+                        }
+                } else if err := spec.ValidateOrder(size, 0); err != nil {
+                        return nil, fmt.Errorf("invalid order: %w", err)
+                }
+                sizeText = spec.FormatSize(size)
+        }
+        
         orderReq := OrderRequest{
                 Symbol:      symbol,
                 ProductType: "USDT-FUTURES",   // USDT-M Futures
                 MarginMode:  "isolated",       // Isolated margin
                 MarginCoin:  "USDT",          // Margin coin (capitalized)
-                Size:        fmt.Sprintf("%.8f", size),
+                Size:        sizeText,
                 Side:        side,            // buy or sell
                 TradeSide:   tradeSide,       // open or close
                 OrderType:   OrderTypeMarket, // market order
                 Force:       "gtc",           // Good till canceled
         }
+        if reduceOnly {
+                orderReq.ReduceOnly = "YES"
+        }
         
         endpoint := "/api/v2/mix/order/place-order"
         
         fmt.Printf("🚀 Placing v2 order: %+v\n", orderReq)
         
         var orderResp OrderResponse
+        sentAt := time.Now()
         err := b.makeRequest("POST", endpoint, orderReq, &orderResp)
         if err != nil {
                 fmt.Printf("❌ Order placement failed: %v\n", err)
                 return nil, fmt.Errorf("failed to place order: %w", err)
         }
+        orderResp.SentAt = sentAt
+        orderResp.AckAt = time.Now()
+        orderResp.Size = size
         
         fmt.Printf("✅ Order placed successfully: %+v\n", orderResp)
         return &orderResp, nil
 }
 
 // OpenLongPosition opens a long position like Python version
+// Leverage is capped at the contract maximum, and orders below the contract minimums are refused before anything is sent,
+// leverage included
 func (b *BitgetAPI) OpenLongPosition(symbol string, marginUSDT float64, leverage int) (*OrderResponse, error) {
-        // First set leverage
-        if err := b.SetLeverage(symbol, leverage); err != nil {
-                return nil, fmt.Errorf("failed to set leverage: %w", err)
+        spec, hasSpec := b.contractSpec(symbol)
+        if hasSpec {
+                if clamped := spec.ClampLeverage(leverage); clamped != leverage {
+                        fmt.Printf("⚠️ %s allows at most %dx leverage, using %dx instead of %dx\n", symbol, spec.MaxLever, clamped, leverage)
+                        leverage = clamped
+                }
         }
         
         // Get current price to calculate proper size
@@ -154,11 +230,27 @@ func (b *BitgetAPI) OpenLongPosition(symbol string, marginUSDT float64, leverage
         // Size in base currency = (marginUSDT * leverage) / currentPrice
         totalPositionValue := marginUSDT * float64(leverage)
         baseSize := totalPositionValue / currentPrice
+        if hasSpec {
+                baseSize = spec.RoundSize(baseSize)
+                if err := spec.ValidateOrder(baseSize, currentPrice); err != nil {
+                        return nil, fmt.Errorf("invalid order: %w", err)
+                }
+        }
+        
+        // Leverage is only changed for an order that passed validation, so a refused order leaves the account as it was
+        if err := b.SetLeverage(symbol, leverage); err != nil {
+                return nil, fmt.Errorf("failed to set leverage: %w", err)
+        }
         
         fmt.Printf("📊 Opening long position: symbol=%s, margin=%.2f USDT, leverage=%dx, price=%.6f, total_value=%.2f, size=%.8f\n", 
                 symbol, marginUSDT, leverage, currentPrice, totalPositionValue, baseSize)
         
-        return b.PlaceOrder(symbol, OrderSideBuy, baseSize, "open")
+        orderResp, err := b.PlaceOrder(symbol, OrderSideBuy, baseSize, "open")
+        if err != nil {
+                return nil, err
+        }
+        orderResp.Leverage = leverage
+        return orderResp, nil
 }
 
 // FlashClosePosition closes position using flash close API (market price instantly)
@@ -308,6 +400,24 @@ func (b *BitgetAPI) GetPosition(symbol string) (*BitgetPosition, error) {
         return &positions[0], nil
 }
 
+// GetOrderDetail gets the state and average fill price of an order
+func (b *BitgetAPI) GetOrderDetail(symbol string, orderID string) (*OrderDetail, error) {
+        endpoint := "/api/v2/mix/order/detail"
+        params := map[string]string{
+                "symbol":      symbol,
+                "productType": "USDT-FUTURES",
+                "orderId":     orderID,
+        }
+        
+        var detail OrderDetail
+        err := b.makeRequestWithParams("GET", endpoint, params, nil, &detail)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get order detail: %w", err)
+        }
+        
+        return &detail, nil
+}
+
 // GetAllPositions gets all open positions
 func (b *BitgetAPI) GetAllPositions() ([]BitgetPosition, error) {
         endpoint := "/api/v2/mix/position/all-position"
@@ -348,6 +458,244 @@ func (b *BitgetAPI) SetLeverage(symbol string, leverage int) error {
         return nil
 }
 
+// Plan types of position take profit / stop loss orders; they cover the whole position and
+// are removed by Bitget when the position is closed
+const (
+        PlanTypePositionProfit = "pos_profit"
+        PlanTypePositionLoss   = "pos_loss"
+        PlanTypeMovingPlan     = "moving_plan" // Trailing stop, sized, armed at its trigger price
+        PlanTypeProfitPlan     = "profit_plan" // Partial take profit, sized, one per ladder step
+)
+
+// bitgetMaxCallbackRate is the largest trailing stop callback Bitget accepts (%); wider trails are followed locally
+const bitgetMaxCallbackRate = 10.0
+
+// TPSLOrderRequest is a take profit or stop loss plan order on an open position
+type TPSLOrderRequest struct {
+        MarginCoin   string `json:"marginCoin"`
+        ProductType  string `json:"productType"`
+        Symbol       string `json:"symbol"`
+        PlanType     string `json:"planType"`     // pos_profit or pos_loss
+        TriggerPrice string `json:"triggerPrice"`
+        TriggerType  string `json:"triggerType"`  // mark_price or fill_price
+        ExecutePrice string `json:"executePrice"` // 0 executes at market
+        HoldSide     string `json:"holdSide"`     // long or short
+        Size         string `json:"size,omitempty"`      // moving_plan and profit_plan only
+        RangeRate    string `json:"rangeRate,omitempty"` // moving_plan callback (%)
+}
+
+// formatSize renders a size with the contract's step, or unrounded without contract metadata
+func (b *BitgetAPI) formatSize(symbol string, size float64) string {
+        if spec, ok := b.contractSpec(symbol); ok {
+                return spec.FormatSize(spec.RoundSize(size))
+        }
+        return strconv.FormatFloat(size, 'f', -1, 64)
+}
+
+// formatPrice renders a price with the contract's tick, or unrounded without contract metadata
+func (b *BitgetAPI) formatPrice(symbol string, price float64) string {
+        if spec, ok := b.contractSpec(symbol); ok {
+                return spec.FormatPrice(price)
+        }
+        return strconv.FormatFloat(price, 'f', -1, 64)
+}
+
+// PlaceTPSLOrder attaches a market take profit (pos_profit) or stop loss (pos_loss) to a long position
+// It is triggered by the last fill price, so a wick through the trigger closes the position even while the bot is down
+func (b *BitgetAPI) PlaceTPSLOrder(symbol string, planType string, triggerPrice float64) (*OrderResponse, error) {
+        endpoint := "/api/v2/mix/order/place-tpsl-order"
+        
+        tpslReq := TPSLOrderRequest{
+                MarginCoin:   "USDT",
+                ProductType:  "USDT-FUTURES",
+                Symbol:       symbol,
+                PlanType:     planType,
+                TriggerPrice: b.formatPrice(symbol, triggerPrice),
+                TriggerType:  "fill_price",
+                ExecutePrice: "0",
+                HoldSide:     string(PositionSideLong),
+        }
+        
+        fmt.Printf("🛡️ Placing %s plan order: %+v\n", planType, tpslReq)
+        
+        var orderResp OrderResponse
+        err := b.makeRequest("POST", endpoint, tpslReq, &orderResp)
+        if err != nil {
+                return nil, fmt.Errorf("failed to place %s order: %w", planType, err)
+        }
+        
+        fmt.Printf("✅ %s plan order placed: %s\n", planType, orderResp.OrderID)
+        return &orderResp, nil
+}
+
+// PlacePartialTakeProfit attaches a market take profit (profit_plan) for part of a long position
+// Unlike pos_profit it only closes size, so several can sit at different prices
+func (b *BitgetAPI) PlacePartialTakeProfit(symbol string, size float64, triggerPrice float64) (*OrderResponse, error) {
+        endpoint := "/api/v2/mix/order/place-tpsl-order"
+        
+        tpslReq := TPSLOrderRequest{
+                MarginCoin:   "USDT",
+                ProductType:  "USDT-FUTURES",
+                Symbol:       symbol,
+                PlanType:     PlanTypeProfitPlan,
+                TriggerPrice: b.formatPrice(symbol, triggerPrice),
+                TriggerType:  "fill_price",
+                ExecutePrice: "0",
+                HoldSide:     string(PositionSideLong),
+                Size:         b.formatSize(symbol, size),
+        }
+        
+        fmt.Printf("🪜 Placing partial take profit: %+v\n", tpslReq)
+        
+        var orderResp OrderResponse
+        err := b.makeRequest("POST", endpoint, tpslReq, &orderResp)
+        if err != nil {
+                return nil, fmt.Errorf("failed to place partial take profit: %w", err)
+        }
+        
+        fmt.Printf("✅ Partial take profit placed: %s\n", orderResp.OrderID)
+        return &orderResp, nil
+}
+
+// PlaceTrailingStopOrder attaches a trailing stop (moving_plan) to a long position
+// It is armed once the price reaches activationPrice and then closes size at market after a callbackRate% drop from the high
+func (b *BitgetAPI) PlaceTrailingStopOrder(symbol string, size float64, activationPrice float64, callbackRate float64) (*OrderResponse, error) {
+        endpoint := "/api/v2/mix/order/place-tpsl-order"
+        
+        tpslReq := TPSLOrderRequest{
+                MarginCoin:   "USDT",
+                ProductType:  "USDT-FUTURES",
+                Symbol:       symbol,
+                PlanType:     PlanTypeMovingPlan,
+                TriggerPrice: b.formatPrice(symbol, activationPrice),
+                TriggerType:  "fill_price",
+                ExecutePrice: "0",
+                HoldSide:     string(PositionSideLong),
+                Size:         b.formatSize(symbol, size),
+                RangeRate:    strconv.FormatFloat(callbackRate, 'f', 2, 64),
+        }
+        
+        fmt.Printf("🛡️ Placing trailing stop: %+v\n", tpslReq)
+        
+        var orderResp OrderResponse
+        err := b.makeRequest("POST", endpoint, tpslReq, &orderResp)
+        if err != nil {
+                return nil, fmt.Errorf("failed to place trailing stop: %w", err)
+        }
+        
+        fmt.Printf("✅ Trailing stop placed: %s\n", orderResp.OrderID)
+        return &orderResp, nil
+}
+
+// ModifyTrailingStopOrder changes the activation price and callback rate of a trailing stop
+func (b *BitgetAPI) ModifyTrailingStopOrder(symbol string, orderID string, size float64, activationPrice float64, callbackRate float64) error {
+        endpoint := "/api/v2/mix/order/modify-tpsl-order"
+        
+        modifyReq := map[string]interface{}{
+                "orderId":      orderID,
+                "marginCoin":   "USDT",
+                "productType":  "USDT-FUTURES",
+                "symbol":       symbol,
+                "triggerPrice": b.formatPrice(symbol, activationPrice),
+                "triggerType":  "fill_price",
+                "executePrice": "0",
+                "size":         b.formatSize(symbol, size),
+                "rangeRate":    strconv.FormatFloat(callbackRate, 'f', 2, 64),
+        }
+        
+        fmt.Printf("🛡️ Modifying trailing stop %s: %+v\n", orderID, modifyReq)
+        
+        var response interface{}
+        if err := b.makeRequest("POST", endpoint, modifyReq, &response); err != nil {
+                return fmt.Errorf("failed to modify trailing stop %s: %w", orderID, err)
+        }
+        return nil
+}
+
+// ModifyTPSLOrder moves the trigger price of a position take profit or stop loss order
+func (b *BitgetAPI) ModifyTPSLOrder(symbol string, orderID string, triggerPrice float64) error {
+        endpoint := "/api/v2/mix/order/modify-tpsl-order"
+        
+        modifyReq := map[string]interface{}{
+                "orderId":      orderID,
+                "marginCoin":   "USDT",
+                "productType":  "USDT-FUTURES",
+                "symbol":       symbol,
+                "triggerPrice": b.formatPrice(symbol, triggerPrice),
+                "triggerType":  "fill_price",
+                "executePrice": "0",
+                "size":         "",
+        }
+        
+        fmt.Printf("🛡️ Modifying plan order %s: trigger %s\n", orderID, modifyReq["triggerPrice"])
+        
+        var response interface{}
+        if err := b.makeRequest("POST", endpoint, modifyReq, &response); err != nil {
+                return fmt.Errorf("failed to modify plan order %s: %w", orderID, err)
+        }
+        return nil
+}
+
+// GetPlanOrderStatus returns the status of a finished take profit or stop loss plan order
+// (executed, fail_execute, cancelled), or "live" while it is still waiting for its trigger
+func (b *BitgetAPI) GetPlanOrderStatus(symbol string, orderID string) (string, error) {
+        endpoint := "/api/v2/mix/order/orders-plan-history"
+        params := map[string]string{
+                "orderId":     orderID,
+                "symbol":      symbol,
+                "planType":    "profit_loss",
+                "productType": "USDT-FUTURES",
+        }
+        
+        var history struct {
+                EntrustedList []struct {
+                        OrderID    string `json:"orderId"`
+                        PlanStatus string `json:"planStatus"`
+                } `json:"entrustedList"`
+        }
+        err := b.makeRequestWithRetry("GET", endpoint, params, nil, &history)
+        if err != nil {
+                return "", fmt.Errorf("failed to get plan order %s: %w", orderID, err)
+        }
+        
+        for _, order := range history.EntrustedList {
+                if order.OrderID == orderID {
+                        return order.PlanStatus, nil
+                }
+        }
+        return "live", nil
+}
+
+// CancelPlanOrder cancels a take profit or stop loss plan order
+func (b *BitgetAPI) CancelPlanOrder(symbol string, orderID string) error {
+        endpoint := "/api/v2/mix/order/cancel-plan-order"
+        
+        cancelReq := map[string]interface{}{
+                "orderIdList": []map[string]string{{"orderId": orderID}},
+                "symbol":      symbol,
+                "productType": "USDT-FUTURES",
+                "marginCoin":  "USDT",
+                "planType":    "profit_loss",
+        }
+        
+        fmt.Printf("🛡️ Cancelling plan order %s on %s\n", orderID, symbol)
+        
+        var response struct {
+                SuccessList []map[string]interface{} `json:"successList"`
+                FailureList []struct {
+                        OrderID  string `json:"orderId"`
+                        ErrorMsg string `json:"errorMsg"`
+                } `json:"failureList"`
+        }
+        if err := b.makeRequest("POST", endpoint, cancelReq, &response); err != nil {
+                return fmt.Errorf("failed to cancel plan order %s: %w", orderID, err)
+        }
+        if len(response.FailureList) > 0 {
+                return fmt.Errorf("failed to cancel plan order %s: %s", orderID, response.FailureList[0].ErrorMsg)
+        }
+        return nil
+}
+
 // AccountBalance represents account balance information
 type AccountBalance struct {
         MarginCoin        string `json:"marginCoin"`
@@ -386,6 +734,159 @@ func (b *BitgetAPI) GetAccountBalance() ([]AccountBalance, error) {
         return balances, nil
 }
 
+// ContractInfo is one USDT-M futures contract from the public contracts endpoint
+type ContractInfo struct {
+        Symbol         string `json:"symbol"`         // e.g. 1000SATSUSDT
+        BaseCoin       string `json:"baseCoin"`       // e.g. 1000SATS
+        QuoteCoin      string `json:"quoteCoin"`      // USDT
+        SymbolStatus   string `json:"symbolStatus"`   // normal, maintain, limit_open, restrictedAPI, off
+        PricePlace     string `json:"pricePlace"`     // Price decimal places
+        PriceEndStep   string `json:"priceEndStep"`   // Price tick step
+        VolumePlace    string `json:"volumePlace"`    // Size decimal places
+        SizeMultiplier string `json:"sizeMultiplier"` // Size step
+        MinTradeNum    string `json:"minTradeNum"`    // Minimum order size (base coin)
+        MinTradeUSDT   string `json:"minTradeUSDT"`   // Minimum order value
+        MaxLever       string `json:"maxLever"`
+}
+
+// GetContracts lists all USDT-M futures contracts (public endpoint, no credentials needed)
+func (b *BitgetAPI) GetContracts() ([]ContractInfo, error) {
+        var contracts []ContractInfo
+        err := b.getPublic("/api/v2/mix/market/contracts", map[string]string{"productType": "USDT-FUTURES"}, &contracts)
+        if err != nil {
+                return nil, err
+        }
+        return contracts, nil
+}
+
+// Ticker is the 24h market snapshot of a futures contract
+type Ticker struct {
+        Symbol    string `json:"symbol"`
+        LastPr    string `json:"lastPr"`
+        Open24h   string `json:"open24h"`   // Price 24h ago, or the first traded price of a younger contract
+        High24h   string `json:"high24h"`
+        Low24h    string `json:"low24h"`
+        Change24h string `json:"change24h"` // Ratio, e.g. "0.125" for +12.5%
+        Timestamp string `json:"ts"`
+}
+
+// GetTicker gets the 24h ticker of a contract (public endpoint)
+func (b *BitgetAPI) GetTicker(symbol string) (*Ticker, error) {
+        var tickers []Ticker
+        err := b.getPublic("/api/v2/mix/market/ticker", map[string]string{"symbol": symbol, "productType": "USDT-FUTURES"}, &tickers)
+        if err != nil {
+                return nil, err
+        }
+        if len(tickers) == 0 {
+                return nil, fmt.Errorf("no ticker data for %s", symbol)
+        }
+        return &tickers[0], nil
+}
+
+// Candle is a 1 minute kline of a futures contract
+type Candle struct {
+        OpenTime time.Time
+        Open     float64
+        Close    float64
+}
+
+// GetCandles gets up to limit 1 minute candles ending at endTime, oldest first (public endpoint)
+func (b *BitgetAPI) GetCandles(symbol string, endTime time.Time, limit int) ([]Candle, error) {
+        var rows [][]string
+        err := b.getPublic("/api/v2/mix/market/candles", map[string]string{
+                "symbol":      symbol,
+                "productType": "USDT-FUTURES",
+                "granularity": "1m",
+                "endTime":     strconv.FormatInt(endTime.UnixMilli(), 10),
+                "limit":       strconv.Itoa(limit),
+        }, &rows)
+        if err != nil {
+                return nil, err
+        }
+        
+        candles := make([]Candle, 0, len(rows))
+        for _, row := range rows {
+                if len(row) < 5 {
+                        continue
+                }
+                ms, err := strconv.ParseInt(row[0], 10, 64)
+                if err != nil {
+                        continue
+                }
+                open, _ := strconv.ParseFloat(row[1], 64)
+                close, _ := strconv.ParseFloat(row[4], 64)
+                candles = append(candles, Candle{OpenTime: time.UnixMilli(ms), Open: open, Close: close})
+        }
+        return candles, nil
+}
+
+// PriceBefore returns the last traded price before t from the 1 minute candles
+// A candle that contains t contributes its open price, so nothing traded after t leaks in
+func (b *BitgetAPI) PriceBefore(symbol string, t time.Time) (float64, error) {
+        candles, err := b.GetCandles(symbol, t, 5)
+        if err != nil {
+                return 0, err
+        }
+        
+        price := 0.0
+        for _, candle := range candles {
+                if !candle.OpenTime.Before(t) {
+                        continue
+                }
+                if candle.OpenTime.Add(time.Minute).After(t) {
+                        price = candle.Open
+                } else {
+                        price = candle.Close
+                }
+        }
+        if price <= 0 {
+                return 0, fmt.Errorf("no candles for %s before %s", symbol, t.Format(time.RFC3339))
+        }
+        return price, nil
+}
+
+// getPublic makes an unsigned GET request to a public market endpoint
+func (b *BitgetAPI) getPublic(endpoint string, params map[string]string, result interface{}) error {
+        values := url.Values{}
+        for k, v := range params {
+                values.Add(k, v)
+        }
+        
+        req, err := http.NewRequest("GET", b.BaseURL+endpoint+"?"+values.Encode(), nil)
+        if err != nil {
+                return fmt.Errorf("failed to create request: %w", err)
+        }
+        req.Header.Set("locale", "en-US")
+        
+        resp, err := b.Client.Do(req)
+        if err != nil {
+                return fmt.Errorf("failed to make request: %w", err)
+        }
+        defer resp.Body.Close()
+        
+        respBody, err := io.ReadAll(resp.Body)
+        if err != nil {
+                return fmt.Errorf("failed to read response: %w", err)
+        }
+        
+        var apiResp struct {
+                Code    string          `json:"code"`
+                Message string          `json:"msg"`
+                Data    json.RawMessage `json:"data"`
+        }
+        if err := json.Unmarshal(respBody, &apiResp); err != nil {
+                return fmt.Errorf("failed to parse response (HTTP %d): %w", resp.StatusCode, err)
+        }
+        if apiResp.Code != "00000" {
+                return fmt.Errorf("API error: %s - %s", apiResp.Code, apiResp.Message)
+        }
+        
+        if err := json.Unmarshal(apiResp.Data, result); err != nil {
+                return fmt.Errorf("failed to unmarshal result: %w", err)
+        }
+        return nil
+}
+
 // GetSymbolPrice gets current symbol price using v2 API
 func (b *BitgetAPI) GetSymbolPrice(symbol string) (float64, error) {
         endpoint := "/api/v2/mix/market/ticker"
@@ -484,7 +985,7 @@ func (b *BitgetAPI) GetSymbolPrice(symbol string) (float64, error) {
 // makeRequestWithRetry makes authenticated HTTP request with retry logic for rate limiting
 func (b *BitgetAPI) makeRequestWithRetry(method, endpoint string, params map[string]string, body interface{}, result interface{}) error {
         maxRetries := 3
-        baseDelay := time.Second * 2
+        baseDelay := bitgetRetryBaseDelay
         
         for attempt := 0; attempt <= maxRetries; attempt++ {
                 err := b.makeRequestWithParams(method, endpoint, params, body, result)
@@ -0,0 +1,300 @@
+package services
+
+import (
+        "crypto/hmac"
+        "crypto/sha256"
+        "encoding/base64"
+        "encoding/json"
+        "fmt"
+        "log"
+        "strconv"
+        "sync"
+        "time"
+
+        "golang.org/x/net/websocket"
+)
+
+// BitgetPrivateStreamURL is Bitget's v2 private WebSocket endpoint
+const BitgetPrivateStreamURL = "wss://ws.bitget.com/v2/ws/private"
+
+// Private channels a trading stream subscribes to; "default" covers every USDT-M symbol of the account
+const (
+        StreamChannelOrders    = "orders"
+        StreamChannelPositions = "positions"
+        StreamChannelFill      = "fill"
+)
+
+// Stream timings; Bitget drops connections that send nothing for 2 minutes
+var (
+        streamPingInterval = 30 * time.Second
+        streamReadTimeout  = 90 * time.Second // No message (pongs included) for this long means the connection is dead
+        streamLoginTimeout = 10 * time.Second
+        streamMinBackoff   = time.Second
+        streamMaxBackoff   = time.Minute
+)
+
+// StreamArg identifies a WebSocket channel subscription
+type StreamArg struct {
+        InstType string `json:"instType"`
+        Channel  string `json:"channel"`
+        InstID   string `json:"instId"`
+}
+
+// StreamMessage is an event (login, subscribe, error) or a data push of the private stream
+type StreamMessage struct {
+        Event  string          `json:"event"`
+        Code   interface{}     `json:"code"` // Number on events, 0 on success
+        Msg    string          `json:"msg"`
+        Action string          `json:"action"` // snapshot or update on data pushes
+        Arg    StreamArg       `json:"arg"`
+        Data   json.RawMessage `json:"data"`
+}
+
+// StreamPosition is an entry of the positions channel
+type StreamPosition struct {
+        PosID        string `json:"posId"`
+        InstID       string `json:"instId"`
+        HoldSide     string `json:"holdSide"`
+        Total        string `json:"total"`        // Position size
+        OpenPriceAvg string `json:"openPriceAvg"`
+        MarkPrice    string `json:"markPrice"`
+        UnrealizedPL string `json:"unrealizedPL"`
+        Leverage     string `json:"leverage"`
+        UpdatedAt    string `json:"uTime"`
+}
+
+// StreamOrder is an entry of the orders channel
+type StreamOrder struct {
+        OrderID       string `json:"orderId"`
+        InstID        string `json:"instId"`
+        Side          string `json:"side"`
+        TradeSide     string `json:"tradeSide"`
+        ReduceOnly    string `json:"reduceOnly"`
+        Size          string `json:"size"`
+        AccBaseVolume string `json:"accBaseVolume"` // Filled size
+        PriceAvg      string `json:"priceAvg"`
+        Status        string `json:"status"` // live, partially_filled, filled, canceled
+}
+
+// StreamFill is an entry of the fill channel
+type StreamFill struct {
+        OrderID    string `json:"orderId"`
+        TradeID    string `json:"tradeId"`
+        Symbol     string `json:"symbol"`
+        Side       string `json:"side"`
+        TradeSide  string `json:"tradeSide"`
+        Price      string `json:"price"`
+        BaseVolume string `json:"baseVolume"`
+        Profit     string `json:"profit"` // Realized P&L of closing fills
+}
+
+// BitgetStream is one account's authenticated private WebSocket connection
+// Run keeps it connected: it logs in, subscribes, pings, and after a disconnect reconnects with backoff and
+// subscribes again. Data pushes are passed to the handler on the read goroutine.
+type BitgetStream struct {
+        URL        string
+        apiKey     string
+        apiSecret  string
+        passphrase string
+        args       []StreamArg
+        handler    func(StreamMessage)
+        
+        mutex       sync.Mutex
+        connected   bool // Logged in and subscribed
+        lastMessage time.Time
+        done        chan struct{}
+        stopOnce    sync.Once
+}
+
+// NewBitgetStream creates a stream for an account subscribed to the given channels of all USDT-M symbols
+func NewBitgetStream(apiKey, apiSecret, passphrase string, channels []string, handler func(StreamMessage)) *BitgetStream {
+        args := make([]StreamArg, len(channels))
+        for i, channel := range channels {
+                args[i] = StreamArg{InstType: "USDT-FUTURES", Channel: channel, InstID: "default"}
+        }
+        return &BitgetStream{
+                URL:        BitgetPrivateStreamURL,
+                apiKey:     apiKey,
+                apiSecret:  apiSecret,
+                passphrase: passphrase,
+                args:       args,
+                handler:    handler,
+                done:       make(chan struct{}),
+        }
+}
+
+// Connected reports whether the stream is logged in, subscribed and still receiving messages
+func (s *BitgetStream) Connected() bool {
+        s.mutex.Lock()
+        defer s.mutex.Unlock()
+        return s.connected && time.Since(s.lastMessage) < streamReadTimeout
+}
+
+// Stop closes the connection and ends Run
+func (s *BitgetStream) Stop() {
+        s.stopOnce.Do(func() { close(s.done) })
+}
+
+func (s *BitgetStream) stopped() bool {
+        select {
+        case <-s.done:
+                return true
+        default:
+                return false
+        }
+}
+
+// Run connects and reconnects until Stop (blocking)
+func (s *BitgetStream) Run() {
+        backoff := streamMinBackoff
+        for !s.stopped() {
+                started := time.Now()
+                err := s.session()
+                s.setConnected(false)
+                if s.stopped() {
+                        return
+                }
+                
+                // A connection that held for a while starts the backoff over
+                if time.Since(started) > streamMaxBackoff {
+                        backoff = streamMinBackoff
+                }
+                log.Printf("🔌 Bitget stream disconnected: %v, reconnecting in %v", err, backoff)
+                select {
+                case <-time.After(backoff):
+                case <-s.done:
+                        return
+                }
+                backoff *= 2
+                if backoff > streamMaxBackoff {
+                        backoff = streamMaxBackoff
+                }
+        }
+}
+
+// session runs one connection: login, subscribe, then read until it fails
+func (s *BitgetStream) session() error {
+        conn, err := websocket.Dial(s.URL, "", "https://www.bitget.com")
+        if err != nil {
+                return fmt.Errorf("failed to connect: %w", err)
+        }
+        defer conn.Close()
+        
+        // Stop unblocks the read by closing the connection
+        closed := make(chan struct{})
+        defer close(closed)
+        go func() {
+                select {
+                case <-s.done:
+                        conn.Close()
+                case <-closed:
+                }
+        }()
+        
+        if err := s.login(conn); err != nil {
+                return err
+        }
+        if err := websocket.JSON.Send(conn, map[string]interface{}{"op": "subscribe", "args": s.args}); err != nil {
+                return fmt.Errorf("failed to subscribe: %w", err)
+        }
+        
+        go s.ping(conn, closed)
+        
+        for {
+                conn.SetReadDeadline(time.Now().Add(streamReadTimeout))
+                var raw string
+                if err := websocket.Message.Receive(conn, &raw); err != nil {
+                        return err
+                }
+                s.touch()
+                if raw == "pong" {
+                        continue
+                }
+                
+                var msg StreamMessage
+                if err := json.Unmarshal([]byte(raw), &msg); err != nil {
+                        log.Printf("⚠️ Unreadable Bitget stream message: %v", err)
+                        continue
+                }
+                switch msg.Event {
+                case "subscribe":
+                        if !s.Connected() {
+                                log.Printf("🔌 Bitget stream subscribed")
+                        }
+                        s.setConnected(true)
+                case "error":
+                        log.Printf("⚠️ Bitget stream error %v: %s", msg.Code, msg.Msg)
+                case "":
+                        if len(msg.Data) > 0 && s.handler != nil {
+                                s.handler(msg)
+                        }
+                }
+        }
+}
+
+// login sends the signed login request and waits for its answer
+// The signature is base64(HMAC-SHA256(secret, timestamp + "GET" + "/user/verify")) with the timestamp in seconds
+func (s *BitgetStream) login(conn *websocket.Conn) error {
+        timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+        mac := hmac.New(sha256.New, []byte(s.apiSecret))
+        mac.Write([]byte(timestamp + "GET" + "/user/verify"))
+        
+        login := map[string]interface{}{
+                "op": "login",
+                "args": []map[string]string{{
+                        "apiKey":     s.apiKey,
+                        "passphrase": s.passphrase,
+                        "timestamp":  timestamp,
+                        "sign":       base64.StdEncoding.EncodeToString(mac.Sum(nil)),
+                }},
+        }
+        if err := websocket.JSON.Send(conn, login); err != nil {
+                return fmt.Errorf("failed to send login: %w", err)
+        }
+        
+        conn.SetReadDeadline(time.Now().Add(streamLoginTimeout))
+        for {
+                var msg StreamMessage
+                if err := websocket.JSON.Receive(conn, &msg); err != nil {
+                        return fmt.Errorf("no login answer: %w", err)
+                }
+                switch msg.Event {
+                case "login":
+                        if fmt.Sprint(msg.Code) != "0" {
+                                return fmt.Errorf("login failed: %v %s", msg.Code, msg.Msg)
+                        }
+                        s.touch()
+                        return nil
+                case "error":
+                        return fmt.Errorf("login failed: %v %s", msg.Code, msg.Msg)
+                }
+        }
+}
+
+// ping keeps the connection alive with Bitget's plain text ping; the server answers "pong"
+func (s *BitgetStream) ping(conn *websocket.Conn, closed chan struct{}) {
+        ticker := time.NewTicker(streamPingInterval)
+        defer ticker.Stop()
+        for {
+                select {
+                case <-ticker.C:
+                        if err := websocket.Message.Send(conn, "ping"); err != nil {
+                                return
+                        }
+                case <-closed:
+                        return
+                }
+        }
+}
+
+func (s *BitgetStream) setConnected(connected bool) {
+        s.mutex.Lock()
+        defer s.mutex.Unlock()
+        s.connected = connected
+}
+
+func (s *BitgetStream) touch() {
+        s.mutex.Lock()
+        defer s.mutex.Unlock()
+        s.lastMessage = time.Now()
+}
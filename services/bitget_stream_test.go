@@ -0,0 +1,304 @@
+package services
+
+import (
+        "crypto/hmac"
+        "crypto/sha256"
+        "encoding/base64"
+        "encoding/json"
+        "math"
+        "net/http/httptest"
+        "strings"
+        "sync"
+        "testing"
+        "time"
+        "upbit-bitget-trading-bot/models"
+
+        "golang.org/x/net/websocket"
+)
+
+// fakeStream is a Bitget private WebSocket server: it checks the login signature, acknowledges subscriptions,
+// answers pings and sends the pushes queued with push
+type fakeStream struct {
+        server *httptest.Server
+        secret string
+        
+        mutex         sync.Mutex
+        logins        int
+        pings         int
+        subscriptions [][]StreamArg
+        conns         []*websocket.Conn
+        pushes        chan string
+}
+
+func newFakeStream(t *testing.T, secret string) *fakeStream {
+        t.Helper()
+        
+        fake := &fakeStream{secret: secret, pushes: make(chan string, 10)}
+        fake.server = httptest.NewServer(websocket.Handler(fake.serve))
+        t.Cleanup(fake.server.Close)
+        
+        // Reconnects in milliseconds instead of seconds
+        backoff := streamMinBackoff
+        streamMinBackoff = 10 * time.Millisecond
+        t.Cleanup(func() { streamMinBackoff = backoff })
+        
+        return fake
+}
+
+func (f *fakeStream) url() string {
+        return "ws" + strings.TrimPrefix(f.server.URL, "http")
+}
+
+func (f *fakeStream) serve(conn *websocket.Conn) {
+        f.mutex.Lock()
+        f.conns = append(f.conns, conn)
+        f.mutex.Unlock()
+        
+        // Pushes go out on their own goroutine so pings are answered meanwhile
+        go func() {
+                for push := range f.pushes {
+                        if websocket.Message.Send(conn, push) != nil {
+                                return
+                        }
+                }
+        }()
+        
+        for {
+                var raw string
+                if err := websocket.Message.Receive(conn, &raw); err != nil {
+                        return
+                }
+                if raw == "ping" {
+                        f.mutex.Lock()
+                        f.pings++
+                        f.mutex.Unlock()
+                        websocket.Message.Send(conn, "pong")
+                        continue
+                }
+                
+                var req struct {
+                        Op   string            `json:"op"`
+                        Args []json.RawMessage `json:"args"`
+                }
+                json.Unmarshal([]byte(raw), &req)
+                switch req.Op {
+                case "login":
+                        var login map[string]string
+                        json.Unmarshal(req.Args[0], &login)
+                        mac := hmac.New(sha256.New, []byte(f.secret))
+                        mac.Write([]byte(login["timestamp"] + "GET" + "/user/verify"))
+                        if login["sign"] != base64.StdEncoding.EncodeToString(mac.Sum(nil)) {
+                                websocket.Message.Send(conn, `{"event":"error","code":30005,"msg":"Login failed"}`)
+                                continue
+                        }
+                        f.mutex.Lock()
+                        f.logins++
+                        f.mutex.Unlock()
+                        websocket.Message.Send(conn, `{"event":"login","code":0}`)
+                case "subscribe":
+                        var args []StreamArg
+                        for _, raw := range req.Args {
+                                var arg StreamArg
+                                json.Unmarshal(raw, &arg)
+                                args = append(args, arg)
+                                ack, _ := json.Marshal(map[string]interface{}{"event": "subscribe", "arg": arg})
+                                websocket.Message.Send(conn, string(ack))
+                        }
+                        f.mutex.Lock()
+                        f.subscriptions = append(f.subscriptions, args)
+                        f.mutex.Unlock()
+                }
+        }
+}
+
+// push queues a data push on channel
+func (f *fakeStream) push(channel string, action string, data interface{}) {
+        encoded, _ := json.Marshal(map[string]interface{}{
+                "action": action,
+                "arg":    StreamArg{InstType: "USDT-FUTURES", Channel: channel, InstID: "default"},
+                "data":   data,
+        })
+        f.pushes <- string(encoded)
+}
+
+// dropConnections closes the server side of every connection
+func (f *fakeStream) dropConnections() {
+        f.mutex.Lock()
+        defer f.mutex.Unlock()
+        for _, conn := range f.conns {
+                conn.Close()
+        }
+}
+
+func (f *fakeStream) counts() (logins, pings, subscriptions int) {
+        f.mutex.Lock()
+        defer f.mutex.Unlock()
+        return f.logins, f.pings, len(f.subscriptions)
+}
+
+// waitFor polls cond for up to 2 seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+        t.Helper()
+        deadline := time.Now().Add(2 * time.Second)
+        for !cond() {
+                if time.Now().After(deadline) {
+                        t.Fatalf("timed out waiting for %s", what)
+                }
+                time.Sleep(5 * time.Millisecond)
+        }
+}
+
+func TestBitgetStreamLoginSubscribeAndPush(t *testing.T) {
+        fake := newFakeStream(t, "test-secret")
+        ping := streamPingInterval
+        streamPingInterval = 20 * time.Millisecond
+        t.Cleanup(func() { streamPingInterval = ping })
+        
+        received := make(chan StreamMessage, 1)
+        stream := NewBitgetStream("test-key", "test-secret", "test-pass",
+                []string{StreamChannelOrders, StreamChannelPositions, StreamChannelFill},
+                func(msg StreamMessage) { received <- msg })
+        stream.URL = fake.url()
+        go stream.Run()
+        defer stream.Stop()
+        
+        waitFor(t, "subscription", func() bool { _, _, subscriptions := fake.counts(); return subscriptions == 1 && stream.Connected() })
+        fake.mutex.Lock()
+        args := fake.subscriptions[0]
+        fake.mutex.Unlock()
+        if len(args) != 3 || args[1] != (StreamArg{InstType: "USDT-FUTURES", Channel: "positions", InstID: "default"}) {
+                t.Errorf("subscribed to %+v", args)
+        }
+        
+        fake.push(StreamChannelPositions, "snapshot", []StreamPosition{{InstID: "NEWUSDT", HoldSide: "long", Total: "250", MarkPrice: "2.5"}})
+        select {
+        case msg := <-received:
+                var positions []StreamPosition
+                json.Unmarshal(msg.Data, &positions)
+                if msg.Arg.Channel != StreamChannelPositions || len(positions) != 1 || positions[0].Total != "250" {
+                        t.Errorf("unexpected push %+v", msg)
+                }
+        case <-time.After(2 * time.Second):
+                t.Fatal("push not delivered")
+        }
+        
+        waitFor(t, "ping", func() bool { _, pings, _ := fake.counts(); return pings > 0 })
+}
+
+func TestBitgetStreamResubscribesAfterDisconnect(t *testing.T) {
+        fake := newFakeStream(t, "test-secret")
+        stream := NewBitgetStream("test-key", "test-secret", "test-pass", []string{StreamChannelPositions}, nil)
+        stream.URL = fake.url()
+        go stream.Run()
+        defer stream.Stop()
+        
+        waitFor(t, "first subscription", stream.Connected)
+        fake.dropConnections()
+        
+        waitFor(t, "resubscription", func() bool {
+                logins, _, subscriptions := fake.counts()
+                return logins == 2 && subscriptions == 2 && stream.Connected()
+        })
+}
+
+func TestBitgetStreamRejectedLogin(t *testing.T) {
+        fake := newFakeStream(t, "test-secret")
+        stream := NewBitgetStream("test-key", "wrong-secret", "test-pass", []string{StreamChannelPositions}, nil)
+        stream.URL = fake.url()
+        
+        if err := stream.session(); err == nil || !strings.Contains(err.Error(), "login failed") {
+                t.Fatalf("session error = %v, want a login failure", err)
+        }
+        if _, _, subscriptions := fake.counts(); subscriptions != 0 || stream.Connected() {
+                t.Error("subscribed without a login")
+        }
+}
+
+func TestApplyStreamPositionsRecordsTrancheWithoutPolling(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setPrice("NEWUSDT", 2)
+        fake.openPosition("NEWUSDT", 250, 2)
+        engine, telegram := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        api := fake.exchange()(fake.apiKey, fake.apiSecret, fake.passphrase)
+        
+        position := newLadderPosition(user)
+        if err := placeTrancheOrders(api, &position); err != nil {
+                t.Fatalf("placeTrancheOrders: %v", err)
+        }
+        fake.setPrice("NEWUSDT", 3.2)
+        
+        // The push carries the reduced size and the mark price, so nothing is read over REST but the fill status
+        ps := &positionStream{exchange: api, applied: make(map[uint]time.Time)}
+        engine.applyStreamPositions(ps, []models.Position{position},
+                []StreamPosition{{InstID: "NEWUSDT", HoldSide: "long", Total: "175", MarkPrice: "3.2"}}, true)
+        
+        if !telegram.sentContaining(user.TelegramID, "KADEMELİ TAKE PROFIT 1/2") {
+                t.Errorf("no tranche notification: %v", telegram.sent(user.TelegramID))
+        }
+        if fake.requestCount("/api/v2/mix/position/single-position") != 0 || fake.requestCount("/api/v2/mix/market/ticker") != 0 {
+                t.Error("stream update polled the position")
+        }
+        if telegram.sentContaining(user.TelegramID, "POZİSYON DURUMU") {
+                t.Error("stream update sent a P&L report")
+        }
+}
+
+func TestApplyStreamPositionsConfirmsMissingPosition(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setPrice("NEWUSDT", 2.5)
+        fake.openPosition("NEWUSDT", 250, 2)
+        engine, telegram := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        api := fake.exchange()(fake.apiKey, fake.apiSecret, fake.passphrase)
+        ps := &positionStream{exchange: api, applied: make(map[uint]time.Time)}
+        position := newTestPosition(user, 2)
+        
+        // An update without the position says nothing about it; a snapshot without it is checked over REST
+        engine.applyStreamPositions(ps, []models.Position{position}, nil, false)
+        if fake.requestCount("/api/v2/mix/position/single-position") != 0 {
+                t.Fatal("update without the position triggered a check")
+        }
+        engine.applyStreamPositions(ps, []models.Position{position}, nil, true)
+        if fake.requestCount("/api/v2/mix/position/single-position") != 1 {
+                t.Fatal("snapshot without the position was not confirmed over REST")
+        }
+        
+        // Still open on Bitget: nothing closed, no P&L report from the stream
+        if fake.position("NEWUSDT") == nil || math.Abs(fake.position("NEWUSDT").size-250) > 1e-9 {
+                t.Error("position changed by a stale snapshot")
+        }
+        if len(telegram.sent(user.TelegramID)) != 0 {
+                t.Errorf("unexpected messages: %v", telegram.sent(user.TelegramID))
+        }
+}
+
+func TestPositionsDueThrottlesByInstrument(t *testing.T) {
+        ps := &positionStream{checked: make(map[string]streamPush)}
+        push := []StreamPosition{{InstID: "NEWUSDT", HoldSide: "long", Total: "250", MarkPrice: "2.1"}}
+        
+        if !ps.positionsDue(push, false) {
+                t.Fatal("first push of an instrument must load the positions")
+        }
+        // Mark price moves of an unchanged size inside the interval are dropped before any query
+        push[0].MarkPrice = "2.2"
+        if ps.positionsDue(push, false) {
+                t.Error("unchanged size loaded the positions again inside the interval")
+        }
+        if !ps.positionsDue(push, true) {
+                t.Error("snapshot not applied")
+        }
+        
+        push[0].Total = "175"
+        if !ps.positionsDue(push, false) {
+                t.Error("size change was throttled")
+        }
+        
+        ps.checked["NEWUSDT"] = streamPush{total: "175", at: time.Now().Add(-streamPositionInterval)}
+        if !ps.positionsDue(push, false) {
+                t.Error("push after the interval was dropped")
+        }
+        if ps.positionsDue([]StreamPosition{{InstID: "NEWUSDT", HoldSide: "short", Total: "10"}}, false) {
+                t.Error("short side push loaded the positions")
+        }
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+        "encoding/json"
+        "fmt"
+        "io"
+        "log"
+        "net/http"
+        "path"
+        "strings"
+        "time"
+        "upbit-bitget-trading-bot/models"
+)
+
+// BithumbNoticeSource reads Bithumb's public notice list API
+// Titles are parsed with the Bithumb rule set, and detections are tagged with the Bithumb exchange so
+// they are deduplicated separately from Upbit and only traded for users who enabled Bithumb
+type BithumbNoticeSource struct {
+        endpoint   string
+        interval   time.Duration
+        httpClient *http.Client
+        parser     *announcementParser
+        state      httpPollState
+        baseline   noticeBaseline // Notices already published on the first run are recorded, not traded
+}
+
+// bithumbNotice is a single entry of the notice list
+type bithumbNotice struct {
+        Categories  []string `json:"categories"`
+        Title       string   `json:"title"`
+        PCURL       string   `json:"pc_url"`
+        PublishedAt string   `json:"published_at"` // "2006-01-02 15:04:05" in KST
+        ModifiedAt  string   `json:"modified_at"`
+}
+
+// bithumbErrorResponse is returned instead of the list on errors
+type bithumbErrorResponse struct {
+        Error struct {
+                Name    string `json:"name"`
+                Message string `json:"message"`
+        } `json:"error"`
+}
+
+// NewBithumbNoticeSource creates the Bithumb notice source
+// It uses its own HTTP client: a Bithumb rate limit must not bench the Upbit proxies
+func NewBithumbNoticeSource(interval time.Duration, client *http.Client) *BithumbNoticeSource {
+        return &BithumbNoticeSource{
+                endpoint:   "https://api.bithumb.com/v1/notices?count=20",
+                interval:   interval,
+                httpClient: client,
+                parser:     &announcementParser{ruleSet: &activeBithumbRules},
+                state:      httpPollState{name: "bithumb_notice"},
+                baseline:   noticeBaseline{source: "bithumb_notice"},
+        }
+}
+
+// Name returns the source identifier
+func (s *BithumbNoticeSource) Name() string {
+        return "bithumb_notice"
+}
+
+// Interval returns the base polling interval
+func (s *BithumbNoticeSource) Interval() time.Duration {
+        return s.interval
+}
+
+// Poll fetches the notice list with conditional GET and rate limit handling
+func (s *BithumbNoticeSource) Poll() ([]CoinListing, error) {
+        if s.state.inBackoff() {
+                return nil, nil
+        }
+        
+        req, err := http.NewRequest("GET", s.endpoint, nil)
+        if err != nil {
+                s.state.handleError()
+                return nil, fmt.Errorf("failed to create request: %w", err)
+        }
+        
+        req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
+        req.Header.Set("Accept", "application/json")
+        req.Header.Set("Accept-Language", "ko-KR,ko;q=0.9,en-US;q=0.8")
+        s.state.applyConditionalHeaders(req)
+        
+        resp, err := s.httpClient.Do(req)
+        if err != nil {
+                s.state.handleError()
+                return nil, fmt.Errorf("failed to fetch Bithumb notice list: %w", err)
+        }
+        defer resp.Body.Close()
+        
+        if !s.state.handleStatus(resp) {
+                return nil, nil
+        }
+        
+        body, err := io.ReadAll(resp.Body)
+        if err != nil {
+                s.state.handleError()
+                return nil, fmt.Errorf("failed to read Bithumb notice list: %w", err)
+        }
+        
+        notices, err := parseBithumbNoticeList(body)
+        if err != nil {
+                s.state.handleError()
+                return nil, err
+        }
+        
+        return s.baseline.filter(s.listingsFromNotices(notices)), nil
+}
+
+// parseBithumbNoticeList decodes the endpoint payload (a JSON array, or an error object)
+func parseBithumbNoticeList(body []byte) ([]bithumbNotice, error) {
+        var notices []bithumbNotice
+        if err := json.Unmarshal(body, &notices); err == nil {
+                return notices, nil
+        }
+        
+        var errResp bithumbErrorResponse
+        if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error.Name != "" {
+                return nil, fmt.Errorf("Bithumb notice list error: %s - %s", errResp.Error.Name, errResp.Error.Message)
+        }
+        return nil, fmt.Errorf("failed to parse Bithumb notice list")
+}
+
+// listingsFromNotices turns listing notices into coin events
+// Delisting and warning titles are classified (so they are never read as listings) but not emitted:
+// risk policies act on positions regardless of the exchange that announced them, and only Upbit's count
+func (s *BithumbNoticeSource) listingsFromNotices(notices []bithumbNotice) []CoinListing {
+        var listings []CoinListing
+        
+        for _, notice := range notices {
+                if s.parser.classifyAnnouncement(notice.Title) != ListingKindNew {
+                        continue
+                }
+                
+                noticeID := path.Base(strings.TrimRight(notice.PCURL, "/"))
+                if noticeID == "." || noticeID == "/" {
+                        noticeID = ""
+                }
+                markets := s.parser.extractMarkets(notice.Title)
+                
+                for _, coin := range s.parser.extractCoinSymbols(notice.Title) {
+                        listings = append(listings, CoinListing{
+                                Symbol:            coin,
+                                Kind:              ListingKindNew,
+                                Exchange:          models.ExchangeBithumb,
+                                Source:            s.Name(),
+                                AnnouncementID:    noticeID,
+                                AnnouncementURL:   notice.PCURL,
+                                AnnouncementTitle: notice.Title,
+                                Markets:           markets,
+                                MarketType:        primaryMarket(markets),
+                                Category:          strings.Join(notice.Categories, ","),
+                                AnnouncedAt:       parseBithumbTime(notice.PublishedAt),
+                                DetectedAt:        time.Now(),
+                        })
+                }
+        }
+        
+        return listings
+}
+
+// parseBithumbTime parses Bithumb's KST "2006-01-02 15:04:05" timestamps, returns zero time when missing or invalid
+func parseBithumbTime(value string) time.Time {
+        if value == "" {
+                return time.Time{}
+        }
+        t, err := time.ParseInLocation("2006-01-02 15:04:05", value, koreaLocation())
+        if err != nil {
+                log.Printf("⚠️ Invalid Bithumb timestamp %q: %v", value, err)
+                return time.Time{}
+        }
+        return t
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+        "net/http"
+        "testing"
+        "time"
+        "upbit-bitget-trading-bot/models"
+)
+
+func TestBithumbNoticeSourceParsesFixture(t *testing.T) {
+        server := newNoticeFixtureServer(t, "bithumb_notices.json", func(w http.ResponseWriter, r *http.Request, body []byte) {
+                w.Header().Set("Content-Type", "application/json")
+                w.Write(body)
+        })
+        
+        source := NewBithumbNoticeSource(time.Minute, &http.Client{Timeout: 5 * time.Second})
+        source.endpoint = server.URL
+        source.baseline.ready = true
+        listings, err := source.Poll()
+        if err != nil {
+                t.Fatalf("Poll returned error: %v", err)
+        }
+        
+        // Warning, event and delisting notices are never emitted as listings
+        if len(listings) != 1 {
+                t.Fatalf("expected 1 listing, got %d: %+v", len(listings), listings)
+        }
+        
+        pengu := listings[0]
+        if pengu.Symbol != "PENGU" || pengu.Exchange != models.ExchangeBithumb || pengu.Source != "bithumb_notice" {
+                t.Errorf("unexpected listing: %+v", pengu)
+        }
+        if pengu.AnnouncementID != "1648210" || pengu.MarketType != MarketKRW {
+                t.Errorf("expected notice 1648210 on KRW, got %+v", pengu)
+        }
+        wantAnnounced := time.Date(2025, 6, 26, 8, 0, 5, 0, time.UTC)
+        if !pengu.AnnouncedAt.Equal(wantAnnounced) {
+                t.Errorf("expected announced at %v, got %v", wantAnnounced, pengu.AnnouncedAt)
+        }
+}
+
+func TestBithumbNoticeSourceFirstPollIsBaseline(t *testing.T) {
+        server := newNoticeFixtureServer(t, "bithumb_notices.json", func(w http.ResponseWriter, r *http.Request, body []byte) {
+                w.Header().Set("Content-Type", "application/json")
+                w.Write(body)
+        })
+        
+        source := NewBithumbNoticeSource(time.Minute, &http.Client{Timeout: 5 * time.Second})
+        source.endpoint = server.URL
+        for poll := 1; poll <= 2; poll++ {
+                if listings, err := source.Poll(); err != nil || len(listings) != 0 {
+                        t.Fatalf("poll %d: expected the published PENGU listing to be baseline only, got %d (err=%v)", poll, len(listings), err)
+                }
+        }
+}
+
+func TestParseBithumbNoticeListError(t *testing.T) {
+        _, err := parseBithumbNoticeList([]byte(`{"error":{"name":"too_many_requests","message":"slow down"}}`))
+        if err == nil {
+                t.Fatal("expected error response to fail")
+        }
+}
+
+func TestProcessedKeysSeparateExchanges(t *testing.T) {
+        upbit := processedKeys(models.ExchangeUpbit, ListingKindNew, "PENGU", []string{MarketKRW})
+        bithumb := processedKeys(models.ExchangeBithumb, ListingKindNew, "PENGU", []string{MarketKRW})
+        if upbit[0] != "PENGU@KRW" || bithumb[0] != "bithumb|PENGU@KRW" {
+                t.Errorf("unexpected keys: upbit %v, bithumb %v", upbit, bithumb)
+        }
+        if processedKeySymbol(bithumb[0]) != "PENGU" {
+                t.Errorf("processedKeySymbol(%q) = %q", bithumb[0], processedKeySymbol(bithumb[0]))
+        }
+        
+        um := &UpbitMonitor{processedCoins: map[string]bool{"PENGU": true}}
+        if um.isProcessed(models.ExchangeBithumb, ListingKindNew, "PENGU", bithumb) {
+                t.Error("an Upbit listing must not hide the Bithumb listing")
+        }
+}
+
+func TestTradeAmountFor(t *testing.T) {
+        user := models.User{TradeAmount: 100}
+        if user.TradeAmountFor(models.ExchangeBithumb) != 100 {
+                t.Error("Bithumb amount should fall back to the trade amount")
+        }
+        user.BithumbTradeAmount = 30
+        if user.TradeAmountFor(models.ExchangeBithumb) != 30 || user.TradeAmountFor("") != 100 {
+                t.Error("unexpected per-exchange trade amounts")
+        }
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+        "fmt"
+        "log"
+        "math"
+        "net/http"
+        "strconv"
+        "sync"
+        "time"
+)
+
+// ContractSpec is the order precision and limits of one USDT-M futures contract
+type ContractSpec struct {
+        Symbol         string
+        Status         string  // normal, maintain, limit_open, restrictedAPI, off
+        PricePlace     int     // Price decimal places
+        PriceEndStep   float64 // Price tick, in units of the last price decimal
+        VolumePlace    int     // Size decimal places
+        SizeMultiplier float64 // Size step
+        MinTradeNum    float64 // Minimum order size (base coin)
+        MinTradeUSDT   float64 // Minimum order value
+        MaxLever       int
+}
+
+// ContractCache keeps Bitget's contract metadata so orders can be rounded and checked before they are sent
+// It is refreshed periodically, and on demand (rate limited) when a symbol is missing, e.g. a contract listed
+// since the last refresh
+type ContractCache struct {
+        mutex       sync.RWMutex
+        specs       map[string]ContractSpec
+        lastRefresh time.Time
+        lastAttempt time.Time
+        fetch       func() ([]ContractInfo, error)
+}
+
+// contractMissRefreshInterval limits refreshes triggered by unknown symbols
+const contractMissRefreshInterval = 10 * time.Second
+
+// BitgetContracts is the contract metadata every BitgetAPI client validates orders against
+var BitgetContracts = NewContractCache(fetchBitgetContracts)
+
+// NewContractCache creates an empty cache filled by fetch
+func NewContractCache(fetch func() ([]ContractInfo, error)) *ContractCache {
+        return &ContractCache{
+                specs: make(map[string]ContractSpec),
+                fetch: fetch,
+        }
+}
+
+// fetchBitgetContracts reads the public contract list
+func fetchBitgetContracts() ([]ContractInfo, error) {
+        api := &BitgetAPI{BaseURL: "https://api.bitget.com", Client: &http.Client{Timeout: 30 * time.Second}}
+        return api.GetContracts()
+}
+
+// StartRefresh loads the contract metadata now and then refreshes it periodically (blocking)
+func (c *ContractCache) StartRefresh(interval time.Duration) {
+        if interval <= 0 {
+                interval = 10 * time.Minute
+        }
+        for {
+                if err := c.Refresh(); err != nil {
+                        log.Printf("❌ Contract metadata refresh failed: %v", err)
+                }
+                time.Sleep(interval)
+        }
+}
+
+// Refresh reloads the contract list; on failure the previous metadata stays in use
+func (c *ContractCache) Refresh() error {
+        c.mutex.Lock()
+        c.lastAttempt = time.Now()
+        c.mutex.Unlock()
+        
+        contracts, err := c.fetch()
+        if err != nil {
+                return fmt.Errorf("failed to fetch Bitget contracts: %w", err)
+        }
+        
+        specs := make(map[string]ContractSpec, len(contracts))
+        for _, contract := range contracts {
+                specs[contract.Symbol] = contractSpec(contract)
+        }
+        if len(specs) == 0 {
+                return fmt.Errorf("Bitget returned an empty contract list")
+        }
+        
+        c.mutex.Lock()
+        c.specs = specs
+        c.lastRefresh = time.Now()
+        c.mutex.Unlock()
+        
+        log.Printf("📐 Contract metadata refreshed: %d contracts", len(specs))
+        return nil
+}
+
+// Spec returns the metadata of a contract, refreshing once when it is unknown
+func (c *ContractCache) Spec(symbol string) (ContractSpec, bool) {
+        c.mutex.RLock()
+        spec, ok := c.specs[symbol]
+        stale := time.Since(c.lastAttempt) > contractMissRefreshInterval
+        c.mutex.RUnlock()
+        if ok || !stale {
+                return spec, ok
+        }
+        
+        if err := c.Refresh(); err != nil {
+                log.Printf("⚠️ %v", err)
+                return ContractSpec{}, false
+        }
+        
+        c.mutex.RLock()
+        defer c.mutex.RUnlock()
+        spec, ok = c.specs[symbol]
+        return spec, ok
+}
+
+// contractSpec parses the string fields of a contract
+func contractSpec(contract ContractInfo) ContractSpec {
+        spec := ContractSpec{
+                Symbol:         contract.Symbol,
+                Status:         contract.SymbolStatus,
+                PricePlace:     parseContractInt(contract.PricePlace),
+                PriceEndStep:   parseContractFloat(contract.PriceEndStep),
+                VolumePlace:    parseContractInt(contract.VolumePlace),
+                SizeMultiplier: parseContractFloat(contract.SizeMultiplier),
+                MinTradeNum:    parseContractFloat(contract.MinTradeNum),
+                MinTradeUSDT:   parseContractFloat(contract.MinTradeUSDT),
+                MaxLever:       parseContractInt(contract.MaxLever),
+        }
+        return spec
+}
+
+func parseContractInt(value string) int {
+        parsed, _ := strconv.Atoi(value)
+        return parsed
+}
+
+func parseContractFloat(value string) float64 {
+        parsed, _ := strconv.ParseFloat(value, 64)
+        return parsed
+}
+
+// sizeStep is the smallest size increment of the contract
+func (s ContractSpec) sizeStep() float64 {
+        step := math.Pow10(-s.VolumePlace)
+        if s.SizeMultiplier > step {
+                step = s.SizeMultiplier
+        }
+        return step
+}
+
+// RoundSize rounds a size down to the contract's size step, so an order never exceeds the margin it was sized for
+func (s ContractSpec) RoundSize(size float64) float64 {
+        step := s.sizeStep()
+        steps := math.Floor(size/step + 1e-9)
+        return roundToPlaces(steps*step, s.VolumePlace)
+}
+
+// FormatSize renders a rounded size with the contract's decimal places
+func (s ContractSpec) FormatSize(size float64) string {
+        return strconv.FormatFloat(size, 'f', s.VolumePlace, 64)
+}
+
+// RoundPrice rounds a price to the contract's tick
+func (s ContractSpec) RoundPrice(price float64) float64 {
+        tick := math.Pow10(-s.PricePlace)
+        if s.PriceEndStep > 1 {
+                tick *= s.PriceEndStep
+        }
+        return roundToPlaces(math.Round(price/tick)*tick, s.PricePlace)
+}
+
+// FormatPrice renders a rounded price with the contract's decimal places
+func (s ContractSpec) FormatPrice(price float64) string {
+        return strconv.FormatFloat(s.RoundPrice(price), 'f', s.PricePlace, 64)
+}
+
+// ClampLeverage limits a leverage to the contract maximum
+func (s ContractSpec) ClampLeverage(leverage int) int {
+        if s.MaxLever > 0 && leverage > s.MaxLever {
+                return s.MaxLever
+        }
+        return leverage
+}
+
+// ValidateOrder checks a rounded size (and its value at price, when known) against the contract minimums
+func (s ContractSpec) ValidateOrder(size float64, price float64) error {
+        if s.Status != "" && s.Status != "normal" {
+                return fmt.Errorf("%s is not open for trading (status %s)", s.Symbol, s.Status)
+        }
+        if size <= 0 || size < s.MinTradeNum {
+                return fmt.Errorf("order size %s below the %s minimum of %g", s.FormatSize(size), s.Symbol, s.MinTradeNum)
+        }
+        if price > 0 && s.MinTradeUSDT > 0 && size*price < s.MinTradeUSDT {
+                return fmt.Errorf("order value %.2f USDT below the %s minimum of %g USDT", size*price, s.Symbol, s.MinTradeUSDT)
+        }
+        return nil
+}
+
+func roundToPlaces(value float64, places int) float64 {
+        factor := math.Pow10(places)
+        return math.Round(value*factor) / factor
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+        "errors"
+        "strings"
+        "testing"
+        "time"
+)
+
+func TestContractSpecRounding(t *testing.T) {
+        whole := contractSpec(ContractInfo{Symbol: "PEPEUSDT", SymbolStatus: "normal", PricePlace: "8", PriceEndStep: "1",
+                VolumePlace: "0", SizeMultiplier: "100", MinTradeNum: "100", MinTradeUSDT: "5", MaxLever: "50"})
+        fractional := contractSpec(ContractInfo{Symbol: "ETHUSDT", SymbolStatus: "normal", PricePlace: "2", PriceEndStep: "5",
+                VolumePlace: "2", SizeMultiplier: "0.01", MinTradeNum: "0.01", MinTradeUSDT: "5", MaxLever: "125"})
+        
+        tests := []struct {
+                spec ContractSpec
+                size float64
+                want string
+        }{
+                {whole, 1234567.89, "1234500"},
+                {whole, 99.9, "0"},
+                {fractional, 0.4199999, "0.41"},
+                {fractional, 0.07, "0.07"}, // Exact steps survive float error
+                {fractional, 3, "3.00"},
+        }
+        for _, tt := range tests {
+                if got := tt.spec.FormatSize(tt.spec.RoundSize(tt.size)); got != tt.want {
+                        t.Errorf("%s RoundSize(%v) = %s, want %s", tt.spec.Symbol, tt.size, got, tt.want)
+                }
+        }
+        
+        if got := fractional.FormatPrice(2501.337); got != "2501.35" {
+                t.Errorf("ETHUSDT FormatPrice = %s, want 2501.35 (0.05 tick)", got)
+        }
+        if got := whole.ClampLeverage(75); got != 50 {
+                t.Errorf("ClampLeverage(75) = %d, want 50", got)
+        }
+        if got := whole.ClampLeverage(10); got != 10 {
+                t.Errorf("ClampLeverage(10) = %d, want 10", got)
+        }
+}
+
+func TestContractSpecValidateOrder(t *testing.T) {
+        spec := contractSpec(ContractInfo{Symbol: "NEWUSDT", SymbolStatus: "normal", VolumePlace: "1", SizeMultiplier: "0.1",
+                MinTradeNum: "1", MinTradeUSDT: "5", MaxLever: "20"})
+        
+        if err := spec.ValidateOrder(10, 1); err != nil {
+                t.Errorf("valid order rejected: %v", err)
+        }
+        if err := spec.ValidateOrder(0.9, 100); err == nil || !strings.Contains(err.Error(), "size") {
+                t.Errorf("size below minTradeNum accepted: %v", err)
+        }
+        if err := spec.ValidateOrder(4, 1); err == nil || !strings.Contains(err.Error(), "USDT") {
+                t.Errorf("value below minTradeUSDT accepted: %v", err)
+        }
+        if err := spec.ValidateOrder(4, 0); err != nil {
+                t.Errorf("value checked without a price: %v", err)
+        }
+        spec.Status = "limit_open"
+        if err := spec.ValidateOrder(10, 1); err == nil {
+                t.Error("order accepted on a contract closed for opening")
+        }
+}
+
+func TestContractCacheRefreshesOnMiss(t *testing.T) {
+        fetches := 0
+        listed := []ContractInfo{{Symbol: "BTCUSDT", VolumePlace: "4"}}
+        cache := NewContractCache(func() ([]ContractInfo, error) {
+                fetches++
+                return listed, nil
+        })
+        
+        if _, ok := cache.Spec("BTCUSDT"); !ok || fetches != 1 {
+                t.Fatalf("first lookup: ok=%v fetches=%d", ok, fetches)
+        }
+        
+        // A contract listed after the last refresh is picked up once the miss refresh interval passed
+        listed = append(listed, ContractInfo{Symbol: "NEWUSDT", VolumePlace: "0"})
+        if _, ok := cache.Spec("NEWUSDT"); ok || fetches != 1 {
+                t.Errorf("miss refreshed too soon: ok=%v fetches=%d", ok, fetches)
+        }
+        cache.lastAttempt = time.Now().Add(-contractMissRefreshInterval - time.Second)
+        if _, ok := cache.Spec("NEWUSDT"); !ok || fetches != 2 {
+                t.Errorf("miss not refreshed: ok=%v fetches=%d", ok, fetches)
+        }
+        
+        // A failed refresh keeps the previous metadata
+        cache.fetch = func() ([]ContractInfo, error) { return nil, errors.New("timeout") }
+        if err := cache.Refresh(); err == nil {
+                t.Error("refresh error not returned")
+        }
+        if _, ok := cache.Spec("BTCUSDT"); !ok {
+                t.Error("metadata lost after a failed refresh")
+        }
+}
+
+func TestReducePositionSkipsOpenChecks(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setContract(ContractInfo{Symbol: "NEWUSDT", SymbolStatus: "restrictedAPI", VolumePlace: "0", SizeMultiplier: "1",
+                MinTradeNum: "10", MinTradeUSDT: "5", MaxLever: "20"})
+        fake.setPrice("NEWUSDT", 2)
+        fake.openPosition("NEWUSDT", 25, 2)
+        api := fake.exchange()(fake.apiKey, fake.apiSecret, fake.passphrase)
+        
+        // A restricted contract and a size below the minimum still close, rounded down to the step
+        order, err := api.ReducePosition("NEWUSDT", 5.7)
+        if err != nil {
+                t.Fatalf("ReducePosition: %v", err)
+        }
+        if order.Size != 5 || fake.position("NEWUSDT").size != 20 {
+                t.Errorf("closed %g, position left %g; want 5 closed and 20 left", order.Size, fake.position("NEWUSDT").size)
+        }
+        
+        if _, err := api.ReducePosition("NEWUSDT", 0.4); err == nil {
+                t.Error("close rounding to 0 was sent")
+        }
+        if _, err := api.OpenLongPosition("NEWUSDT", 100, 5); err == nil || !strings.Contains(err.Error(), "not open for trading") {
+                t.Errorf("open on a restricted contract: %v", err)
+        }
+}
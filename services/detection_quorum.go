@@ -0,0 +1,297 @@
+package services
+
+import (
+        "fmt"
+        "log"
+        "math"
+        "sort"
+        "strconv"
+        "strings"
+        "sync"
+        "time"
+        "upbit-bitget-trading-bot/models"
+)
+
+// QuorumConfig configures how many sources must confirm a new listing before it is traded
+type QuorumConfig struct {
+        Threshold float64            // Score a candidate needs; each distinct source adds its weight
+        Window    time.Duration      // Confirmations must arrive within this time of the first detection
+        Weights   map[string]float64 // Per-source weights; "*" is the default for unlisted sources (1 if absent)
+}
+
+// DetectionQuorum holds new listings until enough distinct sources have confirmed them
+// With weight 1 per source and threshold 2, two sources must agree; a source weighted at or above the
+// threshold (a high-trust source) confirms alone. External signals are weighted by their reported confidence,
+// and sources parsing the same notice (same ID or title) count as one piece of evidence.
+type DetectionQuorum struct {
+        config QuorumConfig
+        
+        mutex      sync.Mutex
+        candidates map[string]*detectionCandidate // Pending candidates by exchange and symbol
+        expired    map[string]*detectionCandidate // Expired candidates by exchange and symbol, so re-polled sources don't restart them
+        unreported []*models.DetectionDecision     // Expired while observing, handed out by the next Expire
+}
+
+// detectionCandidate is a symbol waiting for quorum
+type detectionCandidate struct {
+        key         string             // Exchange-qualified symbol, so Upbit and Bithumb listings never merge
+        listing     CoinListing        // Base listing that will be emitted (announcements preferred over market list hits)
+        firstSeenAt time.Time
+        sources     []string           // Confirming sources in order
+        weights     map[string]float64 // Weight contributed by each source
+        notices     map[string]float64 // Weight counted per notice (by ID and by title), so sources reading one notice count once
+        score       float64
+}
+
+// expiredCandidateRetention is how long an expired candidate keeps ignoring sources that already reported it
+const expiredCandidateRetention = 24 * time.Hour
+
+// NewDetectionQuorum creates an aggregator; a threshold <= 0 is treated as 1 (any single source confirms)
+func NewDetectionQuorum(config QuorumConfig) *DetectionQuorum {
+        if config.Threshold <= 0 {
+                config.Threshold = 1
+        }
+        if config.Window <= 0 {
+                config.Window = 2 * time.Minute
+        }
+        if config.Weights == nil {
+                config.Weights = make(map[string]float64)
+        }
+        
+        log.Printf("🗳️ Detection quorum: score %.2f within %v (weights: %s)", config.Threshold, config.Window, formatSourceWeights(config.Weights))
+        
+        return &DetectionQuorum{
+                config:     config,
+                candidates: make(map[string]*detectionCandidate),
+                expired:    make(map[string]*detectionCandidate),
+        }
+}
+
+// ParseSourceWeights reads "source:weight" entries; "*:weight" sets the default weight
+func ParseSourceWeights(entries []string) map[string]float64 {
+        weights := make(map[string]float64)
+        for _, entry := range entries {
+                name, value, ok := strings.Cut(strings.TrimSpace(entry), ":")
+                name = strings.ToLower(strings.TrimSpace(name))
+                weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+                if !ok || name == "" || err != nil || weight < 0 {
+                        log.Printf("⚠️ Ignoring invalid source weight %q", entry)
+                        continue
+                }
+                weights[name] = weight
+        }
+        return weights
+}
+
+// Window returns the confirmation window
+func (q *DetectionQuorum) Window() time.Duration {
+        return q.config.Window
+}
+
+// weight returns how much a detection counts toward the quorum
+func (q *DetectionQuorum) weight(listing CoinListing) float64 {
+        weight, ok := q.config.Weights[strings.ToLower(listing.Source)]
+        if !ok {
+                weight, ok = q.config.Weights["*"]
+        }
+        if !ok {
+                weight = 1
+        }
+        // Signals scale the source weight by their confidence; one reporting none counts for nothing
+        if strings.HasPrefix(listing.Source, signalSourcePrefix) {
+                weight *= math.Max(listing.Confidence, 0)
+        }
+        return weight
+}
+
+// Observe adds a detection of a new listing
+// Returns the listing to emit and its decision once the candidate reaches quorum, nil while it is still held
+func (q *DetectionQuorum) Observe(listing CoinListing, now time.Time) (*CoinListing, *models.DetectionDecision) {
+        q.mutex.Lock()
+        defer q.mutex.Unlock()
+        
+        symbol := listing.Symbol
+        key := exchangeSymbol(listing.Exchange, symbol)
+        candidate, pending := q.candidates[key]
+        if pending && now.Sub(candidate.firstSeenAt) > q.config.Window {
+                q.unreported = append(q.unreported, q.expire(candidate, now))
+                candidate, pending = nil, false
+        }
+        
+        if !pending {
+                // Sources that already reported an expired candidate are ignored, a new source starts over
+                if old, ok := q.expired[key]; ok {
+                        if _, seen := old.weights[listing.Source]; seen {
+                                return nil, nil
+                        }
+                }
+                candidate = &detectionCandidate{
+                        key:         key,
+                        listing:     listing,
+                        firstSeenAt: now,
+                        weights:     make(map[string]float64),
+                        notices:     make(map[string]float64),
+                }
+                q.candidates[key] = candidate
+        }
+        
+        if _, seen := candidate.weights[listing.Source]; !seen {
+                weight := candidate.countNotice(listing, q.weight(listing))
+                candidate.sources = append(candidate.sources, listing.Source)
+                candidate.weights[listing.Source] = weight
+                candidate.score += weight
+                candidate.merge(listing)
+                
+                if candidate.score < q.config.Threshold {
+                        log.Printf("⏳ Holding %s for confirmation: %s (score %.2f/%.2f, window %v)",
+                                symbol, candidate.sourceList(), candidate.score, q.config.Threshold, q.config.Window)
+                }
+        }
+        
+        if candidate.score < q.config.Threshold {
+                return nil, nil
+        }
+        
+        delete(q.candidates, key)
+        delete(q.expired, key)
+        
+        confirmed := candidate.listing
+        confirmed.Confirmations = append([]string{}, candidate.sources...)
+        reason := fmt.Sprintf("%d source(s) scored %.2f >= %.2f after %v", len(candidate.sources), candidate.score,
+                q.config.Threshold, now.Sub(candidate.firstSeenAt).Round(time.Millisecond))
+        return &confirmed, candidate.decision(models.DetectionConfirmed, q.config.Threshold, now, reason)
+}
+
+// Expire closes candidates whose window has passed and returns their decisions
+func (q *DetectionQuorum) Expire(now time.Time) []*models.DetectionDecision {
+        q.mutex.Lock()
+        defer q.mutex.Unlock()
+        
+        decisions := q.unreported
+        q.unreported = nil
+        for _, candidate := range q.candidates {
+                if now.Sub(candidate.firstSeenAt) > q.config.Window {
+                        decisions = append(decisions, q.expire(candidate, now))
+                }
+        }
+        for key, candidate := range q.expired {
+                if now.Sub(candidate.firstSeenAt) > expiredCandidateRetention {
+                        delete(q.expired, key)
+                }
+        }
+        
+        sort.Slice(decisions, func(i, j int) bool { return decisions[i].Symbol < decisions[j].Symbol })
+        return decisions
+}
+
+// expire moves a pending candidate to the expired set (caller holds mutex)
+func (q *DetectionQuorum) expire(candidate *detectionCandidate, now time.Time) *models.DetectionDecision {
+        delete(q.candidates, candidate.key)
+        q.expired[candidate.key] = candidate
+        
+        reason := fmt.Sprintf("only %d source(s) scored %.2f < %.2f within %v", len(candidate.sources), candidate.score,
+                q.config.Threshold, q.config.Window)
+        log.Printf("🗳️ %s not traded: %s (%s)", candidate.key, reason, candidate.sourceList())
+        return candidate.decision(models.DetectionExpired, q.config.Threshold, now, reason)
+}
+
+// countNotice returns the weight a detection adds once the notice it was parsed from is taken into account
+// The notice API and page scraper read the same Upbit notice, so a misparsed title must not confirm itself:
+// a notice counts once, with the weight of its highest weighted source
+func (c *detectionCandidate) countNotice(listing CoinListing, weight float64) float64 {
+        keys := noticeKeys(listing)
+        counted := 0.0
+        for _, key := range keys {
+                counted = math.Max(counted, c.notices[key])
+        }
+        for _, key := range keys {
+                c.notices[key] = math.Max(counted, weight)
+        }
+        if counted > 0 {
+                log.Printf("🔁 %s from %s reports a notice already counted for %s", listing.Symbol, listing.Source, c.sourceList())
+        }
+        return math.Max(weight-counted, 0)
+}
+
+// noticeKeys identifies the notice behind a detection by ID and by title (none for market list hits)
+func noticeKeys(listing CoinListing) []string {
+        if listing.FromMarketList {
+                return nil
+        }
+        var keys []string
+        if listing.AnnouncementID != "" {
+                keys = append(keys, "id:"+listing.AnnouncementID)
+        }
+        if title := strings.ToLower(strings.TrimSpace(listing.AnnouncementTitle)); title != "" {
+                keys = append(keys, "title:"+title)
+        }
+        return keys
+}
+
+// merge folds another source's detection into the candidate
+func (c *detectionCandidate) merge(listing CoinListing) {
+        if c.listing.FromMarketList && !listing.FromMarketList {
+                // Announcements carry the notice metadata; keep the earliest detection time
+                detectedAt := c.listing.DetectedAt
+                markets := c.listing.Markets
+                c.listing = listing
+                c.listing.DetectedAt = detectedAt
+                if !c.listing.AnnouncedAt.IsZero() {
+                        c.listing.Latency = detectedAt.Sub(c.listing.AnnouncedAt)
+                }
+                listing.Markets = markets
+        }
+        
+        for _, market := range listing.Markets {
+                if !containsString(c.listing.Markets, market) {
+                        c.listing.Markets = append(append([]string{}, c.listing.Markets...), market)
+                }
+        }
+        c.listing.MarketType = primaryMarket(c.listing.Markets)
+        if c.listing.TradingStartsAt.IsZero() {
+                c.listing.TradingStartsAt = listing.TradingStartsAt
+        }
+}
+
+func (c *detectionCandidate) sourceList() string {
+        parts := make([]string, 0, len(c.sources))
+        for _, source := range c.sources {
+                parts = append(parts, fmt.Sprintf("%s=%.2f", source, c.weights[source]))
+        }
+        return strings.Join(parts, ",")
+}
+
+func (c *detectionCandidate) decision(outcome string, threshold float64, now time.Time, reason string) *models.DetectionDecision {
+        return &models.DetectionDecision{
+                Symbol:            c.listing.Symbol,
+                Markets:           strings.Join(c.listing.Markets, ","),
+                Decision:          outcome,
+                Score:             c.score,
+                Threshold:         threshold,
+                SourceCount:       len(c.sources),
+                Sources:           c.sourceList(),
+                AnnouncementID:    c.listing.AnnouncementID,
+                AnnouncementTitle: c.listing.AnnouncementTitle,
+                FirstSeenAt:       c.firstSeenAt,
+                DecidedAt:         now,
+                WaitMs:            now.Sub(c.firstSeenAt).Milliseconds(),
+                Reason:            reason,
+        }
+}
+
+func formatSourceWeights(weights map[string]float64) string {
+        if len(weights) == 0 {
+                return "all 1"
+        }
+        names := make([]string, 0, len(weights))
+        for name := range weights {
+                names = append(names, name)
+        }
+        sort.Strings(names)
+        
+        parts := make([]string, 0, len(names))
+        for _, name := range names {
+                parts = append(parts, fmt.Sprintf("%s=%.2f", name, weights[name]))
+        }
+        return strings.Join(parts, ", ")
+}
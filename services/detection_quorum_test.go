@@ -0,0 +1,111 @@
+package services
+
+import (
+        "testing"
+        "time"
+        "upbit-bitget-trading-bot/models"
+)
+
+func TestDetectionQuorumNeedsTwoSources(t *testing.T) {
+        quorum := NewDetectionQuorum(QuorumConfig{Threshold: 2, Window: time.Minute})
+        start := time.Date(2025, 6, 26, 8, 0, 0, 0, time.UTC)
+        
+        api := CoinListing{Symbol: "NEW", Source: "upbit_notice_api", AnnouncementID: "5120", Markets: []string{MarketBTC}, DetectedAt: start}
+        if listing, _ := quorum.Observe(api, start); listing != nil {
+                t.Fatal("single source should be held")
+        }
+        // The same source polling again adds nothing
+        if listing, _ := quorum.Observe(api, start.Add(10*time.Second)); listing != nil {
+                t.Fatal("repeated source should not count twice")
+        }
+        
+        market := CoinListing{Symbol: "NEW", Source: "upbit_market_list", FromMarketList: true, Markets: []string{MarketKRW}, DetectedAt: start.Add(20 * time.Second)}
+        listing, decision := quorum.Observe(market, start.Add(20*time.Second))
+        if listing == nil || decision == nil {
+                t.Fatal("second source should confirm")
+        }
+        if listing.AnnouncementID != "5120" || listing.FromMarketList || !listing.DetectedAt.Equal(start) {
+                t.Errorf("announcement listing should be the base: %+v", listing)
+        }
+        if listing.MarketType != MarketKRW || len(listing.Markets) != 2 {
+                t.Errorf("markets not merged: %v (%s)", listing.Markets, listing.MarketType)
+        }
+        if len(listing.Confirmations) != 2 {
+                t.Errorf("confirmations = %v", listing.Confirmations)
+        }
+        if decision.Decision != models.DetectionConfirmed || decision.SourceCount != 2 || decision.WaitMs != 20000 {
+                t.Errorf("unexpected decision %+v", decision)
+        }
+}
+
+func TestDetectionQuorumHighTrustSource(t *testing.T) {
+        quorum := NewDetectionQuorum(QuorumConfig{
+                Threshold: 2,
+                Window:    time.Minute,
+                Weights:   ParseSourceWeights([]string{"upbit_notice_api:2", "signal_alpha:2", "*:1"}),
+        })
+        now := time.Now()
+        
+        if listing, _ := quorum.Observe(CoinListing{Symbol: "AAA", Source: "upbit_notice_api"}, now); listing == nil {
+                t.Error("high-trust source should confirm alone")
+        }
+        // Signal weight is scaled by its confidence: 2 * 0.6 = 1.2
+        if listing, _ := quorum.Observe(CoinListing{Symbol: "BBB", Source: "signal_alpha", Confidence: 0.6}, now); listing != nil {
+                t.Error("low-confidence signal should be held")
+        }
+        if listing, _ := quorum.Observe(CoinListing{Symbol: "BBB", Source: "upbit_notice_html"}, now); listing == nil {
+                t.Error("signal plus second source should confirm")
+        }
+        // A signal reporting no confidence adds nothing, not its full source weight
+        quorum.Observe(CoinListing{Symbol: "CCC", Source: "signal_alpha", Confidence: 0}, now)
+        if listing, _ := quorum.Observe(CoinListing{Symbol: "CCC", Source: "upbit_notice_html"}, now); listing != nil {
+                t.Error("zero-confidence signal counted toward the quorum")
+        }
+}
+
+func TestDetectionQuorumCountsOneNoticeOnce(t *testing.T) {
+        quorum := NewDetectionQuorum(QuorumConfig{Threshold: 2, Window: time.Minute})
+        now := time.Now()
+        title := "Market Support for Toshi(TOSHI) (KRW, USDT Market)"
+        
+        api := CoinListing{Symbol: "TOSHI", Source: "upbit_notice_api", AnnouncementID: "5012", AnnouncementTitle: title}
+        html := CoinListing{Symbol: "TOSHI", Source: "upbit_notice_html", AnnouncementID: "5012", AnnouncementTitle: title}
+        quorum.Observe(api, now)
+        if listing, _ := quorum.Observe(html, now.Add(5*time.Second)); listing != nil {
+                t.Fatal("API and page scraper reading the same notice must not confirm each other")
+        }
+        // The scraper may miss the notice ID; the title still identifies the notice
+        html.Symbol, api.Symbol, html.AnnouncementID = "OPEN", "OPEN", ""
+        quorum.Observe(api, now)
+        if listing, _ := quorum.Observe(html, now.Add(5*time.Second)); listing != nil {
+                t.Fatal("same title without an ID counted as a second notice")
+        }
+        
+        market := CoinListing{Symbol: "TOSHI", Source: "upbit_market_list", FromMarketList: true, AnnouncementID: "market:KRW-TOSHI"}
+        if listing, _ := quorum.Observe(market, now.Add(10*time.Second)); listing == nil {
+                t.Error("an independent source should still confirm")
+        }
+}
+
+func TestDetectionQuorumExpiry(t *testing.T) {
+        quorum := NewDetectionQuorum(QuorumConfig{Threshold: 2, Window: time.Minute})
+        start := time.Now()
+        
+        quorum.Observe(CoinListing{Symbol: "BAD", Source: "upbit_notice_api"}, start)
+        if decisions := quorum.Expire(start.Add(30 * time.Second)); len(decisions) != 0 {
+                t.Fatalf("expired inside the window: %+v", decisions)
+        }
+        
+        decisions := quorum.Expire(start.Add(2 * time.Minute))
+        if len(decisions) != 1 || decisions[0].Decision != models.DetectionExpired || decisions[0].Symbol != "BAD" {
+                t.Fatalf("unexpected decisions %+v", decisions)
+        }
+        
+        // The source keeps re-reporting the notice: ignored, no new candidate or decision
+        if listing, _ := quorum.Observe(CoinListing{Symbol: "BAD", Source: "upbit_notice_api"}, start.Add(3*time.Minute)); listing != nil {
+                t.Error("expired candidate re-confirmed by the same source")
+        }
+        if decisions := quorum.Expire(start.Add(10 * time.Minute)); len(decisions) != 0 {
+                t.Errorf("repeated source restarted the candidate: %+v", decisions)
+        }
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+        "time"
+)
+
+// Exchange is the futures exchange API the trading engine and the Telegram bot trade through
+// BitgetAPI is the production implementation; tests point a BitgetAPI at an in-process fake server
+type Exchange interface {
+        IsSymbolValid(symbol string) bool
+        GetSymbolPrice(symbol string) (float64, error)
+        GetTicker(symbol string) (*Ticker, error)
+        PriceBefore(symbol string, t time.Time) (float64, error)
+        OpenLongPosition(symbol string, marginUSDT float64, leverage int) (*OrderResponse, error)
+        ClosePosition(symbol string, size float64, side PositionSide) (*OrderResponse, error)
+        FlashClosePosition(symbol string, holdSide string) (*OrderResponse, error)
+        PlaceTPSLOrder(symbol string, planType string, triggerPrice float64) (*OrderResponse, error)
+        ModifyTPSLOrder(symbol string, orderID string, triggerPrice float64) error
+        PlaceTrailingStopOrder(symbol string, size float64, activationPrice float64, callbackRate float64) (*OrderResponse, error)
+        ModifyTrailingStopOrder(symbol string, orderID string, size float64, activationPrice float64, callbackRate float64) error
+        PlacePartialTakeProfit(symbol string, size float64, triggerPrice float64) (*OrderResponse, error)
+        ReducePosition(symbol string, size float64) (*OrderResponse, error)
+        CancelPlanOrder(symbol string, orderID string) error
+        GetPlanOrderStatus(symbol string, orderID string) (string, error)
+        GetPosition(symbol string) (*BitgetPosition, error)
+        GetOrderDetail(symbol string, orderID string) (*OrderDetail, error)
+        GetAccountBalance() ([]AccountBalance, error)
+        GetContractSpec(symbol string) (ContractSpec, bool)
+}
+
+// ExchangeFactory creates an exchange client for a user's API credentials (empty for public endpoints)
+type ExchangeFactory func(apiKey, apiSecret, passphrase string) Exchange
+
+// NewBitgetExchange is the production ExchangeFactory
+func NewBitgetExchange(apiKey, apiSecret, passphrase string) Exchange {
+        return NewBitgetAPI(apiKey, apiSecret, passphrase)
+}
+
+var _ Exchange = (*BitgetAPI)(nil)
@@ -0,0 +1,177 @@
+package services
+
+import (
+        "errors"
+        "fmt"
+        "log"
+        "upbit-bitget-trading-bot/database"
+        "upbit-bitget-trading-bot/models"
+
+        "gorm.io/gorm"
+)
+
+// attachExitOrders places the exchange-side take profit (and stop loss, when set) of a newly opened position,
+// and the partial take profits of its ladder steps
+// monitorPositions keeps checking both locally, so a failure here only loses the protection
+// against wicks between checks and against the bot being down
+func (te *TradingEngine) attachExitOrders(bitgetAPI Exchange, telegramID int64, position *models.Position) {
+        if err := errors.Join(syncExitOrders(bitgetAPI, position), placeTrancheOrders(bitgetAPI, position)); err != nil {
+                log.Printf("⚠️ Failed to attach exit orders to %s for user %d: %v", position.Symbol, telegramID, err)
+                te.telegramBot.sendMessage(telegramID,
+                        fmt.Sprintf("⚠️ %s için Bitget TP/SL emirleri kurulamadı, take profit ve stop loss bot tarafından izlenecek: %v", position.Symbol, err))
+                return
+        }
+        log.Printf("🛡️ Exit orders attached to %s: take profit %s at %.6f, stop loss %s at %.6f",
+                position.Symbol, position.TakeProfitOrderID, position.TakeProfitPrice, position.StopLossOrderID, position.StopLossPrice)
+}
+
+// SyncUserExitOrders moves the exit orders of a user's open positions to the user's current settings
+// It returns how many positions were updated
+func (te *TradingEngine) SyncUserExitOrders(user models.User) (int, error) {
+        var positions []models.Position
+        err := database.WithDB(func(db *gorm.DB) error {
+                return db.Where("user_id = ? AND status = ?", user.ID, models.PositionOpen).Find(&positions).Error
+        })
+        if err != nil {
+                return 0, err
+        }
+        if len(positions) == 0 {
+                return 0, nil
+        }
+        
+        apiKey, apiSecret, passphrase, err := user.GetAPICredentials(te.encryptionKey)
+        if err != nil {
+                return 0, fmt.Errorf("failed to get API credentials: %w", err)
+        }
+        bitgetAPI := te.newExchange(apiKey, apiSecret, passphrase)
+        
+        userMutex := te.getUserMutex(user.TelegramID)
+        userMutex.Lock()
+        defer userMutex.Unlock()
+        
+        updated := 0
+        var errs []error
+        for _, position := range positions {
+                applyExitSettings(&position, user)
+                
+                if err := syncExitOrders(bitgetAPI, &position); err != nil {
+                        errs = append(errs, fmt.Errorf("%s: %w", position.Symbol, err))
+                }
+                
+                // The order IDs are saved even on failure so a replaced order is not placed twice
+                err := database.WithDB(func(db *gorm.DB) error {
+                        return db.Save(&position).Error
+                })
+                if err != nil {
+                        errs = append(errs, fmt.Errorf("%s: %w", position.Symbol, err))
+                        continue
+                }
+                updated++
+        }
+        
+        log.Printf("🛡️ Exit orders of user %d synced: %d/%d positions", user.TelegramID, updated, len(positions))
+        return updated, errors.Join(errs...)
+}
+
+// applyExitSettings sets a position's exit levels from the user's settings: a fixed take profit or a
+// trailing stop, and the stop loss
+func applyExitSettings(position *models.Position, user models.User) {
+        position.TakeProfitPrice = user.TakeProfitPrice(position.EntryPrice)
+        position.StopLossPrice = user.StopLossPrice(position.EntryPrice, position.Leverage)
+        position.TrailingActivationPrice = user.TrailingActivationPrice(position.EntryPrice)
+        position.TrailingCallbackRate = 0
+        if user.UsesTrailingStop() {
+                position.TrailingCallbackRate = user.TrailingCallbackPercentage
+        }
+}
+
+// syncExitOrders brings the plan orders of a position in line with its take profit, stop loss and trailing stop
+func syncExitOrders(bitgetAPI Exchange, position *models.Position) error {
+        tpErr := syncExitOrder(bitgetAPI, position.Symbol, PlanTypePositionProfit, &position.TakeProfitOrderID, position.TakeProfitPrice)
+        slErr := syncExitOrder(bitgetAPI, position.Symbol, PlanTypePositionLoss, &position.StopLossOrderID, position.StopLossPrice)
+        trErr := syncTrailingOrder(bitgetAPI, position)
+        return errors.Join(tpErr, slErr, trErr)
+}
+
+// syncTrailingOrder places, amends or cancels the moving_plan of a position
+// Callbacks wider than Bitget allows get no order and are trailed by monitorTrailingStops instead
+func syncTrailingOrder(bitgetAPI Exchange, position *models.Position) error {
+        onExchange := position.TrailingCallbackRate > 0 && position.TrailingCallbackRate <= bitgetMaxCallbackRate
+        switch {
+        case !onExchange && position.TrailingOrderID == "":
+                return nil
+        case !onExchange:
+                if err := bitgetAPI.CancelPlanOrder(position.Symbol, position.TrailingOrderID); err != nil {
+                        return err
+                }
+                position.TrailingOrderID = ""
+                return nil
+        case position.TrailingOrderID != "":
+                err := bitgetAPI.ModifyTrailingStopOrder(position.Symbol, position.TrailingOrderID, position.Quantity,
+                        position.TrailingActivationPrice, position.TrailingCallbackRate)
+                if err == nil {
+                        return nil
+                }
+                log.Printf("⚠️ %v, placing a new trailing stop", err)
+        }
+        
+        orderResp, err := bitgetAPI.PlaceTrailingStopOrder(position.Symbol, position.Quantity,
+                position.TrailingActivationPrice, position.TrailingCallbackRate)
+        if err != nil {
+                position.TrailingOrderID = ""
+                return err
+        }
+        position.TrailingOrderID = orderResp.OrderID
+        return nil
+}
+
+// syncExitOrder places, amends or cancels one plan order; a price of 0 means no order
+func syncExitOrder(bitgetAPI Exchange, symbol string, planType string, orderID *string, price float64) error {
+        switch {
+        case price <= 0 && *orderID == "":
+                return nil
+        case price <= 0:
+                if err := bitgetAPI.CancelPlanOrder(symbol, *orderID); err != nil {
+                        return err
+                }
+                *orderID = ""
+                return nil
+        case *orderID != "":
+                err := bitgetAPI.ModifyTPSLOrder(symbol, *orderID, price)
+                if err == nil {
+                        return nil
+                }
+                // Triggered or cancelled on Bitget in the meantime; a position plan replaces any previous one
+                log.Printf("⚠️ %v, placing a new %s order", err, planType)
+        }
+        
+        orderResp, err := bitgetAPI.PlaceTPSLOrder(symbol, planType, price)
+        if err != nil {
+                return err
+        }
+        *orderID = orderResp.OrderID
+        return nil
+}
+
+// exchangeExitReason works out why a position disappeared from Bitget from the state of its plan orders
+func exchangeExitReason(bitgetAPI Exchange, position models.Position) string {
+        if position.StopLossOrderID != "" && planExecuted(bitgetAPI, position.Symbol, position.StopLossOrderID) {
+                return models.ExitReasonStopLoss
+        }
+        if position.TakeProfitOrderID != "" && planExecuted(bitgetAPI, position.Symbol, position.TakeProfitOrderID) {
+                return models.ExitReasonTakeProfit
+        }
+        if position.TrailingOrderID != "" && planExecuted(bitgetAPI, position.Symbol, position.TrailingOrderID) {
+                return models.ExitReasonTrailingStop
+        }
+        return models.ExitReasonExternal
+}
+
+func planExecuted(bitgetAPI Exchange, symbol string, orderID string) bool {
+        status, err := bitgetAPI.GetPlanOrderStatus(symbol, orderID)
+        if err != nil {
+                log.Printf("⚠️ %v", err)
+                return false
+        }
+        return status == "executed"
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+        "math"
+        "net/http"
+        "testing"
+        "time"
+        "upbit-bitget-trading-bot/models"
+)
+
+func TestProcessUserTradeAttachesTakeProfit(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setPrice("NEWUSDT", 2)
+        engine, _ := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        
+        if !engine.processUserTrade(user, CoinListing{Symbol: "NEW", Kind: ListingKindNew, DetectedAt: time.Now()}).Opened {
+                t.Fatal("processUserTrade did not open a position")
+        }
+        
+        plan := fake.plan("NEWUSDT", PlanTypePositionProfit)
+        if plan == nil || plan.triggerPrice != 4 {
+                t.Fatalf("take profit plan = %+v, want trigger 4", plan)
+        }
+        if fake.plan("NEWUSDT", PlanTypePositionLoss) != nil {
+                t.Error("stop loss placed without a stop loss price")
+        }
+        
+        // A wick through the target closes the position on the exchange, between two monitor cycles
+        fake.setPrice("NEWUSDT", 4.1)
+        fake.setPrice("NEWUSDT", 3)
+        if fake.position("NEWUSDT") != nil {
+                t.Error("take profit plan did not close the position")
+        }
+}
+
+func TestProcessUserTradeWithoutTakeProfitPlan(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setPrice("NEWUSDT", 2)
+        fake.fail("/api/v2/mix/order/place-tpsl-order", fakeFailure{status: http.StatusBadRequest, code: "43011", msg: "The parameter does not meet the specification"}, 1)
+        engine, telegram := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        
+        if !engine.processUserTrade(user, CoinListing{Symbol: "NEW", Kind: ListingKindNew, DetectedAt: time.Now()}).Opened {
+                t.Fatal("a failed take profit plan failed the entry")
+        }
+        if !telegram.sentContaining(user.TelegramID, "TP/SL emirleri kurulamadı") {
+                t.Errorf("user not told the take profit is only watched locally: %v", telegram.sent(user.TelegramID))
+        }
+}
+
+func TestSyncExitOrders(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setPrice("NEWUSDT", 2)
+        fake.openPosition("NEWUSDT", 250, 2)
+        api := fake.exchange()(fake.apiKey, fake.apiSecret, fake.passphrase)
+        
+        position := &models.Position{Symbol: "NEWUSDT", EntryPrice: 2, TakeProfitPrice: 4, StopLossPrice: 1.5}
+        if err := syncExitOrders(api, position); err != nil {
+                t.Fatalf("place: %v", err)
+        }
+        if position.TakeProfitOrderID == "" || position.StopLossOrderID == "" {
+                t.Fatalf("order IDs not recorded: %+v", position)
+        }
+        
+        // Amend in place, rounded to the contract's 4 price decimals
+        takeProfitID := position.TakeProfitOrderID
+        position.TakeProfitPrice = 6.123456
+        if err := syncExitOrders(api, position); err != nil {
+                t.Fatalf("amend: %v", err)
+        }
+        if plan := fake.plan("NEWUSDT", PlanTypePositionProfit); position.TakeProfitOrderID != takeProfitID || plan.triggerPrice != 6.1235 {
+                t.Errorf("take profit not amended: id %s -> %s, plan %+v", takeProfitID, position.TakeProfitOrderID, plan)
+        }
+        
+        // Removing the stop loss cancels it
+        position.StopLossPrice = 0
+        if err := syncExitOrders(api, position); err != nil {
+                t.Fatalf("cancel: %v", err)
+        }
+        if position.StopLossOrderID != "" || fake.plan("NEWUSDT", PlanTypePositionLoss) != nil {
+                t.Errorf("stop loss not cancelled: %+v", position)
+        }
+        
+        // An order gone on the exchange is placed again
+        position.TakeProfitOrderID = "plan-missing"
+        if err := syncExitOrders(api, position); err != nil {
+                t.Fatalf("replace: %v", err)
+        }
+        if position.TakeProfitOrderID == "plan-missing" || fake.plan("NEWUSDT", PlanTypePositionProfit) == nil {
+                t.Errorf("missing take profit not replaced: %+v", position)
+        }
+}
+
+func TestProcessUserTradeAttachesStopLoss(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setPrice("NEWUSDT", 2)
+        engine, _ := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        user.StopLossPercentage = 50
+        user.StopLossMode = models.StopLossModeROE
+        
+        if !engine.processUserTrade(user, CoinListing{Symbol: "NEW", Kind: ListingKindNew, DetectedAt: time.Now()}).Opened {
+                t.Fatal("processUserTrade did not open a position")
+        }
+        
+        // 50% of the margin at 5x is a 10% price drop
+        stop := fake.plan("NEWUSDT", PlanTypePositionLoss)
+        if stop == nil || stop.triggerPrice != 1.8 {
+                t.Fatalf("stop loss plan = %+v, want trigger 1.8", stop)
+        }
+        
+        fake.setPrice("NEWUSDT", 1.7)
+        if fake.position("NEWUSDT") != nil {
+                t.Fatal("stop loss plan did not close the position")
+        }
+        
+        api := fake.exchange()(fake.apiKey, fake.apiSecret, fake.passphrase)
+        position := newTestPosition(user, 2)
+        position.StopLossOrderID = stop.id
+        position.TakeProfitOrderID = "plan-1"
+        if reason := exchangeExitReason(api, position); reason != models.ExitReasonStopLoss {
+                t.Errorf("exit reason = %s, want %s", reason, models.ExitReasonStopLoss)
+        }
+}
+
+func TestUpdatePositionPNLStopLossFallback(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setPrice("NEWUSDT", 1.5)
+        fake.openPosition("NEWUSDT", 250, 2)
+        engine, telegram := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        
+        // No stop plan on the exchange (placing it failed), so the monitor closes the position
+        position := newTestPosition(user, 2)
+        position.StopLossPrice = 1.6
+        engine.updatePositionPNL(position)
+        
+        if fake.position("NEWUSDT") != nil {
+                t.Error("position still open below the stop price")
+        }
+        if !telegram.sentContaining(user.TelegramID, "STOP LOSS TETİKLENDİ") || !telegram.sentContaining(user.TelegramID, "yedek kontrol") {
+                t.Errorf("no stop loss notification: %v", telegram.sent(user.TelegramID))
+        }
+}
+
+func TestUserStopLossPrice(t *testing.T) {
+        tests := []struct {
+                percentage float64
+                mode       string
+                leverage   int
+                want       float64
+        }{
+                {0, models.StopLossModePrice, 5, 0},
+                {20, models.StopLossModePrice, 5, 1.6},
+                {20, "", 50, 1.6}, // Price mode is the default
+                {50, models.StopLossModeROE, 5, 1.8},
+                {100, models.StopLossModePrice, 5, 0}, // A 100% price stop never triggers
+        }
+        for _, tt := range tests {
+                user := models.User{StopLossPercentage: tt.percentage, StopLossMode: tt.mode}
+                if got := user.StopLossPrice(2, tt.leverage); math.Abs(got-tt.want) > 1e-9 {
+                        t.Errorf("StopLossPrice(%v%% %q, %dx) = %v, want %v", tt.percentage, tt.mode, tt.leverage, got, tt.want)
+                }
+        }
+}
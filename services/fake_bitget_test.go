@@ -0,0 +1,607 @@
+package services
+
+import (
+        "crypto/hmac"
+        "crypto/sha256"
+        "encoding/base64"
+        "encoding/json"
+        "fmt"
+        "io"
+        "math"
+        "net/http"
+        "net/http/httptest"
+        "sort"
+        "strconv"
+        "strings"
+        "sync"
+        "testing"
+        "time"
+
+        tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// fakeBitget is an in-process Bitget v2 USDT-M futures server
+// Private endpoints check ACCESS-KEY, ACCESS-PASSPHRASE and the ACCESS-SIGN HMAC like the real API;
+// market endpoints are public. Orders fill immediately at the current ticker price.
+type fakeBitget struct {
+        server     *httptest.Server
+        apiKey     string
+        apiSecret  string
+        passphrase string
+        
+        mutex     sync.Mutex
+        prices    map[string]float64          // Ticker price per symbol; unknown symbols don't exist
+        contracts map[string]ContractInfo     // Contract metadata per symbol, defaulted by setPrice
+        positions map[string]*fakePosition    // Open long positions per symbol
+        orders    map[string]fakeOrder        // Placed orders by ID
+        plans     map[string]*fakePlan        // Live position TP/SL plan orders by ID
+        planStatus map[string]string          // Finished plan orders: executed or cancelled
+        leverage  map[string]int              // Leverage set per symbol
+        available float64                     // USDT available balance
+        failures  map[string][]fakeFailure    // Queued failures per path, served before normal handling
+        requests  map[string]int              // Requests per path
+}
+
+type fakePosition struct {
+        size       float64
+        entryPrice float64
+        margin     float64
+}
+
+// fakePlan is a position take profit, stop loss or trailing stop, triggered by setPrice
+type fakePlan struct {
+        id           string
+        symbol       string
+        planType     string
+        triggerPrice float64
+        size         float64 // moving_plan and profit_plan size
+        rangeRate    float64 // moving_plan callback (%)
+        high         float64 // moving_plan high since it was armed, 0 while waiting for the trigger
+}
+
+type fakeOrder struct {
+        id        string
+        symbol    string
+        side      string
+        tradeSide string
+        size      float64
+        price     float64
+}
+
+// fakeFailure is an error response served instead of the normal one
+type fakeFailure struct {
+        status int
+        code   string
+        msg    string
+}
+
+var (
+        fakeRateLimit  = fakeFailure{status: http.StatusTooManyRequests, code: "429", msg: "Too Many Requests"}
+        fakeNoPosition = fakeFailure{status: http.StatusBadRequest, code: "22002", msg: "No position to close"}
+)
+
+func newFakeBitget(t *testing.T) *fakeBitget {
+        t.Helper()
+        
+        fake := &fakeBitget{
+                apiKey:     "test-key",
+                apiSecret:  "test-secret",
+                passphrase: "test-pass",
+                prices:     make(map[string]float64),
+                contracts:  make(map[string]ContractInfo),
+                positions:  make(map[string]*fakePosition),
+                orders:     make(map[string]fakeOrder),
+                plans:      make(map[string]*fakePlan),
+                planStatus: make(map[string]string),
+                leverage:   make(map[string]int),
+                available:  1000,
+                failures:   make(map[string][]fakeFailure),
+                requests:   make(map[string]int),
+        }
+        fake.server = httptest.NewServer(http.HandlerFunc(fake.handle))
+        t.Cleanup(fake.server.Close)
+        
+        // Rate limit retries back off for milliseconds instead of seconds
+        delay := bitgetRetryBaseDelay
+        bitgetRetryBaseDelay = time.Millisecond
+        t.Cleanup(func() { bitgetRetryBaseDelay = delay })
+        
+        return fake
+}
+
+// exchange returns a factory whose clients talk to the fake server and share a contract cache filled from it
+func (f *fakeBitget) exchange() ExchangeFactory {
+        contracts := NewContractCache(func() ([]ContractInfo, error) {
+                return (&BitgetAPI{BaseURL: f.server.URL, Client: f.server.Client()}).GetContracts()
+        })
+        return func(apiKey, apiSecret, passphrase string) Exchange {
+                api := NewBitgetAPI(apiKey, apiSecret, passphrase)
+                api.BaseURL = f.server.URL
+                api.Contracts = contracts
+                return api
+        }
+}
+
+// setPrice lists symbol at price, with default contract metadata unless setContract was called
+// A price crossing a plan order's trigger closes the position like Bitget would
+func (f *fakeBitget) setPrice(symbol string, price float64) {
+        f.mutex.Lock()
+        defer f.mutex.Unlock()
+        f.prices[symbol] = price
+        if _, ok := f.contracts[symbol]; !ok {
+                f.contracts[symbol] = ContractInfo{Symbol: symbol, SymbolStatus: "normal", PricePlace: "4", PriceEndStep: "1",
+                        VolumePlace: "2", SizeMultiplier: "0.01", MinTradeNum: "0.01", MinTradeUSDT: "5", MaxLever: "125"}
+        }
+        
+        for _, plan := range f.plans {
+                if plan.symbol != symbol {
+                        continue
+                }
+                // Partial take profits close their size and leave the rest of the position open
+                if position := f.positions[symbol]; plan.planType == PlanTypeProfitPlan && position != nil && price >= plan.triggerPrice {
+                        f.fill(symbol, "sell", "close", plan.size)
+                        delete(f.plans, plan.id)
+                        f.planStatus[plan.id] = "executed"
+                        position.size -= plan.size
+                        if position.size <= 1e-9 {
+                                f.closePosition(symbol)
+                                return
+                        }
+                        continue
+                }
+                profit := plan.planType == PlanTypePositionProfit && price >= plan.triggerPrice
+                loss := plan.planType == PlanTypePositionLoss && price <= plan.triggerPrice
+                if plan.planType == PlanTypeMovingPlan {
+                        if plan.high == 0 && price >= plan.triggerPrice || plan.high > 0 && price > plan.high {
+                                plan.high = price
+                        }
+                        loss = plan.high > 0 && price <= plan.high*(1-plan.rangeRate/100)
+                }
+                if position := f.positions[symbol]; position != nil && (profit || loss) {
+                        f.fill(symbol, "sell", "close", position.size)
+                        delete(f.plans, plan.id)
+                        f.planStatus[plan.id] = "executed"
+                        f.closePosition(symbol)
+                        return
+                }
+        }
+}
+
+// closePosition removes a position and, like Bitget, its plan orders (caller holds mutex)
+func (f *fakeBitget) closePosition(symbol string) {
+        delete(f.positions, symbol)
+        for id, plan := range f.plans {
+                if plan.symbol == symbol {
+                        delete(f.plans, id)
+                        f.planStatus[id] = "cancelled"
+                }
+        }
+}
+
+// plan returns the live plan order of a type on symbol
+func (f *fakeBitget) plan(symbol string, planType string) *fakePlan {
+        f.mutex.Lock()
+        defer f.mutex.Unlock()
+        for _, plan := range f.plans {
+                if plan.symbol == symbol && plan.planType == planType {
+                        return plan
+                }
+        }
+        return nil
+}
+
+// partialPlans returns the live partial take profits on symbol, lowest trigger first
+func (f *fakeBitget) partialPlans(symbol string) []*fakePlan {
+        f.mutex.Lock()
+        defer f.mutex.Unlock()
+        var plans []*fakePlan
+        for _, plan := range f.plans {
+                if plan.symbol == symbol && plan.planType == PlanTypeProfitPlan {
+                        plans = append(plans, plan)
+                }
+        }
+        sort.Slice(plans, func(i, j int) bool { return plans[i].triggerPrice < plans[j].triggerPrice })
+        return plans
+}
+
+func (f *fakeBitget) setContract(contract ContractInfo) {
+        f.mutex.Lock()
+        defer f.mutex.Unlock()
+        f.contracts[contract.Symbol] = contract
+}
+
+func (f *fakeBitget) openPosition(symbol string, size, entryPrice float64) {
+        f.mutex.Lock()
+        defer f.mutex.Unlock()
+        f.positions[symbol] = &fakePosition{size: size, entryPrice: entryPrice}
+}
+
+func (f *fakeBitget) position(symbol string) *fakePosition {
+        f.mutex.Lock()
+        defer f.mutex.Unlock()
+        return f.positions[symbol]
+}
+
+// fail queues failures for the next requests to path
+func (f *fakeBitget) fail(path string, failure fakeFailure, times int) {
+        f.mutex.Lock()
+        defer f.mutex.Unlock()
+        for i := 0; i < times; i++ {
+                f.failures[path] = append(f.failures[path], failure)
+        }
+}
+
+func (f *fakeBitget) requestCount(path string) int {
+        f.mutex.Lock()
+        defer f.mutex.Unlock()
+        return f.requests[path]
+}
+
+func (f *fakeBitget) handle(w http.ResponseWriter, r *http.Request) {
+        body, _ := io.ReadAll(r.Body)
+        
+        f.mutex.Lock()
+        defer f.mutex.Unlock()
+        f.requests[r.URL.Path]++
+        
+        if queued := f.failures[r.URL.Path]; len(queued) > 0 {
+                f.failures[r.URL.Path] = queued[1:]
+                f.writeError(w, queued[0])
+                return
+        }
+        
+        if !strings.HasPrefix(r.URL.Path, "/api/v2/mix/market/") {
+                if failure, ok := f.authenticate(r, body); !ok {
+                        f.writeError(w, failure)
+                        return
+                }
+        }
+        
+        switch r.URL.Path {
+        case "/api/v2/mix/market/ticker":
+                symbol := r.URL.Query().Get("symbol")
+                price, ok := f.prices[symbol]
+                if !ok {
+                        f.writeError(w, fakeFailure{status: http.StatusBadRequest, code: "40034", msg: "Parameter does not exist"})
+                        return
+                }
+                f.writeData(w, []map[string]string{{"symbol": symbol, "lastPr": formatFakeFloat(price), "open24h": formatFakeFloat(price)}})
+        case "/api/v2/mix/market/contracts":
+                contracts := []ContractInfo{}
+                for _, contract := range f.contracts {
+                        contracts = append(contracts, contract)
+                }
+                f.writeData(w, contracts)
+        case "/api/v2/mix/account/set-leverage":
+                var req struct {
+                        Symbol   string `json:"symbol"`
+                        Leverage string `json:"leverage"`
+                }
+                json.Unmarshal(body, &req)
+                leverage, err := strconv.Atoi(req.Leverage)
+                maxLever, _ := strconv.Atoi(f.contracts[req.Symbol].MaxLever)
+                if err != nil || leverage <= 0 || (maxLever > 0 && leverage > maxLever) {
+                        f.writeError(w, fakeFailure{status: http.StatusBadRequest, code: "40808", msg: "Parameter verification exception leverage"})
+                        return
+                }
+                f.leverage[req.Symbol] = leverage
+                f.writeData(w, map[string]string{"symbol": req.Symbol, "longLeverage": req.Leverage})
+        case "/api/v2/mix/order/place-order":
+                f.placeOrder(w, body)
+        case "/api/v2/mix/order/close-positions":
+                var req struct {
+                        Symbol string `json:"symbol"`
+                }
+                json.Unmarshal(body, &req)
+                position := f.positions[req.Symbol]
+                if position == nil {
+                        f.writeData(w, map[string]interface{}{
+                                "successList": []interface{}{},
+                                "failureList": []map[string]string{{"symbol": req.Symbol, "errorCode": "22002", "errorMsg": "No position to close"}},
+                        })
+                        return
+                }
+                order := f.fill(req.Symbol, "sell", "close", position.size)
+                f.closePosition(req.Symbol)
+                f.writeData(w, map[string]interface{}{
+                        "successList": []map[string]string{{"orderId": order.id, "clientOid": "", "symbol": req.Symbol}},
+                        "failureList": []interface{}{},
+                })
+        case "/api/v2/mix/order/place-tpsl-order":
+                var req TPSLOrderRequest
+                json.Unmarshal(body, &req)
+                trigger, failure, ok := f.triggerPrice(req.Symbol, req.TriggerPrice)
+                if !ok {
+                        f.writeError(w, failure)
+                        return
+                }
+                if f.positions[req.Symbol] == nil {
+                        f.writeError(w, fakeNoPosition)
+                        return
+                }
+                rangeRate, _ := strconv.ParseFloat(req.RangeRate, 64)
+                if req.PlanType == PlanTypeMovingPlan && (rangeRate <= 0 || rangeRate > bitgetMaxCallbackRate || req.Size == "") {
+                        f.writeError(w, fakeFailure{status: http.StatusBadRequest, code: "40808", msg: "Parameter verification exception rangeRate"})
+                        return
+                }
+                size, _ := strconv.ParseFloat(req.Size, 64)
+                if req.PlanType == PlanTypeProfitPlan && size <= 0 {
+                        f.writeError(w, fakeFailure{status: http.StatusBadRequest, code: "40808", msg: "Parameter verification exception size"})
+                        return
+                }
+                // A position has at most one position plan of each type; a new one replaces it
+                for id, plan := range f.plans {
+                        if plan.symbol == req.Symbol && plan.planType == req.PlanType && req.PlanType != PlanTypeProfitPlan {
+                                delete(f.plans, id)
+                        }
+                }
+                id := fmt.Sprintf("plan-%d", f.requests[r.URL.Path])
+                f.plans[id] = &fakePlan{id: id, symbol: req.Symbol, planType: req.PlanType, triggerPrice: trigger, size: size, rangeRate: rangeRate}
+                f.writeData(w, map[string]string{"orderId": id, "clientOid": ""})
+        case "/api/v2/mix/order/modify-tpsl-order":
+                var req struct {
+                        OrderID      string `json:"orderId"`
+                        Symbol       string `json:"symbol"`
+                        TriggerPrice string `json:"triggerPrice"`
+                        RangeRate    string `json:"rangeRate"`
+                        Size         string `json:"size"`
+                }
+                json.Unmarshal(body, &req)
+                plan := f.plans[req.OrderID]
+                if plan == nil {
+                        f.writeError(w, fakeFailure{status: http.StatusBadRequest, code: "40768", msg: "Order does not exist"})
+                        return
+                }
+                trigger, failure, ok := f.triggerPrice(req.Symbol, req.TriggerPrice)
+                if !ok {
+                        f.writeError(w, failure)
+                        return
+                }
+                plan.triggerPrice = trigger
+                if plan.planType == PlanTypeMovingPlan {
+                        plan.rangeRate, _ = strconv.ParseFloat(req.RangeRate, 64)
+                        plan.size, _ = strconv.ParseFloat(req.Size, 64)
+                }
+                f.writeData(w, map[string]string{"orderId": plan.id, "clientOid": ""})
+        case "/api/v2/mix/order/cancel-plan-order":
+                var req struct {
+                        OrderIDList []struct {
+                                OrderID string `json:"orderId"`
+                        } `json:"orderIdList"`
+                }
+                json.Unmarshal(body, &req)
+                success := []map[string]string{}
+                failure := []map[string]string{}
+                for _, entry := range req.OrderIDList {
+                        if f.plans[entry.OrderID] == nil {
+                                failure = append(failure, map[string]string{"orderId": entry.OrderID, "errorMsg": "Order does not exist"})
+                                continue
+                        }
+                        delete(f.plans, entry.OrderID)
+                        f.planStatus[entry.OrderID] = "cancelled"
+                        success = append(success, map[string]string{"orderId": entry.OrderID})
+                }
+                f.writeData(w, map[string]interface{}{"successList": success, "failureList": failure})
+        case "/api/v2/mix/order/orders-plan-history":
+                orders := []map[string]string{}
+                if status, ok := f.planStatus[r.URL.Query().Get("orderId")]; ok {
+                        orders = append(orders, map[string]string{"orderId": r.URL.Query().Get("orderId"), "planStatus": status})
+                }
+                f.writeData(w, map[string]interface{}{"entrustedList": orders, "endId": ""})
+        case "/api/v2/mix/position/single-position":
+                symbol := r.URL.Query().Get("symbol")
+                positions := []BitgetPosition{}
+                if position := f.positions[symbol]; position != nil {
+                        positions = append(positions, BitgetPosition{
+                                Symbol:     symbol,
+                                Size:       formatFakeFloat(position.size),
+                                Side:       "long",
+                                EntryPrice: formatFakeFloat(position.entryPrice),
+                                MarkPrice:  formatFakeFloat(f.prices[symbol]),
+                                Leverage:   strconv.Itoa(f.leverage[symbol]),
+                        })
+                }
+                f.writeData(w, positions)
+        case "/api/v2/mix/account/accounts":
+                f.writeData(w, []AccountBalance{{MarginCoin: "USDT", Available: formatFakeFloat(f.available), Equity: formatFakeFloat(f.available)}})
+        case "/api/v2/mix/order/detail":
+                order, ok := f.orders[r.URL.Query().Get("orderId")]
+                if !ok {
+                        f.writeError(w, fakeFailure{status: http.StatusBadRequest, code: "40768", msg: "Order does not exist"})
+                        return
+                }
+                f.writeData(w, OrderDetail{OrderID: order.id, Symbol: order.symbol, State: "filled",
+                        PriceAvg: formatFakeFloat(order.price), BaseVolume: formatFakeFloat(order.size)})
+        default:
+                f.writeError(w, fakeFailure{status: http.StatusNotFound, code: "40404", msg: "Request URL NOT FOUND"})
+        }
+}
+
+// triggerPrice parses a plan trigger price and checks it against the contract's price decimals (caller holds mutex)
+func (f *fakeBitget) triggerPrice(symbol string, value string) (float64, fakeFailure, bool) {
+        invalid := fakeFailure{status: http.StatusBadRequest, code: "40808", msg: "Parameter verification exception triggerPrice"}
+        price, err := strconv.ParseFloat(value, 64)
+        if err != nil || price <= 0 {
+                return 0, invalid, false
+        }
+        if contract, ok := f.contracts[symbol]; ok {
+                places, _ := strconv.Atoi(contract.PricePlace)
+                if dot := strings.Index(value, "."); dot >= 0 && len(value)-dot-1 > places {
+                        return 0, invalid, false
+                }
+        }
+        return price, fakeFailure{}, true
+}
+
+// authenticate validates the signed headers (caller holds mutex)
+func (f *fakeBitget) authenticate(r *http.Request, body []byte) (fakeFailure, bool) {
+        if r.Header.Get("ACCESS-KEY") != f.apiKey {
+                return fakeFailure{status: http.StatusBadRequest, code: "40006", msg: "Invalid ACCESS_KEY"}, false
+        }
+        if r.Header.Get("ACCESS-PASSPHRASE") != f.passphrase {
+                return fakeFailure{status: http.StatusBadRequest, code: "40012", msg: "apikey/password is incorrect"}, false
+        }
+        
+        path := r.URL.Path
+        if r.URL.RawQuery != "" {
+                path += "?" + r.URL.RawQuery
+        }
+        mac := hmac.New(sha256.New, []byte(f.apiSecret))
+        mac.Write([]byte(r.Header.Get("ACCESS-TIMESTAMP") + r.Method + path + string(body)))
+        expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+        if !hmac.Equal([]byte(r.Header.Get("ACCESS-SIGN")), []byte(expected)) {
+                return fakeFailure{status: http.StatusBadRequest, code: "40009", msg: "sign signature error"}, false
+        }
+        return fakeFailure{}, true
+}
+
+// placeOrder handles market orders (caller holds mutex)
+func (f *fakeBitget) placeOrder(w http.ResponseWriter, body []byte) {
+        var req OrderRequest
+        if err := json.Unmarshal(body, &req); err != nil {
+                f.writeError(w, fakeFailure{status: http.StatusBadRequest, code: "40017", msg: "Parameter verification failed"})
+                return
+        }
+        size, err := strconv.ParseFloat(req.Size, 64)
+        price, listed := f.prices[req.Symbol]
+        if err != nil || size <= 0 || !listed {
+                f.writeError(w, fakeFailure{status: http.StatusBadRequest, code: "40017", msg: "Parameter verification failed"})
+                return
+        }
+        if contract, ok := f.contracts[req.Symbol]; ok {
+                spec := contractSpec(contract)
+                decimals := 0
+                if dot := strings.Index(req.Size, "."); dot >= 0 {
+                        decimals = len(req.Size) - dot - 1
+                }
+                if decimals > spec.VolumePlace || math.Abs(spec.RoundSize(size)-size) > 1e-9 {
+                        f.writeError(w, fakeFailure{status: http.StatusBadRequest, code: "40762", msg: "The order size is not a multiple of the size step"})
+                        return
+                }
+                // Bitget lets closing orders through below the minimums, so a small remainder can still be closed
+                if req.TradeSide == "open" && (size < spec.MinTradeNum || size*price < spec.MinTradeUSDT) {
+                        f.writeError(w, fakeFailure{status: http.StatusBadRequest, code: "45111", msg: "less than the minimum order quantity"})
+                        return
+                }
+        }
+        
+        position := f.positions[req.Symbol]
+        switch req.TradeSide {
+        case "open":
+                margin := size * price / float64(max(f.leverage[req.Symbol], 1))
+                if margin > f.available {
+                        f.writeError(w, fakeFailure{status: http.StatusBadRequest, code: "40762", msg: "The order amount exceeds the balance"})
+                        return
+                }
+                f.available -= margin
+                if position == nil {
+                        position = &fakePosition{entryPrice: price}
+                        f.positions[req.Symbol] = position
+                }
+                position.size += size
+                position.margin += margin
+        case "close":
+                if position == nil {
+                        f.writeError(w, fakeNoPosition)
+                        return
+                }
+                if req.ReduceOnly == "YES" && size > position.size+1e-9 {
+                        f.writeError(w, fakeFailure{status: http.StatusBadRequest, code: "22004", msg: "reduce-only order exceeds the position size"})
+                        return
+                }
+                position.size -= size
+                if position.size <= 1e-9 {
+                        f.available += position.margin + (price-position.entryPrice)*(size+position.size)
+                        f.closePosition(req.Symbol)
+                }
+        }
+        
+        order := f.fill(req.Symbol, string(req.Side), req.TradeSide, size)
+        f.writeData(w, map[string]string{"orderId": order.id, "clientOid": req.ClientOID})
+}
+
+// fill records an order filled at the current price (caller holds mutex)
+func (f *fakeBitget) fill(symbol string, side string, tradeSide string, size float64) fakeOrder {
+        order := fakeOrder{
+                id:        fmt.Sprintf("order-%d", len(f.orders)+1),
+                symbol:    symbol,
+                side:      side,
+                tradeSide: tradeSide,
+                size:      size,
+                price:     f.prices[symbol],
+        }
+        f.orders[order.id] = order
+        return order
+}
+
+func (f *fakeBitget) writeData(w http.ResponseWriter, data interface{}) {
+        w.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(w).Encode(map[string]interface{}{"code": "00000", "msg": "success", "requestTime": 1, "data": data})
+}
+
+func (f *fakeBitget) writeError(w http.ResponseWriter, failure fakeFailure) {
+        w.Header().Set("Content-Type", "application/json")
+        w.WriteHeader(failure.status)
+        json.NewEncoder(w).Encode(map[string]interface{}{"code": failure.code, "msg": failure.msg, "requestTime": 1, "data": nil})
+}
+
+func formatFakeFloat(value float64) string {
+        return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// fakeTelegram is a Telegram Bot API server that records the texts sent to each chat
+type fakeTelegram struct {
+        mutex    sync.Mutex
+        messages map[int64][]string
+}
+
+// newFakeTelegramBot returns a TelegramBot whose Bot API calls go to an in-process server
+func newFakeTelegramBot(t *testing.T) (*TelegramBot, *fakeTelegram) {
+        t.Helper()
+        
+        fake := &fakeTelegram{messages: make(map[int64][]string)}
+        server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+                r.ParseForm()
+                w.Header().Set("Content-Type", "application/json")
+                if strings.HasSuffix(r.URL.Path, "/getMe") {
+                        w.Write([]byte(`{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"test","username":"test_bot"}}`))
+                        return
+                }
+                
+                chatID, _ := strconv.ParseInt(r.Form.Get("chat_id"), 10, 64)
+                fake.mutex.Lock()
+                fake.messages[chatID] = append(fake.messages[chatID], r.Form.Get("text"))
+                fake.mutex.Unlock()
+                fmt.Fprintf(w, `{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":%d,"type":"private"}}}`, chatID)
+        }))
+        t.Cleanup(server.Close)
+        
+        bot, err := tgbotapi.NewBotAPIWithAPIEndpoint("test-token", server.URL+"/bot%s/%s")
+        if err != nil {
+                t.Fatalf("failed to create fake Telegram bot: %v", err)
+        }
+        
+        return &TelegramBot{
+                Bot:            bot,
+                newExchange:    NewBitgetExchange,
+                adminIDs:       make(map[int64]bool),
+                userRateLimits: make(map[int64]*time.Ticker),
+        }, fake
+}
+
+// sent returns the texts sent to a chat
+func (f *fakeTelegram) sent(chatID int64) []string {
+        f.mutex.Lock()
+        defer f.mutex.Unlock()
+        return append([]string{}, f.messages[chatID]...)
+}
+
+// sentContaining reports whether any text sent to the chat contains substr
+func (f *fakeTelegram) sentContaining(chatID int64, substr string) bool {
+        for _, text := range f.sent(chatID) {
+                if strings.Contains(text, substr) {
+                        return true
+                }
+        }
+        return false
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+        "fmt"
+        "log"
+        "math"
+        "net/http"
+        "sort"
+        "strconv"
+        "time"
+        "upbit-bitget-trading-bot/database"
+        "upbit-bitget-trading-bot/models"
+
+        "gorm.io/gorm"
+)
+
+// LatencyStats summarizes one latency (or slippage) series
+type LatencyStats struct {
+        Count int
+        Sum   float64
+        P50   float64
+        P90   float64
+        P99   float64
+        Max   float64
+}
+
+// LatencyReport holds the latency percentiles of listings and entries since a point in time
+type LatencyReport struct {
+        Since     time.Time
+        Detection LatencyStats // Listing events: announced -> detected (ms)
+        Decision  LatencyStats // Entries: detected -> order sent, without scheduled waits (ms)
+        Ack       LatencyStats // Entries: order sent -> acknowledged (ms)
+        Total     LatencyStats // Entries: announced -> acknowledged, without scheduled waits (ms)
+        Wait      LatencyStats // Scheduled entries: wait for the entry time or the watchlist contract (ms)
+        Slippage  LatencyStats // Entries: fill vs pre-announcement price (%)
+}
+
+// recordTradeLatency stores the timings of an acknowledged entry order, then fills in the
+// pre-announcement and fill prices in the background (the order detail may lag the ack)
+// A deliberate wait before the entry is stored on its own and left out of the decision and total latencies
+func (te *TradingEngine) recordTradeLatency(bitgetAPI Exchange, user models.User, listing CoinListing, symbol string, order *OrderResponse) {
+        record := &models.TradeLatency{
+                UserID:         user.ID,
+                OrderID:        order.OrderID,
+                Symbol:         listing.Symbol,
+                BitgetSymbol:   symbol,
+                Exchange:       listing.Exchange,
+                Source:         listing.Source,
+                AnnouncementID: listing.AnnouncementID,
+                DetectedAt:     listing.DetectedAt,
+                OrderSentAt:    order.SentAt,
+                OrderAckAt:     order.AckAt,
+                AckMs:          order.AckAt.Sub(order.SentAt).Milliseconds(),
+        }
+        if listing.ReleasedAt.After(listing.DetectedAt) {
+                record.WaitMs = listing.ReleasedAt.Sub(listing.DetectedAt).Milliseconds()
+        }
+        reference := listing.DetectedAt
+        if !listing.AnnouncedAt.IsZero() {
+                announcedAt := listing.AnnouncedAt
+                record.AnnouncedAt = &announcedAt
+                record.DetectionMs = listing.DetectedAt.Sub(announcedAt).Milliseconds()
+                reference = announcedAt
+        }
+        record.DecisionMs = order.SentAt.Sub(listing.DetectedAt).Milliseconds() - record.WaitMs
+        record.TotalMs = order.AckAt.Sub(reference).Milliseconds() - record.WaitMs
+        
+        log.Printf("⏱️ %s entry for user %d: detection %dms, decision %dms, ack %dms, total %dms (scheduled wait %dms)",
+                listing.Symbol, user.TelegramID, record.DetectionMs, record.DecisionMs, record.AckMs, record.TotalMs, record.WaitMs)
+        
+        err := database.WithDB(func(db *gorm.DB) error {
+                return db.Create(record).Error
+        })
+        if err != nil {
+                log.Printf("⚠️ Failed to record latency for %s: %v", listing.Symbol, err)
+                return
+        }
+        
+        go completeTradeLatency(bitgetAPI, record, reference)
+}
+
+// completeTradeLatency reads the fill price and the last price before the announcement and stores the slippage
+func completeTradeLatency(bitgetAPI Exchange, record *models.TradeLatency, reference time.Time) {
+        for attempt := 0; attempt < 3 && record.FillPrice == 0; attempt++ {
+                if attempt > 0 {
+                        time.Sleep(2 * time.Second)
+                }
+                detail, err := bitgetAPI.GetOrderDetail(record.BitgetSymbol, record.OrderID)
+                if err != nil {
+                        log.Printf("⚠️ Failed to read fill of order %s: %v", record.OrderID, err)
+                        continue
+                }
+                record.FillPrice, _ = strconv.ParseFloat(detail.PriceAvg, 64)
+        }
+        
+        price, err := bitgetAPI.PriceBefore(record.BitgetSymbol, reference)
+        if err != nil {
+                log.Printf("⚠️ No pre-announcement price for %s: %v", record.BitgetSymbol, err)
+        } else {
+                record.ReferencePrice = price
+        }
+        
+        if record.FillPrice > 0 && record.ReferencePrice > 0 {
+                record.SlippagePct = (record.FillPrice - record.ReferencePrice) / record.ReferencePrice * 100
+                log.Printf("⏱️ %s filled at %.6f, %.2f%% from pre-announcement price %.6f",
+                        record.BitgetSymbol, record.FillPrice, record.SlippagePct, record.ReferencePrice)
+        }
+        
+        err = database.WithDB(func(db *gorm.DB) error {
+                return db.Model(record).Updates(map[string]interface{}{
+                        "fill_price":      record.FillPrice,
+                        "reference_price": record.ReferencePrice,
+                        "slippage_pct":    record.SlippagePct,
+                }).Error
+        })
+        if err != nil {
+                log.Printf("⚠️ Failed to update latency record %d: %v", record.ID, err)
+        }
+}
+
+// GetLatencyReport computes latency percentiles over listings and entries since the given time
+func GetLatencyReport(since time.Time) (*LatencyReport, error) {
+        var events []models.ListingEvent
+        var trades []models.TradeLatency
+        err := database.WithDB(func(db *gorm.DB) error {
+                err := db.Where("kind = ? AND announced_at IS NOT NULL AND created_at >= ?", string(ListingKindNew), since).
+                        Find(&events).Error
+                if err != nil {
+                        return err
+                }
+                return db.Where("created_at >= ?", since).Find(&trades).Error
+        })
+        if err != nil {
+                return nil, err
+        }
+        
+        report := summarizeLatencies(events, trades)
+        report.Since = since
+        return report, nil
+}
+
+// summarizeLatencies builds the percentiles of a report
+func summarizeLatencies(events []models.ListingEvent, trades []models.TradeLatency) *LatencyReport {
+        var detection, decision, ack, total, wait, slippage []float64
+        for _, event := range events {
+                if event.AnnouncedAt != nil {
+                        detection = append(detection, float64(event.LatencyMs))
+                }
+        }
+        for _, trade := range trades {
+                decision = append(decision, float64(trade.DecisionMs))
+                ack = append(ack, float64(trade.AckMs))
+                total = append(total, float64(trade.TotalMs))
+                if trade.WaitMs > 0 {
+                        wait = append(wait, float64(trade.WaitMs))
+                }
+                if trade.FillPrice > 0 && trade.ReferencePrice > 0 {
+                        slippage = append(slippage, trade.SlippagePct)
+                }
+        }
+        
+        return &LatencyReport{
+                Detection: newLatencyStats(detection),
+                Decision:  newLatencyStats(decision),
+                Ack:       newLatencyStats(ack),
+                Total:     newLatencyStats(total),
+                Wait:      newLatencyStats(wait),
+                Slippage:  newLatencyStats(slippage),
+        }
+}
+
+func newLatencyStats(values []float64) LatencyStats {
+        if len(values) == 0 {
+                return LatencyStats{}
+        }
+        sorted := append([]float64{}, values...)
+        sort.Float64s(sorted)
+        sum := 0.0
+        for _, value := range sorted {
+                sum += value
+        }
+        return LatencyStats{
+                Count: len(sorted),
+                Sum:   sum,
+                P50:   percentile(sorted, 50),
+                P90:   percentile(sorted, 90),
+                P99:   percentile(sorted, 99),
+                Max:   sorted[len(sorted)-1],
+        }
+}
+
+// percentile returns the nearest-rank percentile of sorted values
+func percentile(sorted []float64, p float64) float64 {
+        if len(sorted) == 0 {
+                return 0
+        }
+        rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+        if rank < 1 {
+                rank = 1
+        }
+        return sorted[rank-1]
+}
+
+// ServeLatencyMetrics exposes the latency report in the Prometheus text format on /metrics
+// The window defaults to 7 days and can be changed with ?hours=N
+func ServeLatencyMetrics(w http.ResponseWriter, r *http.Request) {
+        hours := 24 * 7
+        if value, err := strconv.Atoi(r.URL.Query().Get("hours")); err == nil && value > 0 {
+                hours = value
+        }
+        
+        report, err := GetLatencyReport(time.Now().Add(-time.Duration(hours) * time.Hour))
+        if err != nil {
+                http.Error(w, fmt.Sprintf("latency report unavailable: %v", err), http.StatusServiceUnavailable)
+                return
+        }
+        
+        w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+        writeLatencySummary(w, "listing_detection_latency_ms", "Announcement to detection latency of new listings", report.Detection)
+        writeLatencySummary(w, "entry_decision_latency_ms", "Detection to entry order sent, without scheduled waits", report.Decision)
+        writeLatencySummary(w, "entry_ack_latency_ms", "Entry order sent to acknowledged by Bitget", report.Ack)
+        writeLatencySummary(w, "entry_total_latency_ms", "Announcement to acknowledged entry order, without scheduled waits", report.Total)
+        writeLatencySummary(w, "entry_scheduled_wait_ms", "Scheduled wait for the entry time or the watchlist contract", report.Wait)
+        writeLatencySummary(w, "entry_slippage_percent", "Entry fill price vs last price before the announcement", report.Slippage)
+}
+
+func writeLatencySummary(w http.ResponseWriter, name string, help string, stats LatencyStats) {
+        fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s summary\n", name, help, name)
+        fmt.Fprintf(w, "%s{quantile=\"0.5\"} %g\n", name, stats.P50)
+        fmt.Fprintf(w, "%s{quantile=\"0.9\"} %g\n", name, stats.P90)
+        fmt.Fprintf(w, "%s{quantile=\"0.99\"} %g\n", name, stats.P99)
+        fmt.Fprintf(w, "%s_sum %g\n", name, stats.Sum)
+        fmt.Fprintf(w, "%s_count %d\n", name, stats.Count)
+}
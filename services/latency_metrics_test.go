@@ -0,0 +1,90 @@
+package services
+
+import (
+        "math"
+        "net/http"
+        "net/http/httptest"
+        "strings"
+        "testing"
+        "time"
+        "upbit-bitget-trading-bot/models"
+)
+
+func TestPercentile(t *testing.T) {
+        values := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+        stats := newLatencyStats(values)
+        if stats.Count != 10 || stats.P50 != 5 || stats.P90 != 9 || stats.P99 != 10 || stats.Max != 10 {
+                t.Errorf("unexpected stats %+v", stats)
+        }
+        if empty := newLatencyStats(nil); empty.Count != 0 || empty.P99 != 0 {
+                t.Errorf("empty stats = %+v", empty)
+        }
+}
+
+func TestSummarizeLatencies(t *testing.T) {
+        announced := time.Now()
+        events := []models.ListingEvent{
+                {Symbol: "AAA", AnnouncedAt: &announced, LatencyMs: 1200},
+                {Symbol: "BBB", AnnouncedAt: &announced, LatencyMs: 800},
+                {Symbol: "CCC", LatencyMs: 5}, // Market list hit without announcement time
+        }
+        trades := []models.TradeLatency{
+                {DecisionMs: 300, AckMs: 90, TotalMs: 1600, FillPrice: 1.1, ReferencePrice: 1.0, SlippagePct: 10},
+                {DecisionMs: 100, AckMs: 110, TotalMs: 1000}, // Fill not read yet
+                {DecisionMs: 200, AckMs: 100, TotalMs: 1300, WaitMs: 3600000}, // Entered at trading start an hour later
+        }
+        
+        report := summarizeLatencies(events, trades)
+        if report.Detection.Count != 2 || report.Detection.P50 != 800 || report.Detection.Max != 1200 {
+                t.Errorf("detection = %+v", report.Detection)
+        }
+        if report.Ack.Count != 3 || report.Total.Max != 1600 || report.Decision.P50 != 200 || report.Decision.Sum != 600 {
+                t.Errorf("entries: ack %+v, total %+v, decision %+v", report.Ack, report.Total, report.Decision)
+        }
+        if report.Wait.Count != 1 || report.Wait.Max != 3600000 {
+                t.Errorf("wait = %+v", report.Wait)
+        }
+        
+        rec := httptest.NewRecorder()
+        writeLatencySummary(rec, "entry_decision_latency_ms", "Detection to entry order sent", report.Decision)
+        for _, line := range []string{`entry_decision_latency_ms{quantile="0.5"} 200`, "entry_decision_latency_ms_sum 600", "entry_decision_latency_ms_count 3"} {
+                if !strings.Contains(rec.Body.String(), line) {
+                        t.Errorf("summary lacks %q:\n%s", line, rec.Body.String())
+                }
+        }
+        if report.Slippage.Count != 1 || report.Slippage.Max != 10 {
+                t.Errorf("slippage = %+v", report.Slippage)
+        }
+}
+
+func TestPriceBefore(t *testing.T) {
+        announced := time.Date(2025, 6, 26, 8, 0, 30, 0, time.UTC)
+        server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+                if r.URL.Path != "/api/v2/mix/market/candles" || r.URL.Query().Get("granularity") != "1m" {
+                        t.Errorf("unexpected request %s", r.URL.String())
+                }
+                // 07:58, 07:59 and the 08:00 candle that contains the announcement
+                w.Write([]byte(`{"code":"00000","msg":"success","data":[` +
+                        `["1750924680000","0.98","1","0.97","0.99","10","10"],` +
+                        `["1750924740000","0.99","1","0.98","1.00","10","10"],` +
+                        `["1750924800000","1.01","1.5","1.0","1.40","10","10"]]}`))
+        }))
+        defer server.Close()
+        
+        api := NewBitgetAPI("", "", "")
+        api.BaseURL = server.URL
+        
+        price, err := api.PriceBefore("NEWUSDT", announced)
+        if err != nil {
+                t.Fatalf("PriceBefore: %v", err)
+        }
+        // The announcement minute contributes its open, not the post-announcement close
+        if math.Abs(price-1.01) > 1e-9 {
+                t.Errorf("price = %.4f, want 1.01", price)
+        }
+        
+        price, err = api.PriceBefore("NEWUSDT", announced.Add(-30*time.Second))
+        if err != nil || math.Abs(price-1.00) > 1e-9 {
+                t.Errorf("price at minute boundary = %.4f (%v), want 1.00", price, err)
+        }
+}
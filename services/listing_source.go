@@ -0,0 +1,163 @@
+package services
+
+import (
+        "log"
+        "net/http"
+        "strconv"
+        "time"
+)
+
+// ListingSource is a single origin of listing detections (a scraper, an API, ...)
+// UpbitMonitor polls every registered source on its own schedule and merges the results
+type ListingSource interface {
+        Name() string            // Short identifier used in logs and on detections
+        Interval() time.Duration // Base polling interval (jitter is applied by the monitor)
+        Poll() ([]CoinListing, error)
+}
+
+// httpPollState keeps per-source conditional GET cache and backoff state
+type httpPollState struct {
+        name         string
+        lastETag     string    // For conditional GET requests
+        lastModified string    // For conditional GET requests
+        backoffUntil time.Time // Exponential backoff timestamp
+        failureCount int       // Consecutive failure count for backoff
+}
+
+// inBackoff reports whether the source should skip this poll
+func (s *httpPollState) inBackoff() bool {
+        if time.Now().Before(s.backoffUntil) {
+                log.Printf("⏳ [%s] In backoff period until %v, skipping check", s.name, s.backoffUntil.Format("15:04:05"))
+                return true
+        }
+        return false
+}
+
+// applyConditionalHeaders adds If-None-Match / If-Modified-Since if we have cached data
+func (s *httpPollState) applyConditionalHeaders(req *http.Request) {
+        if s.lastETag != "" {
+                req.Header.Set("If-None-Match", s.lastETag)
+        }
+        if s.lastModified != "" {
+                req.Header.Set("If-Modified-Since", s.lastModified)
+        }
+}
+
+// handleStatus handles rate limiting and HTTP errors, returns true when body should be parsed
+func (s *httpPollState) handleStatus(resp *http.Response) bool {
+        switch resp.StatusCode {
+        case 200:
+                // Success - reset failure count
+                s.failureCount = 0
+                
+                // Cache ETag and Last-Modified for next request
+                if etag := resp.Header.Get("ETag"); etag != "" {
+                        s.lastETag = etag
+                }
+                if lastModified := resp.Header.Get("Last-Modified"); lastModified != "" {
+                        s.lastModified = lastModified
+                }
+                return true
+        
+        case 304:
+                // Not Modified - page hasn't changed, this is good!
+                log.Printf("📄 [%s] Not modified since last check (304)", s.name)
+                s.failureCount = 0
+        
+        case 429:
+                // Too Many Requests - honor Retry-After if provided, otherwise exponential backoff
+                retryAfter := resp.Header.Get("Retry-After")
+                if retryAfter != "" {
+                        if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds > 0 {
+                                retryDuration := time.Duration(seconds) * time.Second
+                                s.backoffUntil = time.Now().Add(retryDuration)
+                                log.Printf("🚫 [%s] Rate limited (429) - honoring Retry-After: %v", s.name, retryDuration)
+                        } else {
+                                log.Printf("🚫 [%s] Rate limited (429) - invalid Retry-After, applying exponential backoff", s.name)
+                                s.applyBackoff()
+                        }
+                } else {
+                        log.Printf("🚫 [%s] Rate limited (429) - applying exponential backoff", s.name)
+                        s.applyBackoff()
+                }
+        
+        case 403:
+                // Forbidden - might be IP blocked
+                log.Printf("🚫 [%s] Access forbidden (403) - possible IP block, applying backoff", s.name)
+                s.applyBackoff()
+        
+        default:
+                log.Printf("❌ [%s] Returned status code: %d", s.name, resp.StatusCode)
+                s.handleError()
+        }
+        return false
+}
+
+// handleError handles general errors with light backoff
+func (s *httpPollState) handleError() {
+        s.failureCount++
+        if s.failureCount >= 3 {
+                // Apply light backoff after 3 consecutive failures
+                backoffDuration := time.Duration(s.failureCount) * 30 * time.Second
+                s.backoffUntil = time.Now().Add(backoffDuration)
+                log.Printf("⚠️ [%s] %d consecutive failures, backing off for %v", s.name, s.failureCount, backoffDuration)
+        }
+}
+
+// applyBackoff applies exponential backoff for rate limiting
+func (s *httpPollState) applyBackoff() {
+        s.failureCount++
+        
+        // Exponential backoff: 1, 2, 4, 8, 10 minutes max
+        backoffMinutes := 1 << uint(s.failureCount-1)
+        if backoffMinutes > 10 {
+                backoffMinutes = 10
+        }
+        
+        backoffDuration := time.Duration(backoffMinutes) * time.Minute
+        s.backoffUntil = time.Now().Add(backoffDuration)
+        
+        log.Printf("📉 [%s] Applying exponential backoff for %v (failure #%d)", s.name, backoffDuration, s.failureCount)
+}
+
+// noticeBaseline keeps a notice source from trading the notices already published when it first runs
+// Without stored events of the source (first deploy), the first poll only records its notices; they are never emitted
+type noticeBaseline struct {
+        source string
+        ready  bool
+        seen   map[string]bool // Baseline notice events ("announcement key|symbol")
+}
+
+// filter returns the listings that are not part of the baseline, establishing it on the first call
+func (b *noticeBaseline) filter(listings []CoinListing) []CoinListing {
+        if !b.ready {
+                b.ready = true
+                if sourceHasEvents(b.source) {
+                        return listings
+                }
+                
+                b.seen = make(map[string]bool)
+                for _, listing := range listings {
+                        b.seen[baselineKey(listing)] = true
+                        recordListingEvent(listing)
+                }
+                log.Printf("📸 [%s] Baseline of %d published notice events recorded, not traded", b.source, len(listings))
+                return nil
+        }
+        
+        if len(b.seen) == 0 {
+                return listings
+        }
+        var fresh []CoinListing
+        for _, listing := range listings {
+                if !b.seen[baselineKey(listing)] {
+                        fresh = append(fresh, listing)
+                }
+        }
+        return fresh
+}
+
+// baselineKey identifies a notice event within a source
+func baselineKey(listing CoinListing) string {
+        return announcementKey(listing) + "|" + string(listing.Kind) + "|" + listing.Symbol
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+        "crypto/sha1"
+        "encoding/hex"
+        "fmt"
+        "log"
+        "strings"
+        "upbit-bitget-trading-bot/database"
+        "upbit-bitget-trading-bot/models"
+
+        "gorm.io/gorm"
+        "gorm.io/gorm/clause"
+)
+
+// ensureProcessedCoinsLoaded seeds processedCoins from the listing_events table once the database is available
+func (um *UpbitMonitor) ensureProcessedCoinsLoaded() {
+        um.coinMutex.RLock()
+        loaded := um.processedLoaded
+        um.coinMutex.RUnlock()
+        if loaded {
+                return
+        }
+        
+        if !database.IsConnected() {
+                um.coinMutex.Lock()
+                logged := um.loadDeferLogged
+                um.loadDeferLogged = true
+                um.coinMutex.Unlock()
+                if !logged {
+                        log.Printf("⚠️ Database not connected, processed coins not loaded yet (restart dedupe limited to memory)")
+                }
+                return
+        }
+        
+        var rows []processedEventRow
+        err := database.WithDB(func(db *gorm.DB) error {
+                return db.Model(&models.ListingEvent{}).Distinct("exchange", "symbol", "kind", "markets").Find(&rows).Error
+        })
+        if err != nil {
+                log.Printf("❌ Failed to load processed coins: %v", err)
+                return
+        }
+        
+        um.coinMutex.Lock()
+        defer um.coinMutex.Unlock()
+        
+        um.seedProcessedCoins(rows)
+        um.processedLoaded = true
+        
+        log.Printf("💾 Loaded %d processed events from listing_events", len(rows))
+}
+
+// processedEventRow is the part of a stored listing event that makes up its dedupe keys
+type processedEventRow struct {
+        Exchange string
+        Symbol   string
+        Kind     string
+        Markets  string
+}
+
+// seedProcessedCoins marks the dedupe keys of stored events as processed (caller holds coinMutex)
+func (um *UpbitMonitor) seedProcessedCoins(rows []processedEventRow) {
+        for _, row := range rows {
+                var markets []string
+                if row.Markets != "" {
+                        markets = strings.Split(row.Markets, ",")
+                }
+                for _, key := range processedKeys(row.Exchange, ListingKind(row.Kind), row.Symbol, markets) {
+                        um.processedCoins[key] = true
+                }
+        }
+}
+
+// recordListingEvent writes the detection to listing_events (idempotent per announcement and symbol)
+func recordListingEvent(listing CoinListing) {
+        event := newListingEvent(listing)
+        err := database.WithDB(func(db *gorm.DB) error {
+                return db.Clauses(clause.OnConflict{DoNothing: true}).Create(event).Error
+        })
+        if err != nil {
+                if err.Error() == "database not available" {
+                        log.Printf("⚠️ Database unavailable, listing event for %s not persisted", listing.Symbol)
+                } else {
+                        log.Printf("❌ Failed to persist listing event for %s: %v", listing.Symbol, err)
+                }
+        }
+}
+
+// newListingEvent builds the listing_events row of a detection
+func newListingEvent(listing CoinListing) *models.ListingEvent {
+        event := &models.ListingEvent{
+                AnnouncementID:    announcementKey(listing),
+                Symbol:            listing.Symbol,
+                Exchange:          listing.Exchange,
+                Kind:              string(listing.Kind),
+                AnnouncementTitle: listing.AnnouncementTitle,
+                AnnouncementURL:   listing.AnnouncementURL,
+                Source:            listing.Source,
+                MarketType:        listing.MarketType,
+                Markets:           strings.Join(listing.Markets, ","),
+                DetectedAt:        listing.DetectedAt,
+                LatencyMs:         listing.Latency.Milliseconds(),
+                FromMarketList:    listing.FromMarketList,
+                AnnouncementSeen:  listing.AnnouncementSeen,
+                AddedMarket:       listing.AddedMarket,
+                Confidence:        listing.Confidence,
+        }
+        if !listing.AnnouncedAt.IsZero() {
+                announcedAt := listing.AnnouncedAt
+                event.AnnouncedAt = &announcedAt
+        }
+        if !listing.TradingStartsAt.IsZero() {
+                tradingStartsAt := listing.TradingStartsAt
+                event.TradingStartsAt = &tradingStartsAt
+        }
+        return event
+}
+
+// sourceHasEvents reports whether listing_events holds events of a source (false when the database is unavailable)
+func sourceHasEvents(source string) bool {
+        var count int64
+        err := database.WithDB(func(db *gorm.DB) error {
+                return db.Model(&models.ListingEvent{}).Where("source = ?", source).Count(&count).Error
+        })
+        if err != nil {
+                if err.Error() != "database not available" {
+                        log.Printf("⚠️ Failed to look up stored events of %s: %v", source, err)
+                }
+                return false
+        }
+        return count > 0
+}
+
+// recordConfirmation links a duplicate detection to the already emitted one
+// A market list hit after the announcement is stored with AnnouncementSeen, and the first
+// announcement after a market list hit flags the earlier market list events
+func (um *UpbitMonitor) recordConfirmation(listing CoinListing, firstAnnouncement bool) {
+        if listing.FromMarketList {
+                log.Printf("🔁 Market list confirms already processed coin %s (%s, announcement seen: %v)",
+                        listing.Symbol, listing.MarketType, listing.AnnouncementSeen)
+                recordListingEvent(listing)
+                return
+        }
+        
+        if !firstAnnouncement {
+                return
+        }
+        
+        err := database.WithDB(func(db *gorm.DB) error {
+                return db.Model(&models.ListingEvent{}).
+                        Where("symbol = ? AND from_market_list = ? AND announcement_seen = ?", listing.Symbol, true, false).
+                        Update("announcement_seen", true).Error
+        })
+        if err != nil && err.Error() != "database not available" {
+                log.Printf("❌ Failed to flag market list events for %s: %v", listing.Symbol, err)
+        }
+}
+
+// recordDetectionDecision writes a quorum decision to the audit table
+func (um *UpbitMonitor) recordDetectionDecision(decision *models.DetectionDecision) {
+        err := database.WithDB(func(db *gorm.DB) error {
+                return db.Create(decision).Error
+        })
+        if err != nil && err.Error() != "database not available" {
+                log.Printf("❌ Failed to record detection decision for %s: %v", decision.Symbol, err)
+        }
+}
+
+// GetDetectionDecisions returns the latest quorum decisions, optionally for one symbol
+func (um *UpbitMonitor) GetDetectionDecisions(symbol string, limit int) ([]models.DetectionDecision, error) {
+        var decisions []models.DetectionDecision
+        err := database.WithDB(func(db *gorm.DB) error {
+                query := db.Order("decided_at DESC").Limit(limit)
+                if symbol != "" {
+                        query = query.Where("symbol = ?", symbol)
+                }
+                return query.Find(&decisions).Error
+        })
+        return decisions, err
+}
+
+// isAddedMarket reports whether an announced coin already trades on Upbit in a market the notice doesn't open
+// Relies on the upbit_markets snapshot kept by the market list source; unknown coins count as new listings
+func (um *UpbitMonitor) isAddedMarket(listing CoinListing) bool {
+        var count int64
+        err := database.WithDB(func(db *gorm.DB) error {
+                query := db.Model(&models.UpbitMarket{}).Where("base_currency = ?", listing.Symbol)
+                if len(listing.Markets) > 0 {
+                        query = query.Where("quote_currency NOT IN ?", listing.Markets)
+                }
+                return query.Count(&count).Error
+        })
+        if err != nil {
+                if err.Error() != "database not available" {
+                        log.Printf("⚠️ Failed to look up existing markets for %s: %v", listing.Symbol, err)
+                }
+                return false
+        }
+        return count > 0
+}
+
+// announcementKey returns the notice ID, or a stable hash of the title for sources without IDs
+// Notices of exchanges other than Upbit are prefixed so their IDs can't collide with Upbit's
+func announcementKey(listing CoinListing) string {
+        key := listing.AnnouncementID
+        if key == "" {
+                sum := sha1.Sum([]byte(listing.AnnouncementTitle))
+                key = "title-" + hex.EncodeToString(sum[:8])
+        }
+        prefix := listing.Exchange + "-"
+        if listing.Exchange != "" && listing.Exchange != models.ExchangeUpbit && !strings.HasPrefix(key, prefix) {
+                key = prefix + key
+        }
+        return key
+}
+
+// GetProcessedCoins returns the persisted listing events, newest first (falls back to memory without a database)
+func (um *UpbitMonitor) GetProcessedCoins() ([]models.ListingEvent, error) {
+        var events []models.ListingEvent
+        err := database.WithDB(func(db *gorm.DB) error {
+                return db.Order("detected_at DESC").Find(&events).Error
+        })
+        if err == nil {
+                return events, nil
+        }
+        
+        if err.Error() != "database not available" {
+                return nil, fmt.Errorf("failed to load listing events: %w", err)
+        }
+        
+        um.coinMutex.RLock()
+        defer um.coinMutex.RUnlock()
+        
+        for key := range um.processedCoins {
+                events = append(events, models.ListingEvent{Symbol: key})
+        }
+        return events, nil
+}
+
+// ClearProcessedCoins forgets a processed coin (or all coins when symbol is empty) so it can be detected again
+// Memory is cleared even without a database; cleared counts the in-memory keys, deleted the listing events, and
+// err only reports the database part
+func (um *UpbitMonitor) ClearProcessedCoins(symbol string) (cleared int, deleted int64, err error) {
+        um.coinMutex.Lock()
+        if symbol == "" {
+                cleared = len(um.processedCoins)
+                um.processedCoins = make(map[string]bool)
+        } else {
+                for key := range um.processedCoins {
+                        if processedKeySymbol(key) == symbol {
+                                delete(um.processedCoins, key)
+                                cleared++
+                        }
+                }
+        }
+        um.coinMutex.Unlock()
+        
+        dbErr := database.WithDB(func(db *gorm.DB) error {
+                query := db.Session(&gorm.Session{AllowGlobalUpdate: true})
+                if symbol != "" {
+                        query = query.Where("symbol = ?", symbol)
+                }
+                result := query.Delete(&models.ListingEvent{})
+                deleted = result.RowsAffected
+                return result.Error
+        })
+        if dbErr != nil {
+                log.Printf("⚠️ Cleared %d processed coins in memory only (symbol=%q): %v", cleared, symbol, dbErr)
+                return cleared, 0, fmt.Errorf("failed to clear listing events: %w", dbErr)
+        }
+        
+        log.Printf("🧹 Cleared processed coins (symbol=%q, %d in memory, %d listing events deleted)", symbol, cleared, deleted)
+        return cleared, deleted, nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+        "testing"
+        "upbit-bitget-trading-bot/models"
+)
+
+// storedRow reads back the dedupe columns of the listing_events row a detection is stored as
+func storedRow(listing CoinListing) processedEventRow {
+        event := newListingEvent(listing)
+        return processedEventRow{Exchange: event.Exchange, Symbol: event.Symbol, Kind: event.Kind, Markets: event.Markets}
+}
+
+func TestProcessedCoinsRoundTripThroughListingEvents(t *testing.T) {
+        stored := []CoinListing{
+                {Symbol: "TOSHI", Kind: ListingKindNew, Exchange: models.ExchangeUpbit, Markets: []string{MarketKRW, MarketBTC}},
+                {Symbol: "PENGU", Kind: ListingKindNew, Exchange: models.ExchangeBithumb, Markets: []string{MarketKRW}},
+                {Symbol: "OPEN", Kind: ListingKindNew, Source: "upbit_notice_html"},
+                {Symbol: "BORA", Kind: ListingKindDelisting, Exchange: models.ExchangeUpbit},
+        }
+        rows := make([]processedEventRow, 0, len(stored))
+        for _, listing := range stored {
+                rows = append(rows, storedRow(listing))
+        }
+        
+        // A restart seeds a fresh monitor from the stored rows
+        um := &UpbitMonitor{processedCoins: make(map[string]bool)}
+        um.seedProcessedCoins(rows)
+        
+        for _, listing := range stored {
+                keys := processedKeys(listing.Exchange, listing.Kind, listing.Symbol, listing.Markets)
+                if !um.isProcessed(listing.Exchange, listing.Kind, listing.Symbol, keys) {
+                        t.Errorf("%s %s (%s) not processed after restart, keys %v", listing.Kind, listing.Symbol, listing.Exchange, keys)
+                }
+        }
+        
+        cases := []struct {
+                listing   CoinListing
+                processed bool
+        }{
+                {CoinListing{Symbol: "TOSHI", Kind: ListingKindNew, Exchange: models.ExchangeUpbit, Markets: []string{MarketUSDT}}, false},
+                {CoinListing{Symbol: "PENGU", Kind: ListingKindNew, Exchange: models.ExchangeUpbit, Markets: []string{MarketKRW}}, false},
+                {CoinListing{Symbol: "OPEN", Kind: ListingKindNew, Exchange: models.ExchangeUpbit, Markets: []string{MarketUSDT}}, true},
+                {CoinListing{Symbol: "BORA", Kind: ListingKindWarning, Exchange: models.ExchangeUpbit}, false},
+        }
+        for _, c := range cases {
+                keys := processedKeys(c.listing.Exchange, c.listing.Kind, c.listing.Symbol, c.listing.Markets)
+                if got := um.isProcessed(c.listing.Exchange, c.listing.Kind, c.listing.Symbol, keys); got != c.processed {
+                        t.Errorf("%s %s %v (%s): processed = %v, want %v", c.listing.Kind, c.listing.Symbol, c.listing.Markets, c.listing.Exchange, got, c.processed)
+                }
+        }
+}
+
+func TestEnsureProcessedCoinsLoadedWaitsForDatabase(t *testing.T) {
+        um := &UpbitMonitor{processedCoins: map[string]bool{"TOSHI@KRW": true}}
+        
+        // Without a database nothing is loaded yet, memory stays as it is and the next call tries again
+        um.ensureProcessedCoinsLoaded()
+        um.ensureProcessedCoinsLoaded()
+        if um.processedLoaded || !um.loadDeferLogged {
+                t.Errorf("loaded = %v, defer logged = %v; want a deferred load logged once", um.processedLoaded, um.loadDeferLogged)
+        }
+        if len(um.processedCoins) != 1 || !um.processedCoins["TOSHI@KRW"] {
+                t.Errorf("processed coins changed without a database: %v", um.processedCoins)
+        }
+}
+
+func TestClearProcessedCoinsWithoutDatabase(t *testing.T) {
+        um := &UpbitMonitor{processedCoins: map[string]bool{"PENGU@KRW": true, "bithumb|PENGU@KRW": true, "TOSHI@KRW": true}}
+        
+        cleared, deleted, err := um.ClearProcessedCoins("PENGU")
+        if err == nil || cleared != 2 || deleted != 0 {
+                t.Errorf("ClearProcessedCoins = %d, %d, %v; want 2 cleared and the database error", cleared, deleted, err)
+        }
+        if len(um.processedCoins) != 1 || !um.processedCoins["TOSHI@KRW"] {
+                t.Errorf("processed coins after clear: %v", um.processedCoins)
+        }
+}
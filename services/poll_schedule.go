@@ -0,0 +1,324 @@
+package services
+
+import (
+        "fmt"
+        "log"
+        "sort"
+        "strings"
+        "sync"
+        "time"
+)
+
+// ScheduleMode is the polling regime currently in effect
+type ScheduleMode string
+
+const (
+        ScheduleHot       ScheduleMode = "hot"       // Inside a configured listing window: poll faster
+        ScheduleNormal    ScheduleMode = "normal"    // Base interval
+        ScheduleOvernight ScheduleMode = "overnight" // Quiet hours: back off
+        ScheduleWeekend   ScheduleMode = "weekend"   // Saturday/Sunday: back off
+)
+
+// PollScheduleConfig configures the KST-aware polling schedule
+type PollScheduleConfig struct {
+        HotWindows      string        // Weekday KST windows, e.g. "09:00-12:00,14:00-18:00"
+        HotFactor       float64       // Interval multiplier inside hot windows (e.g. 0.33)
+        Overnight       string        // KST window for overnight back-off, e.g. "01:00-07:00"
+        OvernightFactor float64       // Interval multiplier overnight (e.g. 3)
+        WeekendFactor   float64       // Interval multiplier on weekends (e.g. 2)
+        HourlyBudget    int           // Max HTTP requests per source per rolling hour (0 = unlimited)
+        MinInterval     time.Duration // Floor for any computed interval
+}
+
+// PollSchedule adjusts each source's polling interval to the time of day in Korea and enforces a per-source request budget
+type PollSchedule struct {
+        config     PollScheduleConfig
+        location   *time.Location
+        hotWindows []clockWindow
+        overnight  *clockWindow
+        
+        mutex   sync.Mutex
+        sources map[string]*sourceSchedule
+}
+
+// clockWindow is a daily time range in minutes since midnight; end < start wraps past midnight
+type clockWindow struct {
+        label string
+        start int
+        end   int
+}
+
+// sourceSchedule is the per-source state reported on the health endpoint
+type sourceSchedule struct {
+        baseInterval    time.Duration
+        currentInterval time.Duration
+        mode            ScheduleMode
+        requests        []time.Time // HTTP request times within the last hour, proxy retries included
+        budgetWaits     int64       // Polls delayed because the budget was spent
+}
+
+// ScheduleStatus is the schedule snapshot served on /health
+type ScheduleStatus struct {
+        Timezone     string                 `json:"timezone"`
+        LocalTime    string                 `json:"local_time"`
+        Mode         ScheduleMode           `json:"mode"`
+        Window       string                 `json:"window,omitempty"`
+        HotWindows   []string               `json:"hot_windows"`
+        Overnight    string                 `json:"overnight,omitempty"`
+        HourlyBudget int                    `json:"hourly_budget"`
+        Sources      []SourceScheduleStatus `json:"sources"`
+}
+
+// SourceScheduleStatus describes one source's current schedule
+type SourceScheduleStatus struct {
+        Source           string       `json:"source"`
+        Mode             ScheduleMode `json:"mode"`
+        BaseIntervalS    float64      `json:"base_interval_s"`
+        CurrentIntervalS float64      `json:"current_interval_s"`
+        RequestsLastHour int          `json:"requests_last_hour"`
+        BudgetWaits      int64        `json:"budget_waits"`
+}
+
+// NewPollSchedule creates a schedule; invalid windows are logged and skipped
+func NewPollSchedule(config PollScheduleConfig) *PollSchedule {
+        if config.MinInterval <= 0 {
+                config.MinInterval = 5 * time.Second
+        }
+        for _, factor := range []*float64{&config.HotFactor, &config.OvernightFactor, &config.WeekendFactor} {
+                if *factor <= 0 {
+                        *factor = 1
+                }
+        }
+        
+        schedule := &PollSchedule{
+                config:   config,
+                location: koreaLocation(),
+                sources:  make(map[string]*sourceSchedule),
+        }
+        
+        for _, spec := range strings.Split(config.HotWindows, ",") {
+                if strings.TrimSpace(spec) == "" {
+                        continue
+                }
+                window, err := parseClockWindow(spec)
+                if err != nil {
+                        log.Printf("⚠️ Ignoring invalid hot window %q: %v", spec, err)
+                        continue
+                }
+                schedule.hotWindows = append(schedule.hotWindows, window)
+        }
+        
+        if strings.TrimSpace(config.Overnight) != "" {
+                window, err := parseClockWindow(config.Overnight)
+                if err != nil {
+                        log.Printf("⚠️ Ignoring invalid overnight window %q: %v", config.Overnight, err)
+                } else {
+                        schedule.overnight = &window
+                }
+        }
+        
+        log.Printf("🗓️ Poll schedule (KST): hot %s x%.2f, overnight %s x%.2f, weekend x%.2f, budget %d req/h per source",
+                schedule.hotWindowLabels(), config.HotFactor, schedule.overnightLabel(), config.OvernightFactor,
+                config.WeekendFactor, config.HourlyBudget)
+        
+        return schedule
+}
+
+// parseClockWindow parses "HH:MM-HH:MM"
+func parseClockWindow(spec string) (clockWindow, error) {
+        spec = strings.TrimSpace(spec)
+        parts := strings.Split(spec, "-")
+        if len(parts) != 2 {
+                return clockWindow{}, fmt.Errorf("expected HH:MM-HH:MM")
+        }
+        
+        var bounds [2]int
+        for i, part := range parts {
+                t, err := time.Parse("15:04", strings.TrimSpace(part))
+                if err != nil {
+                        return clockWindow{}, fmt.Errorf("invalid time %q", part)
+                }
+                bounds[i] = t.Hour()*60 + t.Minute()
+        }
+        if bounds[0] == bounds[1] {
+                return clockWindow{}, fmt.Errorf("empty window")
+        }
+        
+        return clockWindow{label: spec, start: bounds[0], end: bounds[1]}, nil
+}
+
+// contains reports whether a minute of the day falls inside the window
+func (w clockWindow) contains(minute int) bool {
+        if w.start < w.end {
+                return minute >= w.start && minute < w.end
+        }
+        return minute >= w.start || minute < w.end
+}
+
+// Mode returns the regime in effect at the given time and the window that caused it
+// Priority: weekday hot window > overnight > weekend > normal
+func (s *PollSchedule) Mode(now time.Time) (ScheduleMode, string) {
+        local := now.In(s.location)
+        minute := local.Hour()*60 + local.Minute()
+        weekend := local.Weekday() == time.Saturday || local.Weekday() == time.Sunday
+        
+        if !weekend {
+                for _, window := range s.hotWindows {
+                        if window.contains(minute) {
+                                return ScheduleHot, window.label
+                        }
+                }
+        }
+        if s.overnight != nil && s.overnight.contains(minute) {
+                return ScheduleOvernight, s.overnight.label
+        }
+        if weekend {
+                return ScheduleWeekend, ""
+        }
+        return ScheduleNormal, ""
+}
+
+// Interval returns the scheduled polling interval for a source, logging when its mode changes
+func (s *PollSchedule) Interval(source string, base time.Duration, now time.Time) time.Duration {
+        mode, window := s.Mode(now)
+        
+        factor := 1.0
+        switch mode {
+        case ScheduleHot:
+                factor = s.config.HotFactor
+        case ScheduleOvernight:
+                factor = s.config.OvernightFactor
+        case ScheduleWeekend:
+                factor = s.config.WeekendFactor
+        }
+        
+        interval := time.Duration(float64(base) * factor)
+        if interval < s.config.MinInterval {
+                interval = s.config.MinInterval
+        }
+        
+        s.mutex.Lock()
+        state := s.source(source)
+        changed := state.mode != mode
+        state.baseInterval = base
+        state.currentInterval = interval
+        state.mode = mode
+        s.mutex.Unlock()
+        
+        if changed {
+                if window != "" {
+                        log.Printf("🗓️ [%s] Schedule: %s (%s KST) - polling every %v", source, mode, window, interval)
+                } else {
+                        log.Printf("🗓️ [%s] Schedule: %s - polling every %v", source, mode, interval)
+                }
+                if polls := int(time.Hour / interval); s.config.HourlyBudget > 0 && polls > s.config.HourlyBudget {
+                        log.Printf("⚠️ [%s] Polling every %v takes %d requests/h, above the budget of %d; polls will be skipped",
+                                source, interval, polls, s.config.HourlyBudget)
+                }
+        }
+        return interval
+}
+
+// Reserve checks the source's hourly budget before a poll
+// Returns 0 when the poll may go out, otherwise how long to wait for budget. The poll's requests are counted
+// by Record as they are sent.
+func (s *PollSchedule) Reserve(source string, now time.Time) time.Duration {
+        s.mutex.Lock()
+        defer s.mutex.Unlock()
+        
+        state := s.source(source)
+        state.prune(now)
+        
+        if s.config.HourlyBudget > 0 && len(state.requests) >= s.config.HourlyBudget {
+                state.budgetWaits++
+                return state.requests[0].Add(time.Hour).Sub(now)
+        }
+        return 0
+}
+
+// Record counts one HTTP request of a source against its hourly budget
+func (s *PollSchedule) Record(source string, now time.Time) {
+        s.mutex.Lock()
+        defer s.mutex.Unlock()
+        
+        state := s.source(source)
+        state.prune(now)
+        state.requests = append(state.requests, now)
+}
+
+// Status returns the schedule snapshot for the health endpoint
+func (s *PollSchedule) Status(now time.Time) ScheduleStatus {
+        mode, window := s.Mode(now)
+        status := ScheduleStatus{
+                Timezone:     s.location.String(),
+                LocalTime:    now.In(s.location).Format("2006-01-02 15:04:05 Mon"),
+                Mode:         mode,
+                Window:       window,
+                HotWindows:   []string{},
+                HourlyBudget: s.config.HourlyBudget,
+                Sources:      []SourceScheduleStatus{},
+        }
+        for _, hot := range s.hotWindows {
+                status.HotWindows = append(status.HotWindows, hot.label)
+        }
+        if s.overnight != nil {
+                status.Overnight = s.overnight.label
+        }
+        
+        s.mutex.Lock()
+        defer s.mutex.Unlock()
+        
+        for name, state := range s.sources {
+                state.prune(now)
+                status.Sources = append(status.Sources, SourceScheduleStatus{
+                        Source:           name,
+                        Mode:             state.mode,
+                        BaseIntervalS:    state.baseInterval.Seconds(),
+                        CurrentIntervalS: state.currentInterval.Seconds(),
+                        RequestsLastHour: len(state.requests),
+                        BudgetWaits:      state.budgetWaits,
+                })
+        }
+        sort.Slice(status.Sources, func(i, j int) bool {
+                return status.Sources[i].Source < status.Sources[j].Source
+        })
+        return status
+}
+
+// source returns the state of a source, creating it on first use (caller holds mutex)
+func (s *PollSchedule) source(name string) *sourceSchedule {
+        state, ok := s.sources[name]
+        if !ok {
+                state = &sourceSchedule{}
+                s.sources[name] = state
+        }
+        return state
+}
+
+// prune drops request times older than one hour
+func (st *sourceSchedule) prune(now time.Time) {
+        cutoff := now.Add(-time.Hour)
+        i := 0
+        for i < len(st.requests) && !st.requests[i].After(cutoff) {
+                i++
+        }
+        st.requests = st.requests[i:]
+}
+
+func (s *PollSchedule) hotWindowLabels() string {
+        if len(s.hotWindows) == 0 {
+                return "none"
+        }
+        labels := make([]string, 0, len(s.hotWindows))
+        for _, window := range s.hotWindows {
+                labels = append(labels, window.label)
+        }
+        return strings.Join(labels, ",")
+}
+
+func (s *PollSchedule) overnightLabel() string {
+        if s.overnight == nil {
+                return "none"
+        }
+        return s.overnight.label
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+        "net/http"
+        "testing"
+        "time"
+)
+
+func newTestSchedule(budget int) *PollSchedule {
+        return NewPollSchedule(PollScheduleConfig{
+                HotWindows:      "09:00-12:00,14:00-18:00",
+                HotFactor:       0.5,
+                Overnight:       "23:00-07:00",
+                OvernightFactor: 3,
+                WeekendFactor:   2,
+                HourlyBudget:    budget,
+                MinInterval:     time.Second,
+        })
+}
+
+// kst builds a time in Korea; 2025-06-02 is a Monday
+func kst(day, hour, minute int) time.Time {
+        return time.Date(2025, 6, day, hour, minute, 0, 0, time.FixedZone("KST", 9*60*60))
+}
+
+func TestPollScheduleModes(t *testing.T) {
+        schedule := newTestSchedule(0)
+        
+        cases := []struct {
+                name     string
+                at       time.Time
+                mode     ScheduleMode
+                interval time.Duration
+        }{
+                {"weekday hot window", kst(2, 10, 30), ScheduleHot, 10 * time.Second},
+                {"weekday between windows", kst(2, 13, 0), ScheduleNormal, 20 * time.Second},
+                {"window end is exclusive", kst(2, 12, 0), ScheduleNormal, 20 * time.Second},
+                {"overnight before midnight", kst(2, 23, 30), ScheduleOvernight, 60 * time.Second},
+                {"overnight after midnight", kst(3, 3, 0), ScheduleOvernight, 60 * time.Second},
+                {"saturday daytime", kst(7, 10, 30), ScheduleWeekend, 40 * time.Second},
+                {"sunday overnight", kst(8, 2, 0), ScheduleOvernight, 60 * time.Second},
+                {"utc time converted to kst", time.Date(2025, 6, 2, 1, 30, 0, 0, time.UTC), ScheduleHot, 10 * time.Second},
+        }
+        
+        for _, tc := range cases {
+                t.Run(tc.name, func(t *testing.T) {
+                        if mode, _ := schedule.Mode(tc.at); mode != tc.mode {
+                                t.Errorf("mode: got %s, want %s", mode, tc.mode)
+                        }
+                        if interval := schedule.Interval("test", 20*time.Second, tc.at); interval != tc.interval {
+                                t.Errorf("interval: got %v, want %v", interval, tc.interval)
+                        }
+                })
+        }
+}
+
+func TestPollScheduleMinInterval(t *testing.T) {
+        schedule := newTestSchedule(0)
+        if interval := schedule.Interval("test", time.Second, kst(2, 10, 0)); interval != time.Second {
+                t.Errorf("hot interval below the floor: got %v, want %v", interval, time.Second)
+        }
+}
+
+func TestPollScheduleBudget(t *testing.T) {
+        schedule := newTestSchedule(3)
+        start := kst(2, 10, 0)
+        
+        for i := 0; i < 3; i++ {
+                if wait := schedule.Reserve("api", start.Add(time.Duration(i)*time.Minute)); wait != 0 {
+                        t.Fatalf("request %d should fit the budget, got wait %v", i+1, wait)
+                }
+                schedule.Record("api", start.Add(time.Duration(i)*time.Minute))
+        }
+        
+        if wait := schedule.Reserve("api", start.Add(10*time.Minute)); wait != 50*time.Minute {
+                t.Errorf("budget spent: got wait %v, want 50m", wait)
+        }
+        if wait := schedule.Reserve("html", start.Add(10*time.Minute)); wait != 0 {
+                t.Errorf("budget is per source, other source got wait %v", wait)
+        }
+        if wait := schedule.Reserve("api", start.Add(time.Hour+time.Second)); wait != 0 {
+                t.Errorf("budget should roll over after an hour, got wait %v", wait)
+        }
+        
+        status := schedule.Status(start.Add(time.Hour + time.Second))
+        if len(status.Sources) != 2 || status.Sources[0].Source != "api" || status.Sources[0].BudgetWaits != 1 {
+                t.Errorf("unexpected status: %+v", status.Sources)
+        }
+}
+
+func TestBudgetCountsProxyRetries(t *testing.T) {
+        forbidden := int32(http.StatusForbidden)
+        var hitsA, hitsB int32
+        pool := NewProxyPool([]string{newFakeProxy(t, &forbidden, &hitsA), newFakeProxy(t, &forbidden, &hitsB)})
+        um := &UpbitMonitor{schedule: newTestSchedule(0)}
+        
+        resp, err := um.sourceClient("upbit_notice_api", pool).Get("http://upbit.invalid/api/v1/announcements")
+        if err != nil {
+                t.Fatalf("request failed: %v", err)
+        }
+        resp.Body.Close()
+        
+        status := um.schedule.Status(time.Now())
+        if len(status.Sources) != 1 || status.Sources[0].RequestsLastHour != 2 {
+                t.Errorf("expected the retried request counted twice, got %+v", status.Sources)
+        }
+}
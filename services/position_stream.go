@@ -0,0 +1,250 @@
+package services
+
+import (
+        "encoding/json"
+        "log"
+        "strconv"
+        "time"
+        "upbit-bitget-trading-bot/database"
+        "upbit-bitget-trading-bot/models"
+
+        "gorm.io/gorm"
+)
+
+// streamPositionInterval limits how often pushes of an unchanged position size are applied; the positions
+// channel pushes on every mark price move
+var streamPositionInterval = 5 * time.Second
+
+// positionStream is the private stream of a user with open positions
+type positionStream struct {
+        stream   *BitgetStream
+        exchange Exchange
+        applied  map[uint]time.Time // Last stream update per position, only used on the stream's read goroutine
+        checked  map[string]streamPush // Last push per instrument that loaded the open positions, read goroutine only
+}
+
+// streamPush is the size an instrument was pushed with and when its open positions were last loaded for it
+type streamPush struct {
+        total string
+        at    time.Time
+}
+
+// SetStreamURL enables the private streams with a WebSocket URL ("" disables them); must be called before Start
+func (te *TradingEngine) SetStreamURL(url string) {
+        te.streamURL = url
+}
+
+// ensureStream starts the private stream of a user unless it is already running
+func (te *TradingEngine) ensureStream(user models.User) {
+        if te.streamURL == "" {
+                return
+        }
+        
+        te.streamsMutex.Lock()
+        defer te.streamsMutex.Unlock()
+        if _, ok := te.streams[user.TelegramID]; ok {
+                return
+        }
+        
+        apiKey, apiSecret, passphrase, err := user.GetAPICredentials(te.encryptionKey)
+        if err != nil {
+                log.Printf("❌ Failed to get API credentials for the stream of user %d: %v", user.TelegramID, err)
+                return
+        }
+        
+        ps := &positionStream{
+                exchange: te.newExchange(apiKey, apiSecret, passphrase),
+                applied:  make(map[uint]time.Time),
+                checked:  make(map[string]streamPush),
+        }
+        userID, telegramID := user.ID, user.TelegramID
+        ps.stream = NewBitgetStream(apiKey, apiSecret, passphrase,
+                []string{StreamChannelOrders, StreamChannelPositions, StreamChannelFill},
+                func(msg StreamMessage) { te.handleStreamMessage(ps, userID, telegramID, msg) })
+        ps.stream.URL = te.streamURL
+        te.streams[telegramID] = ps
+        
+        log.Printf("🔌 Starting Bitget stream for user %d", telegramID)
+        safeGoTE("BitgetStream", ps.stream.Run)
+}
+
+// syncStreams runs a stream for every user with an open position and stops the others
+func (te *TradingEngine) syncStreams(positions []models.Position) {
+        if te.streamURL == "" {
+                return
+        }
+        
+        users := make(map[int64]models.User)
+        for _, position := range positions {
+                users[position.User.TelegramID] = position.User
+        }
+        
+        te.streamsMutex.Lock()
+        for telegramID, ps := range te.streams {
+                if _, ok := users[telegramID]; !ok {
+                        log.Printf("🔌 Stopping Bitget stream for user %d, no open positions", telegramID)
+                        ps.stream.Stop()
+                        delete(te.streams, telegramID)
+                }
+        }
+        te.streamsMutex.Unlock()
+        
+        for _, user := range users {
+                te.ensureStream(user)
+        }
+}
+
+// streamConnected reports whether a user's positions are kept current by a live stream
+func (te *TradingEngine) streamConnected(telegramID int64) bool {
+        te.streamsMutex.Lock()
+        ps, ok := te.streams[telegramID]
+        te.streamsMutex.Unlock()
+        return ok && ps.stream.Connected()
+}
+
+func (te *TradingEngine) stopStreams() {
+        te.streamsMutex.Lock()
+        defer te.streamsMutex.Unlock()
+        for telegramID, ps := range te.streams {
+                ps.stream.Stop()
+                delete(te.streams, telegramID)
+        }
+}
+
+// handleStreamMessage applies a push of a user's private stream to the user's open positions
+func (te *TradingEngine) handleStreamMessage(ps *positionStream, userID uint, telegramID int64, msg StreamMessage) {
+        switch msg.Arg.Channel {
+        case StreamChannelPositions:
+                var entries []StreamPosition
+                if err := json.Unmarshal(msg.Data, &entries); err != nil {
+                        log.Printf("⚠️ Unreadable positions push for user %d: %v", telegramID, err)
+                        return
+                }
+                // Mark price pushes arrive many times a second: only load the positions when a push could change one
+                snapshot := msg.Action == "snapshot"
+                if !ps.positionsDue(entries, snapshot) {
+                        return
+                }
+                positions, ok := te.openPositionsOf(userID)
+                if !ok {
+                        return
+                }
+                te.applyStreamPositions(ps, positions, entries, snapshot)
+        case StreamChannelOrders:
+                var orders []StreamOrder
+                if err := json.Unmarshal(msg.Data, &orders); err != nil {
+                        log.Printf("⚠️ Unreadable orders push for user %d: %v", telegramID, err)
+                        return
+                }
+                positions, ok := te.openPositionsOf(userID)
+                if !ok {
+                        return
+                }
+                te.applyStreamOrders(positions, orders)
+        case StreamChannelFill:
+                var fills []StreamFill
+                if err := json.Unmarshal(msg.Data, &fills); err != nil {
+                        log.Printf("⚠️ Unreadable fill push for user %d: %v", telegramID, err)
+                        return
+                }
+                // Position changes follow on the positions channel; fills are logged for the trade history
+                for _, fill := range fills {
+                        log.Printf("⚡ Fill for user %d: %s %s/%s %s @ %s (P&L %s)",
+                                telegramID, fill.Symbol, fill.Side, fill.TradeSide, fill.BaseVolume, fill.Price, fill.Profit)
+                }
+        }
+}
+
+// positionsDue reports whether a positions push needs the open positions: snapshots always do, updates when an
+// instrument's size changed or its throttle interval passed; pushes of unchanged sizes in between are dropped
+func (ps *positionStream) positionsDue(entries []StreamPosition, snapshot bool) bool {
+        now := time.Now()
+        due := snapshot
+        for _, entry := range entries {
+                if entry.HoldSide != string(PositionSideLong) {
+                        continue
+                }
+                last, ok := ps.checked[entry.InstID]
+                if ok && entry.Total == last.total && now.Sub(last.at) < streamPositionInterval && !snapshot {
+                        continue
+                }
+                ps.checked[entry.InstID] = streamPush{total: entry.Total, at: now}
+                due = true
+        }
+        return due
+}
+
+// openPositionsOf loads a user's open positions with their take profit tranches
+func (te *TradingEngine) openPositionsOf(userID uint) ([]models.Position, bool) {
+        var positions []models.Position
+        err := database.WithDB(func(db *gorm.DB) error {
+                return db.Preload("User").Preload("Tranches").Where("user_id = ? AND status = ?", userID, models.PositionOpen).Find(&positions).Error
+        })
+        if err != nil {
+                log.Printf("⚠️ Failed to load open positions of user %d: %v", userID, err)
+                return nil, false
+        }
+        return positions, true
+}
+
+// applyStreamPositions applies pushed sizes and mark prices to open positions
+// A position missing from a snapshot is confirmed over REST before it is closed, so a push racing a new entry
+// can't close it
+func (te *TradingEngine) applyStreamPositions(ps *positionStream, positions []models.Position, entries []StreamPosition, snapshot bool) {
+        pushed := make(map[string]StreamPosition)
+        for _, entry := range entries {
+                if entry.HoldSide == string(PositionSideLong) {
+                        pushed[entry.InstID] = entry
+                }
+        }
+        
+        for _, position := range positions {
+                entry, ok := pushed[position.Symbol]
+                size, _ := strconv.ParseFloat(entry.Total, 64)
+                if !ok || size <= 0 {
+                        if ok || snapshot {
+                                te.withUserLock(position.User.TelegramID, func() { te.refreshPosition(position, false) })
+                        }
+                        continue
+                }
+                
+                // Size changes (take profit steps, manual partial closes) are applied at once, price moves throttled
+                sizeChanged := size < position.Quantity*(1-1e-6)
+                if !sizeChanged && time.Since(ps.applied[position.ID]) < streamPositionInterval {
+                        continue
+                }
+                price, err := strconv.ParseFloat(entry.MarkPrice, 64)
+                if err != nil || price <= 0 {
+                        continue
+                }
+                ps.applied[position.ID] = time.Now()
+                te.withUserLock(position.User.TelegramID, func() {
+                        te.applyPositionState(position, ps.exchange, size, price, false)
+                })
+        }
+}
+
+// applyStreamOrders checks a position over REST as soon as an order closing part or all of it fills,
+// which is how Bitget's plan orders show up when they trigger
+func (te *TradingEngine) applyStreamOrders(positions []models.Position, orders []StreamOrder) {
+        for _, order := range orders {
+                closing := order.TradeSide == "close" || order.ReduceOnly == "yes" || order.ReduceOnly == "YES"
+                if order.Status != "filled" || !closing {
+                        continue
+                }
+                for _, position := range positions {
+                        if position.Symbol != order.InstID {
+                                continue
+                        }
+                        log.Printf("⚡ Closing order %s filled for position %d (%s), checking it now", order.OrderID, position.ID, position.Symbol)
+                        te.withUserLock(position.User.TelegramID, func() { te.refreshPosition(position, false) })
+                }
+        }
+}
+
+func (te *TradingEngine) withUserLock(telegramID int64, fn func()) {
+        userMutex := te.getUserMutex(telegramID)
+        userMutex.Lock()
+        defer userMutex.Unlock()
+        fn()
+}
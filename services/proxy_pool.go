@@ -0,0 +1,362 @@
+package services
+
+import (
+        "fmt"
+        "io"
+        "log"
+        "net/http"
+        "net/url"
+        "strconv"
+        "strings"
+        "sync"
+        "sync/atomic"
+        "time"
+)
+
+// ProxyPool is an http.RoundTripper that rotates Upbit requests over several proxies
+// A proxy answering 403/429 is benched on its own and the request is retried on the next one,
+// so listing sources only see a rate limit once every proxy is benched
+type ProxyPool struct {
+        proxies []*poolProxy
+        next    uint64 // Round-robin cursor
+}
+
+// poolProxy is one egress route of the pool with its health state
+type poolProxy struct {
+        label     string // scheme://host:port without credentials, or "direct"
+        transport http.RoundTripper
+        
+        mutex             sync.Mutex
+        score             float64   // Health 0-100, moving average of request outcomes
+        benchedUntil      time.Time // Not used before this time
+        benchCount        int       // Consecutive benches, for exponential bench duration
+        consecutiveErrors int
+        requests          int64
+        successes         int64
+        failures          int64
+        rateLimited       int64
+        forbidden         int64
+        totalLatency      time.Duration
+        lastLatency       time.Duration
+        lastStatus        int
+        lastError         string
+}
+
+// ProxyStats is the health snapshot of one proxy, served on /health
+type ProxyStats struct {
+        Proxy         string     `json:"proxy"`
+        Score         float64    `json:"score"`
+        Benched       bool       `json:"benched"`
+        BenchedUntil  *time.Time `json:"benched_until,omitempty"`
+        Requests      int64      `json:"requests"`
+        Successes     int64      `json:"successes"`
+        Failures      int64      `json:"failures"`
+        RateLimited   int64      `json:"rate_limited"`
+        Forbidden     int64      `json:"forbidden"`
+        SuccessRate   float64    `json:"success_rate"`
+        AvgLatencyMs  int64      `json:"avg_latency_ms"`
+        LastLatencyMs int64      `json:"last_latency_ms"`
+        LastStatus    int        `json:"last_status,omitempty"`
+        LastError     string     `json:"last_error,omitempty"`
+}
+
+// Health score tuning
+const (
+        proxyScoreWeight   = 0.2  // Weight of the latest outcome in the moving average
+        proxyMinScore      = 20.0 // Below this a proxy is benched even without 403/429
+        proxyErrorBench    = 30 * time.Second
+        proxyMaxBench      = 10 * time.Minute
+)
+
+// NewProxyPool builds a pool from proxy URLs ("direct" means no proxy); an empty list gives a direct-only pool
+func NewProxyPool(proxyURLs []string) *ProxyPool {
+        pool := &ProxyPool{}
+        
+        for _, raw := range proxyURLs {
+                raw = strings.TrimSpace(raw)
+                if raw == "" {
+                        continue
+                }
+                
+                transport := http.DefaultTransport.(*http.Transport).Clone()
+                label := "direct"
+                if raw != "direct" {
+                        proxyURL, err := url.Parse(raw)
+                        if err != nil || proxyURL.Host == "" {
+                                log.Printf("⚠️ Invalid proxy URL ignored: %s", raw)
+                                continue
+                        }
+                        transport.Proxy = http.ProxyURL(proxyURL)
+                        label = proxyURL.Scheme + "://" + proxyURL.Host
+                }
+                
+                pool.proxies = append(pool.proxies, &poolProxy{label: label, transport: transport, score: 100})
+        }
+        
+        if len(pool.proxies) == 0 {
+                pool.proxies = append(pool.proxies, &poolProxy{label: "direct", transport: http.DefaultTransport.(*http.Transport).Clone(), score: 100})
+        }
+        
+        labels := make([]string, 0, len(pool.proxies))
+        for _, proxy := range pool.proxies {
+                labels = append(labels, proxy.label)
+        }
+        log.Printf("🌐 Upbit proxy pool: %s", strings.Join(labels, ", "))
+        
+        return pool
+}
+
+// RoundTrip sends the request through the next healthy proxy, retrying idempotent requests on 403/429 and network errors
+func (p *ProxyPool) RoundTrip(req *http.Request) (*http.Response, error) {
+        return p.roundTrip(req, nil)
+}
+
+// roundTrip is RoundTrip calling onAttempt before every proxy attempt, retries included
+func (p *ProxyPool) roundTrip(req *http.Request, onAttempt func()) (*http.Response, error) {
+        retryable := req.Method == http.MethodGet || req.Method == http.MethodHead
+        tried := make(map[*poolProxy]bool)
+        var lastErr error
+        
+        for attempt := 0; attempt < len(p.proxies); attempt++ {
+                proxy := p.pick(tried)
+                if proxy == nil {
+                        break
+                }
+                tried[proxy] = true
+                if onAttempt != nil {
+                        onAttempt()
+                }
+                
+                start := time.Now()
+                resp, err := proxy.transport.RoundTrip(req.Clone(req.Context()))
+                latency := time.Since(start)
+                
+                if err != nil {
+                        proxy.recordError(err, latency)
+                        lastErr = err
+                        if !retryable {
+                                return nil, err
+                        }
+                        continue
+                }
+                
+                if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+                        proxy.recordBlocked(resp, latency)
+                        if !retryable || attempt == len(p.proxies)-1 || p.available() == 0 {
+                                return resp, nil
+                        }
+                        io.Copy(io.Discard, resp.Body)
+                        resp.Body.Close()
+                        continue
+                }
+                
+                proxy.recordResponse(resp.StatusCode, latency)
+                return resp, nil
+        }
+        
+        if lastErr != nil {
+                return nil, fmt.Errorf("all proxies failed: %w", lastErr)
+        }
+        
+        // Every proxy is benched: report a rate limit until the first one comes back
+        return p.benchedResponse(req), nil
+}
+
+// pick returns the next unbenched proxy not yet tried for this request
+func (p *ProxyPool) pick(tried map[*poolProxy]bool) *poolProxy {
+        now := time.Now()
+        count := uint64(len(p.proxies))
+        start := atomic.AddUint64(&p.next, 1)
+        
+        for i := uint64(0); i < count; i++ {
+                proxy := p.proxies[(start+i)%count]
+                if tried[proxy] || proxy.isBenched(now) {
+                        continue
+                }
+                return proxy
+        }
+        return nil
+}
+
+// available counts proxies that are not benched
+func (p *ProxyPool) available() int {
+        now := time.Now()
+        count := 0
+        for _, proxy := range p.proxies {
+                if !proxy.isBenched(now) {
+                        count++
+                }
+        }
+        return count
+}
+
+// benchedResponse builds a 429 whose Retry-After points at the earliest proxy return
+func (p *ProxyPool) benchedResponse(req *http.Request) *http.Response {
+        var earliest time.Time
+        for _, proxy := range p.proxies {
+                proxy.mutex.Lock()
+                until := proxy.benchedUntil
+                proxy.mutex.Unlock()
+                if earliest.IsZero() || until.Before(earliest) {
+                        earliest = until
+                }
+        }
+        
+        retryAfter := int(time.Until(earliest).Seconds()) + 1
+        if retryAfter < 1 {
+                retryAfter = 1
+        }
+        
+        header := make(http.Header)
+        header.Set("Retry-After", strconv.Itoa(retryAfter))
+        return &http.Response{
+                Status:     "429 Too Many Requests (all proxies benched)",
+                StatusCode: http.StatusTooManyRequests,
+                Proto:      "HTTP/1.1",
+                ProtoMajor: 1,
+                ProtoMinor: 1,
+                Header:     header,
+                Body:       io.NopCloser(strings.NewReader("")),
+                Request:    req,
+        }
+}
+
+// Stats returns a health snapshot of every proxy
+func (p *ProxyPool) Stats() []ProxyStats {
+        now := time.Now()
+        stats := make([]ProxyStats, 0, len(p.proxies))
+        
+        for _, proxy := range p.proxies {
+                proxy.mutex.Lock()
+                stat := ProxyStats{
+                        Proxy:         proxy.label,
+                        Score:         float64(int(proxy.score*10)) / 10,
+                        Benched:       now.Before(proxy.benchedUntil),
+                        Requests:      proxy.requests,
+                        Successes:     proxy.successes,
+                        Failures:      proxy.failures,
+                        RateLimited:   proxy.rateLimited,
+                        Forbidden:     proxy.forbidden,
+                        LastLatencyMs: proxy.lastLatency.Milliseconds(),
+                        LastStatus:    proxy.lastStatus,
+                        LastError:     proxy.lastError,
+                }
+                if stat.Benched {
+                        until := proxy.benchedUntil
+                        stat.BenchedUntil = &until
+                }
+                if proxy.requests > 0 {
+                        stat.SuccessRate = float64(int(float64(proxy.successes)/float64(proxy.requests)*1000)) / 10
+                        stat.AvgLatencyMs = (proxy.totalLatency / time.Duration(proxy.requests)).Milliseconds()
+                }
+                proxy.mutex.Unlock()
+                
+                stats = append(stats, stat)
+        }
+        return stats
+}
+
+func (pp *poolProxy) isBenched(now time.Time) bool {
+        pp.mutex.Lock()
+        defer pp.mutex.Unlock()
+        return now.Before(pp.benchedUntil)
+}
+
+// recordResponse updates stats for a non-blocked response (any status other than 403/429)
+func (pp *poolProxy) recordResponse(status int, latency time.Duration) {
+        pp.mutex.Lock()
+        defer pp.mutex.Unlock()
+        
+        pp.track(latency)
+        pp.lastStatus = status
+        if status < 500 {
+                pp.successes++
+                pp.consecutiveErrors = 0
+                pp.benchCount = 0
+                pp.lastError = ""
+                pp.adjustScore(true)
+                return
+        }
+        
+        pp.failures++
+        pp.lastError = fmt.Sprintf("status %d", status)
+        pp.adjustScore(false)
+        pp.benchIfUnhealthy()
+}
+
+// recordError updates stats for a transport error (connect failure, timeout, ...)
+func (pp *poolProxy) recordError(err error, latency time.Duration) {
+        pp.mutex.Lock()
+        defer pp.mutex.Unlock()
+        
+        pp.track(latency)
+        pp.failures++
+        pp.lastStatus = 0
+        pp.lastError = err.Error()
+        pp.adjustScore(false)
+        
+        pp.consecutiveErrors++
+        if pp.consecutiveErrors >= 3 {
+                pp.bench(time.Duration(pp.consecutiveErrors) * proxyErrorBench, "consecutive errors")
+                return
+        }
+        pp.benchIfUnhealthy()
+}
+
+// recordBlocked benches the proxy after 403/429, honoring Retry-After when present
+func (pp *poolProxy) recordBlocked(resp *http.Response, latency time.Duration) {
+        pp.mutex.Lock()
+        defer pp.mutex.Unlock()
+        
+        pp.track(latency)
+        pp.failures++
+        pp.lastStatus = resp.StatusCode
+        pp.lastError = resp.Status
+        if resp.StatusCode == http.StatusTooManyRequests {
+                pp.rateLimited++
+        } else {
+                pp.forbidden++
+        }
+        pp.adjustScore(false)
+        
+        if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+                pp.bench(time.Duration(seconds) * time.Second, fmt.Sprintf("%d with Retry-After", resp.StatusCode))
+                return
+        }
+        
+        // Exponential bench: 1, 2, 4, 8, 10 minutes max
+        pp.benchCount++
+        duration := time.Duration(1<<uint(pp.benchCount-1)) * time.Minute
+        if duration > proxyMaxBench || pp.benchCount > 5 {
+                duration = proxyMaxBench
+        }
+        pp.bench(duration, strconv.Itoa(resp.StatusCode))
+}
+
+func (pp *poolProxy) track(latency time.Duration) {
+        pp.requests++
+        pp.totalLatency += latency
+        pp.lastLatency = latency
+}
+
+func (pp *poolProxy) adjustScore(success bool) {
+        outcome := 0.0
+        if success {
+                outcome = 100
+        }
+        pp.score = (1-proxyScoreWeight)*pp.score + proxyScoreWeight*outcome
+}
+
+// benchIfUnhealthy benches a proxy whose score dropped too low, then gives it a fresh start
+func (pp *poolProxy) benchIfUnhealthy() {
+        if pp.score >= proxyMinScore {
+                return
+        }
+        pp.bench(proxyErrorBench, fmt.Sprintf("health score %.0f", pp.score))
+        pp.score = proxyMinScore * 2
+}
+
+func (pp *poolProxy) bench(duration time.Duration, reason string) {
+        pp.benchedUntil = time.Now().Add(duration)
+        log.Printf("🪑 Proxy %s benched for %v (%s, score %.0f)", pp.label, duration, reason, pp.score)
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+        "net/http"
+        "net/http/httptest"
+        "strconv"
+        "sync/atomic"
+        "testing"
+        "time"
+)
+
+// newFakeProxy answers every proxied request itself with the given status and counts hits
+func newFakeProxy(t *testing.T, status *int32, hits *int32) string {
+        t.Helper()
+        server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+                atomic.AddInt32(hits, 1)
+                w.WriteHeader(int(atomic.LoadInt32(status)))
+        }))
+        t.Cleanup(server.Close)
+        return server.URL
+}
+
+func poolGet(t *testing.T, pool *ProxyPool) *http.Response {
+        t.Helper()
+        client := &http.Client{Timeout: 5 * time.Second, Transport: pool}
+        resp, err := client.Get("http://upbit.invalid/api/v1/announcements")
+        if err != nil {
+                t.Fatalf("request failed: %v", err)
+        }
+        resp.Body.Close()
+        return resp
+}
+
+func TestProxyPoolRotatesPerRequest(t *testing.T) {
+        ok := int32(http.StatusOK)
+        var hitsA, hitsB int32
+        pool := NewProxyPool([]string{newFakeProxy(t, &ok, &hitsA), newFakeProxy(t, &ok, &hitsB)})
+        
+        for i := 0; i < 4; i++ {
+                poolGet(t, pool)
+        }
+        
+        if hitsA != 2 || hitsB != 2 {
+                t.Errorf("expected requests split 2/2 across proxies, got %d/%d", hitsA, hitsB)
+        }
+}
+
+func TestProxyPoolBenchesBlockedProxyAndRetries(t *testing.T) {
+        forbidden := int32(http.StatusForbidden)
+        ok := int32(http.StatusOK)
+        var hitsBlocked, hitsHealthy int32
+        pool := NewProxyPool([]string{newFakeProxy(t, &forbidden, &hitsBlocked), newFakeProxy(t, &ok, &hitsHealthy)})
+        
+        for i := 0; i < 4; i++ {
+                if resp := poolGet(t, pool); resp.StatusCode != http.StatusOK {
+                        t.Fatalf("request %d: expected 200 from the healthy proxy, got %d", i, resp.StatusCode)
+                }
+        }
+        
+        if hitsBlocked != 1 {
+                t.Errorf("expected the blocked proxy to be benched after one 403, got %d hits", hitsBlocked)
+        }
+        if hitsHealthy != 4 {
+                t.Errorf("expected the healthy proxy to serve all 4 requests, got %d", hitsHealthy)
+        }
+        
+        stats := pool.Stats()
+        if !stats[0].Benched || stats[0].Forbidden != 1 || stats[0].Score >= 100 {
+                t.Errorf("expected first proxy benched with one 403, got %+v", stats[0])
+        }
+        if stats[1].Benched || stats[1].Successes != 4 || stats[1].SuccessRate != 100 {
+                t.Errorf("expected second proxy healthy with 4 successes, got %+v", stats[1])
+        }
+}
+
+func TestProxyPoolAllBenchedReportsRetryAfter(t *testing.T) {
+        limited := int32(http.StatusTooManyRequests)
+        var hits int32
+        pool := NewProxyPool([]string{newFakeProxy(t, &limited, &hits)})
+        
+        if resp := poolGet(t, pool); resp.StatusCode != http.StatusTooManyRequests {
+                t.Fatalf("expected upstream 429 when the only proxy is rate limited, got %d", resp.StatusCode)
+        }
+        
+        resp := poolGet(t, pool)
+        if resp.StatusCode != http.StatusTooManyRequests {
+                t.Fatalf("expected 429 while every proxy is benched, got %d", resp.StatusCode)
+        }
+        retryAfter, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+        if err != nil || retryAfter < 50 || retryAfter > 61 {
+                t.Errorf("expected Retry-After of ~60s (first bench), got %q", resp.Header.Get("Retry-After"))
+        }
+        if hits != 1 {
+                t.Errorf("expected no request to reach a benched proxy, got %d hits", hits)
+        }
+}
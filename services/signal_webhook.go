@@ -0,0 +1,264 @@
+package services
+
+import (
+        "crypto/hmac"
+        "crypto/sha256"
+        "encoding/hex"
+        "encoding/json"
+        "fmt"
+        "io"
+        "log"
+        "net/http"
+        "regexp"
+        "sort"
+        "strconv"
+        "strings"
+        "sync"
+        "time"
+)
+
+// SignalWebhookConfig configures the external listing signal endpoint
+type SignalWebhookConfig struct {
+        Secret        string          // HMAC-SHA256 key shared with the signal senders
+        Sources       map[string]bool // Known signal sources and whether each one is enabled
+        MaxSkew       time.Duration   // Max age (and clock skew) of a signed request
+        MinConfidence float64         // Signals below this confidence (0-1) are acknowledged but not traded
+}
+
+// SignalWebhook receives listing signals from external scrapers on POST /signals
+// Requests carry X-Signal-Timestamp (unix seconds), X-Signal-Nonce and X-Signal-Signature, where the signature is
+// hex(HMAC-SHA256(secret, timestamp + "." + nonce + "." + body)). A nonce is accepted once within MaxSkew.
+type SignalWebhook struct {
+        config SignalWebhookConfig
+        ingest func(CoinListing) bool // Emits an accepted signal through the monitor's dedupe and trading path
+        
+        mutex  sync.Mutex
+        nonces map[string]time.Time // Nonces seen within MaxSkew
+}
+
+// ListingSignal is the JSON body of a signal
+type ListingSignal struct {
+        Symbol          string   `json:"symbol"`
+        Source          string   `json:"source"`
+        Market          string   `json:"market"`            // KRW, USDT or BTC
+        Markets         []string `json:"markets,omitempty"` // Optional, when the listing opens several markets
+        Confidence      float64  `json:"confidence"`        // Above 0, up to 1
+        AnnouncementID  string   `json:"announcement_id,omitempty"`
+        AnnouncementURL string   `json:"announcement_url,omitempty"`
+        Title           string   `json:"title,omitempty"`
+        AnnouncedAt     string   `json:"announced_at,omitempty"` // RFC3339
+}
+
+// signalResponse is returned for every request
+type signalResponse struct {
+        Accepted bool   `json:"accepted"`
+        Emitted  bool   `json:"emitted"`
+        Reason   string `json:"reason,omitempty"`
+}
+
+const maxSignalBodySize = 64 * 1024
+
+// signalSourcePrefix marks the source of listings pushed through the webhook, e.g. signal_alpha
+const signalSourcePrefix = "signal_"
+
+var signalSymbolPattern = regexp.MustCompile(`^[A-Z0-9]{1,20}$`)
+
+// NewSignalWebhook creates the endpoint; accepted signals are emitted through the monitor
+func NewSignalWebhook(config SignalWebhookConfig, monitor *UpbitMonitor) *SignalWebhook {
+        if config.MaxSkew <= 0 {
+                config.MaxSkew = 5 * time.Minute
+        }
+        
+        webhook := &SignalWebhook{
+                config: config,
+                ingest: monitor.IngestSignal,
+                nonces: make(map[string]time.Time),
+        }
+        
+        var enabled []string
+        for source, on := range config.Sources {
+                if on {
+                        enabled = append(enabled, source)
+                }
+        }
+        sort.Strings(enabled)
+        log.Printf("📨 Signal webhook ready (enabled sources: %s, min confidence %.2f)", strings.Join(enabled, ", "), config.MinConfidence)
+        
+        return webhook
+}
+
+// ParseSignalSources reads "name" or "name:off" entries into per-source enable flags
+func ParseSignalSources(entries []string) map[string]bool {
+        sources := make(map[string]bool)
+        for _, entry := range entries {
+                name, flag, _ := strings.Cut(strings.TrimSpace(entry), ":")
+                name = strings.ToLower(strings.TrimSpace(name))
+                if name == "" {
+                        continue
+                }
+                switch strings.ToLower(strings.TrimSpace(flag)) {
+                case "off", "false", "0", "disabled":
+                        sources[name] = false
+                default:
+                        sources[name] = true
+                }
+        }
+        return sources
+}
+
+// ServeHTTP handles POST /signals
+func (w *SignalWebhook) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+        if r.Method != http.MethodPost {
+                rw.Header().Set("Allow", http.MethodPost)
+                w.respond(rw, http.StatusMethodNotAllowed, signalResponse{Reason: "method not allowed"})
+                return
+        }
+        
+        body, err := io.ReadAll(http.MaxBytesReader(rw, r.Body, maxSignalBodySize))
+        if err != nil {
+                w.respond(rw, http.StatusRequestEntityTooLarge, signalResponse{Reason: "body too large"})
+                return
+        }
+        
+        if status, reason := w.authenticate(r.Header, body, time.Now()); status != http.StatusOK {
+                log.Printf("🚫 Rejected signal from %s: %s", r.RemoteAddr, reason)
+                w.respond(rw, status, signalResponse{Reason: reason})
+                return
+        }
+        
+        var signal ListingSignal
+        if err := json.Unmarshal(body, &signal); err != nil {
+                w.respond(rw, http.StatusBadRequest, signalResponse{Reason: "invalid JSON"})
+                return
+        }
+        listing, err := signal.listing()
+        if err != nil {
+                w.respond(rw, http.StatusBadRequest, signalResponse{Reason: err.Error()})
+                return
+        }
+        
+        source := strings.TrimPrefix(listing.Source, signalSourcePrefix)
+        if !w.config.Sources[source] {
+                log.Printf("🚫 Signal for %s from disabled or unknown source %q", listing.Symbol, signal.Source)
+                w.respond(rw, http.StatusForbidden, signalResponse{Reason: "source not enabled"})
+                return
+        }
+        
+        if signal.Confidence < w.config.MinConfidence {
+                log.Printf("📨 Signal %s from %s below min confidence (%.2f < %.2f), not traded",
+                        listing.Symbol, source, signal.Confidence, w.config.MinConfidence)
+                w.respond(rw, http.StatusOK, signalResponse{Accepted: true, Reason: "below min confidence"})
+                return
+        }
+        
+        log.Printf("📨 Signal %s (%v) from %s, confidence %.2f", listing.Symbol, listing.Markets, source, signal.Confidence)
+        if !w.ingest(listing) {
+                w.respond(rw, http.StatusOK, signalResponse{Accepted: true, Reason: "not emitted: duplicate or awaiting confirmation"})
+                return
+        }
+        
+        w.respond(rw, http.StatusAccepted, signalResponse{Accepted: true, Emitted: true})
+}
+
+// authenticate checks the signature, timestamp and nonce of a request
+// Returns http.StatusOK or the status to reject the request with and why
+func (w *SignalWebhook) authenticate(header http.Header, body []byte, now time.Time) (int, string) {
+        timestamp := header.Get("X-Signal-Timestamp")
+        nonce := header.Get("X-Signal-Nonce")
+        signature := header.Get("X-Signal-Signature")
+        if timestamp == "" || nonce == "" || signature == "" {
+                return http.StatusUnauthorized, "missing signature headers"
+        }
+        if len(nonce) > 128 {
+                return http.StatusBadRequest, "nonce too long"
+        }
+        
+        expected := SignSignal(w.config.Secret, timestamp, nonce, body)
+        if !hmac.Equal([]byte(strings.ToLower(signature)), []byte(expected)) {
+                return http.StatusUnauthorized, "invalid signature"
+        }
+        
+        seconds, err := strconv.ParseInt(timestamp, 10, 64)
+        if err != nil {
+                return http.StatusUnauthorized, "invalid timestamp"
+        }
+        sentAt := time.Unix(seconds, 0)
+        if sentAt.Before(now.Add(-w.config.MaxSkew)) || sentAt.After(now.Add(w.config.MaxSkew)) {
+                return http.StatusUnauthorized, "timestamp outside allowed window"
+        }
+        
+        // Only signed requests reach the nonce cache, so it can't be filled by unauthenticated callers
+        w.mutex.Lock()
+        defer w.mutex.Unlock()
+        for seen, at := range w.nonces {
+                if now.Sub(at) > 2*w.config.MaxSkew {
+                        delete(w.nonces, seen)
+                }
+        }
+        if _, replayed := w.nonces[nonce]; replayed {
+                return http.StatusConflict, "nonce already used"
+        }
+        w.nonces[nonce] = now
+        
+        return http.StatusOK, ""
+}
+
+// SignSignal computes the signature a sender puts in X-Signal-Signature
+func SignSignal(secret, timestamp, nonce string, body []byte) string {
+        mac := hmac.New(sha256.New, []byte(secret))
+        mac.Write([]byte(timestamp + "." + nonce + "."))
+        mac.Write(body)
+        return hex.EncodeToString(mac.Sum(nil))
+}
+
+// listing validates a signal and converts it to a detection event
+func (s ListingSignal) listing() (CoinListing, error) {
+        symbol := strings.ToUpper(strings.TrimSpace(s.Symbol))
+        if !signalSymbolPattern.MatchString(symbol) {
+                return CoinListing{}, fmt.Errorf("invalid symbol %q", s.Symbol)
+        }
+        source := strings.ToLower(strings.TrimSpace(s.Source))
+        if source == "" {
+                return CoinListing{}, fmt.Errorf("source is required")
+        }
+        if s.Confidence <= 0 || s.Confidence > 1 {
+                return CoinListing{}, fmt.Errorf("confidence must be above 0 and at most 1")
+        }
+        
+        var markets []string
+        for _, market := range append([]string{s.Market}, s.Markets...) {
+                market = strings.ToUpper(strings.TrimSpace(market))
+                if market == "" || containsString(markets, market) {
+                        continue
+                }
+                if market != MarketKRW && market != MarketUSDT && market != MarketBTC {
+                        return CoinListing{}, fmt.Errorf("unknown market %q", market)
+                }
+                markets = append(markets, market)
+        }
+        
+        listing := CoinListing{
+                Symbol:            symbol,
+                Kind:              ListingKindNew,
+                Source:            signalSourcePrefix + source,
+                AnnouncementID:    s.AnnouncementID,
+                AnnouncementURL:   s.AnnouncementURL,
+                AnnouncementTitle: s.Title,
+                Markets:           markets,
+                Confidence:        s.Confidence,
+        }
+        if s.AnnouncedAt != "" {
+                announcedAt, err := time.Parse(time.RFC3339, s.AnnouncedAt)
+                if err != nil {
+                        return CoinListing{}, fmt.Errorf("announced_at must be RFC3339")
+                }
+                listing.AnnouncedAt = announcedAt
+        }
+        return listing, nil
+}
+
+func (w *SignalWebhook) respond(rw http.ResponseWriter, status int, response signalResponse) {
+        rw.Header().Set("Content-Type", "application/json")
+        rw.WriteHeader(status)
+        json.NewEncoder(rw).Encode(response)
+}
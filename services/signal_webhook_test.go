@@ -0,0 +1,120 @@
+package services
+
+import (
+        "net/http"
+        "net/http/httptest"
+        "strconv"
+        "strings"
+        "testing"
+        "time"
+)
+
+func newTestSignalWebhook(emitted *[]CoinListing) *SignalWebhook {
+        webhook := &SignalWebhook{
+                config: SignalWebhookConfig{
+                        Secret:        "test-secret",
+                        Sources:       ParseSignalSources([]string{"alpha", "beta:off"}),
+                        MaxSkew:       time.Minute,
+                        MinConfidence: 0.5,
+                },
+                nonces: make(map[string]time.Time),
+        }
+        webhook.ingest = func(listing CoinListing) bool {
+                *emitted = append(*emitted, listing)
+                return len(*emitted) == 1 // Later signals behave like monitor duplicates
+        }
+        return webhook
+}
+
+func postSignal(webhook *SignalWebhook, body string, nonce string, sentAt time.Time, secret string) *httptest.ResponseRecorder {
+        timestamp := strconv.FormatInt(sentAt.Unix(), 10)
+        req := httptest.NewRequest(http.MethodPost, "/signals", strings.NewReader(body))
+        req.Header.Set("X-Signal-Timestamp", timestamp)
+        req.Header.Set("X-Signal-Nonce", nonce)
+        req.Header.Set("X-Signal-Signature", SignSignal(secret, timestamp, nonce, []byte(body)))
+        
+        rec := httptest.NewRecorder()
+        webhook.ServeHTTP(rec, req)
+        return rec
+}
+
+func TestSignalWebhookAcceptsSignedSignal(t *testing.T) {
+        var emitted []CoinListing
+        webhook := newTestSignalWebhook(&emitted)
+        
+        body := `{"symbol":"new","source":"Alpha","market":"krw","markets":["USDT"],"confidence":0.9,"announced_at":"2025-06-26T08:00:00Z"}`
+        rec := postSignal(webhook, body, "n1", time.Now(), "test-secret")
+        if rec.Code != http.StatusAccepted {
+                t.Fatalf("status = %d, body %s", rec.Code, rec.Body.String())
+        }
+        if len(emitted) != 1 {
+                t.Fatalf("emitted %d listings, want 1", len(emitted))
+        }
+        
+        listing := emitted[0]
+        if listing.Symbol != "NEW" || listing.Source != "signal_alpha" || listing.Confidence != 0.9 {
+                t.Errorf("unexpected listing %+v", listing)
+        }
+        if len(listing.Markets) != 2 || listing.Markets[0] != MarketKRW || listing.Markets[1] != MarketUSDT {
+                t.Errorf("markets = %v", listing.Markets)
+        }
+        if listing.AnnouncedAt.IsZero() {
+                t.Error("announced_at not parsed")
+        }
+        
+        // The monitor reports the second one as a duplicate
+        rec = postSignal(webhook, body, "n2", time.Now(), "test-secret")
+        if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "duplicate") {
+                t.Errorf("duplicate: status %d, body %s", rec.Code, rec.Body.String())
+        }
+}
+
+func TestSignalWebhookRejects(t *testing.T) {
+        valid := `{"symbol":"NEW","source":"alpha","market":"KRW","confidence":0.9}`
+        cases := []struct {
+                name   string
+                body   string
+                nonce  string
+                sentAt time.Time
+                secret string
+                status int
+        }{
+                {"bad signature", valid, "a", time.Now(), "wrong-secret", http.StatusUnauthorized},
+                {"stale timestamp", valid, "b", time.Now().Add(-2 * time.Minute), "test-secret", http.StatusUnauthorized},
+                {"future timestamp", valid, "c", time.Now().Add(2 * time.Minute), "test-secret", http.StatusUnauthorized},
+                {"disabled source", `{"symbol":"NEW","source":"beta","market":"KRW","confidence":0.9}`, "d", time.Now(), "test-secret", http.StatusForbidden},
+                {"unknown source", `{"symbol":"NEW","source":"gamma","market":"KRW","confidence":0.9}`, "e", time.Now(), "test-secret", http.StatusForbidden},
+                {"invalid symbol", `{"symbol":"NE W","source":"alpha","market":"KRW","confidence":0.9}`, "f", time.Now(), "test-secret", http.StatusBadRequest},
+                {"unknown market", `{"symbol":"NEW","source":"alpha","market":"ETH","confidence":0.9}`, "g", time.Now(), "test-secret", http.StatusBadRequest},
+                {"zero confidence", `{"symbol":"NEW","source":"alpha","market":"KRW","confidence":0}`, "h", time.Now(), "test-secret", http.StatusBadRequest},
+                {"missing confidence", `{"symbol":"NEW","source":"alpha","market":"KRW"}`, "i", time.Now(), "test-secret", http.StatusBadRequest},
+        }
+        
+        for _, tc := range cases {
+                var emitted []CoinListing
+                webhook := newTestSignalWebhook(&emitted)
+                rec := postSignal(webhook, tc.body, tc.nonce, tc.sentAt, tc.secret)
+                if rec.Code != tc.status {
+                        t.Errorf("%s: status = %d, want %d (%s)", tc.name, rec.Code, tc.status, rec.Body.String())
+                }
+                if len(emitted) != 0 {
+                        t.Errorf("%s: signal was emitted", tc.name)
+                }
+        }
+}
+
+func TestSignalWebhookReplayAndLowConfidence(t *testing.T) {
+        var emitted []CoinListing
+        webhook := newTestSignalWebhook(&emitted)
+        
+        low := `{"symbol":"NEW","source":"alpha","market":"KRW","confidence":0.2}`
+        rec := postSignal(webhook, low, "same", time.Now(), "test-secret")
+        if rec.Code != http.StatusOK || len(emitted) != 0 {
+                t.Errorf("low confidence: status %d, emitted %d", rec.Code, len(emitted))
+        }
+        
+        rec = postSignal(webhook, low, "same", time.Now(), "test-secret")
+        if rec.Code != http.StatusConflict {
+                t.Errorf("replayed nonce: status = %d, want %d", rec.Code, http.StatusConflict)
+        }
+}
@@ -0,0 +1,403 @@
+package services
+
+import (
+        "fmt"
+        "log"
+        "math"
+        "regexp"
+        "sort"
+        "strings"
+        "sync"
+        "time"
+        "upbit-bitget-trading-bot/database"
+        "upbit-bitget-trading-bot/models"
+
+        "gorm.io/gorm"
+        "gorm.io/gorm/clause"
+)
+
+// SymbolMapper resolves Upbit tickers to Bitget futures symbols
+// Contract mappings are seeded from Bitget's contract list; admin overrides always win
+type SymbolMapper struct {
+        mutex          sync.RWMutex
+        contracts      map[string]models.SymbolMapping // Upbit symbol -> mapping derived from the contract list
+        overrides      map[string]models.SymbolMapping // Upbit symbol -> admin override
+        lastSync       time.Time
+        loadedFromDB   bool
+        fetchContracts func() ([]ContractInfo, error)
+}
+
+// SymbolDecision is the outcome of resolving one Upbit ticker, logged on every trade
+type SymbolDecision struct {
+        UpbitSymbol  string
+        BitgetSymbol string
+        Multiplier   float64
+        Source       string // admin, contracts or default
+        Tradeable    bool
+        Reason       string
+}
+
+// symbolMappingDefault marks a decision that fell back to appending USDT
+const symbolMappingDefault = "default"
+
+// Scaled contracts such as 1000SATS or 1MBABYDOGE trade a multiple of the coin
+var scaledBaseCoinPattern = regexp.MustCompile(`^(1000000|100000|10000|1000|1M)([A-Z][A-Z0-9]*)$`)
+
+// NewSymbolMapper creates a mapper that seeds itself from Bitget's public contract list
+func NewSymbolMapper() *SymbolMapper {
+        publicAPI := NewBitgetAPI("", "", "")
+        return &SymbolMapper{
+                contracts:      make(map[string]models.SymbolMapping),
+                overrides:      make(map[string]models.SymbolMapping),
+                fetchContracts: publicAPI.GetContracts,
+        }
+}
+
+// String renders the decision for logs and Telegram
+func (d SymbolDecision) String() string {
+        if !d.Tradeable {
+                return fmt.Sprintf("%s -> not tradeable (%s)", d.UpbitSymbol, d.Reason)
+        }
+        if d.Multiplier != 1 {
+                return fmt.Sprintf("%s -> %s x%g via %s (%s)", d.UpbitSymbol, d.BitgetSymbol, d.Multiplier, d.Source, d.Reason)
+        }
+        return fmt.Sprintf("%s -> %s via %s (%s)", d.UpbitSymbol, d.BitgetSymbol, d.Source, d.Reason)
+}
+
+// Resolve returns the Bitget symbol to trade for an Upbit ticker
+// Unknown tickers fall back to TICKERUSDT so contracts listed after the last sync can still be tried
+func (m *SymbolMapper) Resolve(upbitSymbol string) SymbolDecision {
+        m.ensureLoaded()
+        
+        symbol := strings.ToUpper(strings.TrimSpace(upbitSymbol))
+        
+        m.mutex.RLock()
+        override, hasOverride := m.overrides[symbol]
+        contract, hasContract := m.contracts[symbol]
+        synced := !m.lastSync.IsZero() || len(m.contracts) > 0
+        m.mutex.RUnlock()
+        
+        switch {
+        case hasOverride && override.Disabled:
+                reason := "disabled by admin"
+                if override.Note != "" {
+                        reason += ": " + override.Note
+                }
+                return SymbolDecision{UpbitSymbol: symbol, Source: models.SymbolMappingAdmin, Reason: reason}
+        case hasOverride:
+                reason := "admin override"
+                if override.Note != "" {
+                        reason += ": " + override.Note
+                }
+                return decisionFromMapping(symbol, override, reason)
+        case hasContract:
+                return decisionFromMapping(symbol, contract, contract.Note)
+        }
+        
+        reason := "not in Bitget contract list, trying default"
+        if !synced {
+                reason = "contract list not loaded yet, trying default"
+        }
+        return SymbolDecision{
+                UpbitSymbol:  symbol,
+                BitgetSymbol: symbol + "USDT",
+                Multiplier:   1,
+                Source:       symbolMappingDefault,
+                Tradeable:    true,
+                Reason:       reason,
+        }
+}
+
+func decisionFromMapping(symbol string, mapping models.SymbolMapping, reason string) SymbolDecision {
+        multiplier := mapping.Multiplier
+        if multiplier <= 0 {
+                multiplier = 1
+        }
+        return SymbolDecision{
+                UpbitSymbol:  symbol,
+                BitgetSymbol: mapping.BitgetSymbol,
+                Multiplier:   multiplier,
+                Source:       mapping.Source,
+                Tradeable:    mapping.BitgetSymbol != "",
+                Reason:       reason,
+        }
+}
+
+// StartSync seeds the mapping table now and then refreshes it periodically (blocking)
+func (m *SymbolMapper) StartSync(interval time.Duration) {
+        if interval <= 0 {
+                interval = time.Hour
+        }
+        for {
+                if err := m.Sync(); err != nil {
+                        log.Printf("❌ Symbol mapping sync failed: %v", err)
+                }
+                time.Sleep(interval)
+        }
+}
+
+// Sync reloads the Bitget contract list and stores the derived mappings
+func (m *SymbolMapper) Sync() error {
+        contracts, err := m.fetchContracts()
+        if err != nil {
+                return fmt.Errorf("failed to fetch Bitget contracts: %w", err)
+        }
+        return m.apply(contracts)
+}
+
+// CheckContracts fetches the contract list and returns the symbols open for trading
+// Contracts listed since the last sync are picked up into the mappings right away
+func (m *SymbolMapper) CheckContracts() (map[string]bool, error) {
+        contracts, err := m.fetchContracts()
+        if err != nil {
+                return nil, fmt.Errorf("failed to fetch Bitget contracts: %w", err)
+        }
+        
+        live := make(map[string]bool)
+        m.mutex.RLock()
+        newContracts := 0
+        for _, contract := range contracts {
+                if contract.SymbolStatus == "normal" {
+                        live[contract.Symbol] = true
+                }
+                if _, known := m.contracts[strings.ToUpper(contract.BaseCoin)]; !known && contract.QuoteCoin == "USDT" {
+                        newContracts++
+                }
+        }
+        m.mutex.RUnlock()
+        
+        if newContracts > 0 {
+                if err := m.apply(contracts); err != nil {
+                        log.Printf("⚠️ Failed to apply %d new contracts to symbol mappings: %v", newContracts, err)
+                }
+        }
+        return live, nil
+}
+
+// apply replaces the contract mappings with ones derived from a contract list
+func (m *SymbolMapper) apply(contracts []ContractInfo) error {
+        mappings := mappingsFromContracts(contracts)
+        if len(mappings) == 0 {
+                return fmt.Errorf("contract list contained no USDT contracts, keeping previous mappings")
+        }
+        
+        m.mutex.Lock()
+        m.contracts = mappings
+        m.lastSync = time.Now()
+        m.mutex.Unlock()
+        
+        log.Printf("🗺️ Symbol mappings synced from %d Bitget contracts (%d tickers)", len(contracts), len(mappings))
+        
+        m.persistContractMappings(mappings)
+        m.ensureLoaded()
+        return nil
+}
+
+// mappingsFromContracts derives Upbit ticker mappings from the contract list
+// A direct contract (SATSUSDT) wins over a scaled one (1000SATSUSDT) for the same coin
+func mappingsFromContracts(contracts []ContractInfo) map[string]models.SymbolMapping {
+        mappings := make(map[string]models.SymbolMapping)
+        scaled := make(map[string]models.SymbolMapping)
+        
+        for _, contract := range contracts {
+                if contract.QuoteCoin != "USDT" || contract.Symbol == "" || contract.SymbolStatus == "off" {
+                        continue
+                }
+                base := strings.ToUpper(contract.BaseCoin)
+                
+                mappings[base] = models.SymbolMapping{
+                        UpbitSymbol:  base,
+                        BitgetSymbol: contract.Symbol,
+                        Multiplier:   1,
+                        Source:       models.SymbolMappingContracts,
+                        Note:         "direct contract",
+                }
+                
+                if match := scaledBaseCoinPattern.FindStringSubmatch(base); match != nil {
+                        multiplier := 1e6
+                        if match[1] != "1M" {
+                                multiplier = math.Pow10(len(match[1]) - 1)
+                        }
+                        scaled[match[2]] = models.SymbolMapping{
+                                UpbitSymbol:  match[2],
+                                BitgetSymbol: contract.Symbol,
+                                Multiplier:   multiplier,
+                                Source:       models.SymbolMappingContracts,
+                                Note:         fmt.Sprintf("scaled contract %s", base),
+                        }
+                }
+        }
+        
+        for symbol, mapping := range scaled {
+                if _, direct := mappings[symbol]; !direct {
+                        mappings[symbol] = mapping
+                }
+        }
+        return mappings
+}
+
+// persistContractMappings upserts contract mappings and drops ones whose contract is gone
+// Admin rows are left untouched
+func (m *SymbolMapper) persistContractMappings(mappings map[string]models.SymbolMapping) {
+        rows := make([]models.SymbolMapping, 0, len(mappings))
+        symbols := make([]string, 0, len(mappings))
+        for symbol, mapping := range mappings {
+                rows = append(rows, mapping)
+                symbols = append(symbols, symbol)
+        }
+        
+        err := database.WithDB(func(db *gorm.DB) error {
+                return db.Transaction(func(tx *gorm.DB) error {
+                        err := tx.Clauses(clause.OnConflict{
+                                Columns:   []clause.Column{{Name: "upbit_symbol"}},
+                                DoUpdates: clause.AssignmentColumns([]string{"bitget_symbol", "multiplier", "note", "updated_at"}),
+                                Where:     clause.Where{Exprs: []clause.Expression{clause.Eq{Column: "symbol_mappings.source", Value: models.SymbolMappingContracts}}},
+                        }).CreateInBatches(rows, 200).Error
+                        if err != nil {
+                                return err
+                        }
+                        return tx.Where("source = ? AND upbit_symbol NOT IN ?", models.SymbolMappingContracts, symbols).
+                                Delete(&models.SymbolMapping{}).Error
+                })
+        })
+        if err != nil {
+                if err.Error() == "database not available" {
+                        log.Printf("⚠️ Database unavailable, symbol mappings kept in memory only")
+                } else {
+                        log.Printf("❌ Failed to persist symbol mappings: %v", err)
+                }
+        }
+}
+
+// ensureLoaded reads admin overrides (and contract rows, if no sync succeeded yet) from the database once
+func (m *SymbolMapper) ensureLoaded() {
+        m.mutex.RLock()
+        loaded := m.loadedFromDB
+        m.mutex.RUnlock()
+        if loaded || !database.IsConnected() {
+                return
+        }
+        
+        var rows []models.SymbolMapping
+        err := database.WithDB(func(db *gorm.DB) error {
+                return db.Find(&rows).Error
+        })
+        if err != nil {
+                log.Printf("❌ Failed to load symbol mappings: %v", err)
+                return
+        }
+        
+        m.mutex.Lock()
+        defer m.mutex.Unlock()
+        
+        useStoredContracts := len(m.contracts) == 0
+        overrides := 0
+        for _, row := range rows {
+                switch row.Source {
+                case models.SymbolMappingAdmin:
+                        m.overrides[row.UpbitSymbol] = row
+                        overrides++
+                case models.SymbolMappingContracts:
+                        if useStoredContracts {
+                                m.contracts[row.UpbitSymbol] = row
+                        }
+                }
+        }
+        m.loadedFromDB = true
+        
+        log.Printf("🗺️ Loaded %d symbol mappings (%d admin overrides)", len(rows), overrides)
+}
+
+// SetOverride stores an admin mapping; an empty bitgetSymbol disables trading the ticker
+func (m *SymbolMapper) SetOverride(upbitSymbol, bitgetSymbol string, multiplier float64, note string, adminID int64) (SymbolDecision, error) {
+        symbol := strings.ToUpper(strings.TrimSpace(upbitSymbol))
+        if symbol == "" {
+                return SymbolDecision{}, fmt.Errorf("empty Upbit symbol")
+        }
+        if multiplier <= 0 {
+                multiplier = 1
+        }
+        
+        mapping := models.SymbolMapping{
+                UpbitSymbol:  symbol,
+                BitgetSymbol: strings.ToUpper(strings.TrimSpace(bitgetSymbol)),
+                Multiplier:   multiplier,
+                Source:       models.SymbolMappingAdmin,
+                Disabled:     bitgetSymbol == "",
+                Note:         note,
+                UpdatedBy:    adminID,
+        }
+        
+        err := database.WithDB(func(db *gorm.DB) error {
+                return db.Clauses(clause.OnConflict{
+                        Columns:   []clause.Column{{Name: "upbit_symbol"}},
+                        DoUpdates: clause.AssignmentColumns([]string{"bitget_symbol", "multiplier", "source", "disabled", "note", "updated_by", "updated_at"}),
+                }).Create(&mapping).Error
+        })
+        if err != nil {
+                return SymbolDecision{}, fmt.Errorf("failed to save override: %w", err)
+        }
+        
+        m.mutex.Lock()
+        m.overrides[symbol] = mapping
+        m.mutex.Unlock()
+        
+        log.Printf("🗺️ Admin %d set symbol mapping %s -> %q x%g (disabled: %v)", adminID, symbol, mapping.BitgetSymbol, multiplier, mapping.Disabled)
+        return m.Resolve(symbol), nil
+}
+
+// ClearOverride removes an admin mapping so the contract list applies again
+func (m *SymbolMapper) ClearOverride(upbitSymbol string, adminID int64) (SymbolDecision, error) {
+        symbol := strings.ToUpper(strings.TrimSpace(upbitSymbol))
+        
+        m.mutex.RLock()
+        contract, hasContract := m.contracts[symbol]
+        m.mutex.RUnlock()
+        
+        err := database.WithDB(func(db *gorm.DB) error {
+                return db.Transaction(func(tx *gorm.DB) error {
+                        if err := tx.Where("upbit_symbol = ? AND source = ?", symbol, models.SymbolMappingAdmin).Delete(&models.SymbolMapping{}).Error; err != nil {
+                                return err
+                        }
+                        if hasContract {
+                                contract.ID = 0
+                                return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&contract).Error
+                        }
+                        return nil
+                })
+        })
+        if err != nil {
+                return SymbolDecision{}, fmt.Errorf("failed to clear override: %w", err)
+        }
+        
+        m.mutex.Lock()
+        delete(m.overrides, symbol)
+        m.mutex.Unlock()
+        
+        log.Printf("🗺️ Admin %d cleared symbol mapping override for %s", adminID, symbol)
+        return m.Resolve(symbol), nil
+}
+
+// Overrides returns the admin mappings sorted by Upbit symbol
+func (m *SymbolMapper) Overrides() []models.SymbolMapping {
+        m.ensureLoaded()
+        
+        m.mutex.RLock()
+        defer m.mutex.RUnlock()
+        
+        result := make([]models.SymbolMapping, 0, len(m.overrides))
+        for _, mapping := range m.overrides {
+                result = append(result, mapping)
+        }
+        sort.Slice(result, func(i, j int) bool {
+                return result[i].UpbitSymbol < result[j].UpbitSymbol
+        })
+        return result
+}
+
+// Stats returns the number of contract mappings and the time of the last successful sync
+func (m *SymbolMapper) Stats() (contracts int, lastSync time.Time) {
+        m.mutex.RLock()
+        defer m.mutex.RUnlock()
+        return len(m.contracts), m.lastSync
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+        "testing"
+        "upbit-bitget-trading-bot/models"
+)
+
+func testContracts() []ContractInfo {
+        return []ContractInfo{
+                {Symbol: "TOSHIUSDT", BaseCoin: "TOSHI", QuoteCoin: "USDT", SymbolStatus: "normal"},
+                {Symbol: "1000SATSUSDT", BaseCoin: "1000SATS", QuoteCoin: "USDT", SymbolStatus: "normal"},
+                {Symbol: "1MBABYDOGEUSDT", BaseCoin: "1MBABYDOGE", QuoteCoin: "USDT", SymbolStatus: "normal"},
+                {Symbol: "PEPEUSDT", BaseCoin: "PEPE", QuoteCoin: "USDT", SymbolStatus: "normal"},
+                {Symbol: "1000PEPEUSDT", BaseCoin: "1000PEPE", QuoteCoin: "USDT", SymbolStatus: "normal"},
+                {Symbol: "OLDUSDT", BaseCoin: "OLD", QuoteCoin: "USDT", SymbolStatus: "off"},
+                {Symbol: "BTCUSD", BaseCoin: "BTC", QuoteCoin: "USD", SymbolStatus: "normal"},
+        }
+}
+
+func TestMappingsFromContracts(t *testing.T) {
+        mappings := mappingsFromContracts(testContracts())
+        
+        cases := []struct {
+                upbit      string
+                bitget     string
+                multiplier float64
+        }{
+                {"TOSHI", "TOSHIUSDT", 1},
+                {"SATS", "1000SATSUSDT", 1000},
+                {"1000SATS", "1000SATSUSDT", 1},
+                {"BABYDOGE", "1MBABYDOGEUSDT", 1e6},
+                {"PEPE", "PEPEUSDT", 1}, // Direct contract wins over the scaled one
+        }
+        for _, tc := range cases {
+                mapping, ok := mappings[tc.upbit]
+                if !ok {
+                        t.Errorf("%s: no mapping", tc.upbit)
+                        continue
+                }
+                if mapping.BitgetSymbol != tc.bitget || mapping.Multiplier != tc.multiplier {
+                        t.Errorf("%s: got %s x%g, want %s x%g", tc.upbit, mapping.BitgetSymbol, mapping.Multiplier, tc.bitget, tc.multiplier)
+                }
+        }
+        
+        for _, missing := range []string{"OLD", "BTC"} {
+                if _, ok := mappings[missing]; ok {
+                        t.Errorf("%s should not be mapped (contract off or not USDT-margined)", missing)
+                }
+        }
+}
+
+func TestSymbolMapperResolvePrecedence(t *testing.T) {
+        mapper := NewSymbolMapper()
+        mapper.fetchContracts = func() ([]ContractInfo, error) { return testContracts(), nil }
+        
+        if decision := mapper.Resolve("NEWCOIN"); decision.BitgetSymbol != "NEWCOINUSDT" || decision.Source != symbolMappingDefault || !decision.Tradeable {
+                t.Errorf("unsynced fallback: got %+v", decision)
+        }
+        
+        if err := mapper.Sync(); err != nil {
+                t.Fatalf("Sync returned error: %v", err)
+        }
+        
+        if decision := mapper.Resolve("sats"); decision.BitgetSymbol != "1000SATSUSDT" || decision.Multiplier != 1000 || decision.Source != models.SymbolMappingContracts {
+                t.Errorf("contract mapping: got %+v", decision)
+        }
+        
+        // Overrides are set directly: SetOverride needs the database
+        mapper.overrides["SATS"] = models.SymbolMapping{UpbitSymbol: "SATS", BitgetSymbol: "SATSUSDT", Multiplier: 1, Source: models.SymbolMappingAdmin}
+        mapper.overrides["TOSHI"] = models.SymbolMapping{UpbitSymbol: "TOSHI", Source: models.SymbolMappingAdmin, Disabled: true, Note: "ticker collision"}
+        
+        if decision := mapper.Resolve("SATS"); decision.BitgetSymbol != "SATSUSDT" || decision.Source != models.SymbolMappingAdmin {
+                t.Errorf("admin override should win: got %+v", decision)
+        }
+        if decision := mapper.Resolve("TOSHI"); decision.Tradeable {
+                t.Errorf("disabled ticker should not be tradeable: got %+v", decision)
+        }
+        if decision := mapper.Resolve("NEWCOIN"); decision.BitgetSymbol != "NEWCOINUSDT" || decision.Reason != "not in Bitget contract list, trying default" {
+                t.Errorf("synced fallback: got %+v", decision)
+        }
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+        "errors"
+        "fmt"
+        "log"
+        "strconv"
+        "upbit-bitget-trading-bot/database"
+        "upbit-bitget-trading-bot/models"
+
+        "gorm.io/gorm"
+)
+
+// buildTranches splits a new position into the user's take profit ladder steps
+// Sizes are rounded down to the contract's size step and a step below the contract minimum is merged into the
+// next one, so the stored quantities are exactly what is placed on Bitget (spec is nil without contract metadata).
+// When the steps add up to 100% the last one takes whatever is left, so rounding never leaves a remainder open.
+func buildTranches(position *models.Position, steps []models.TakeProfitStep, spec *ContractSpec) {
+        position.Tranches = nil
+        remaining := position.Quantity
+        total := 0.0
+        carried, carriedPercent := 0.0, 0.0 // Steps too small to place on their own
+        for i, step := range steps {
+                total += step.SizePercent
+                last := i == len(steps)-1
+                unrounded := position.Quantity*step.SizePercent/100 + carried
+                percent := step.SizePercent + carriedPercent
+                
+                quantity := unrounded
+                if last && total >= 100 {
+                        quantity = remaining
+                }
+                if spec != nil {
+                        quantity = spec.RoundSize(quantity)
+                }
+                
+                if quantity <= 0 || (spec != nil && quantity < spec.MinTradeNum) {
+                        if !last {
+                                carried, carriedPercent = unrounded, percent
+                                continue
+                        }
+                        // Nothing comes after the last step: a closing remainder joins the previous tranche,
+                        // otherwise it is left to the position's other exits
+                        if n := len(position.Tranches); n > 0 && total >= 100 && quantity > 0 {
+                                position.Tranches[n-1].Quantity += quantity
+                                position.Tranches[n-1].SizePercent += percent
+                        }
+                        break
+                }
+                carried, carriedPercent = 0, 0
+                remaining -= quantity
+                
+                position.Tranches = append(position.Tranches, models.PositionTranche{
+                        Step:         len(position.Tranches) + 1,
+                        SizePercent:  percent,
+                        Quantity:     quantity,
+                        TriggerPrice: position.EntryPrice * (1 + step.GainPercent/100),
+                        Status:       models.TranchePending,
+                })
+        }
+        if len(position.Tranches) < len(steps) {
+                log.Printf("🪜 Take profit ladder of %s reduced to %d steps to meet the contract minimum", position.Symbol, len(position.Tranches))
+        }
+}
+
+// placeTrancheOrders places a Bitget partial take profit for every pending tranche without one
+// Tranches whose order fails are closed by the position monitor when their price is reached
+func placeTrancheOrders(bitgetAPI Exchange, position *models.Position) error {
+        var errs []error
+        for _, tranche := range position.PendingTranches() {
+                if tranche.OrderID != "" {
+                        continue
+                }
+                orderResp, err := bitgetAPI.PlacePartialTakeProfit(position.Symbol, tranche.Quantity, tranche.TriggerPrice)
+                if err != nil {
+                        errs = append(errs, fmt.Errorf("step %d: %w", tranche.Step, err))
+                        continue
+                }
+                tranche.OrderID = orderResp.OrderID
+        }
+        return errors.Join(errs...)
+}
+
+// processTranches records the take profit tranches Bitget filled and closes the due tranches that have no order
+// exchangeSize is the position size on Bitget (0 when it is gone); Bitget orders are only looked up once it
+// dropped below the recorded quantity. With price 0 nothing is closed, only fills are read back.
+// It returns how many tranches filled; the caller saves the position.
+func (te *TradingEngine) processTranches(position *models.Position, bitgetAPI Exchange, exchangeSize float64, price float64) int {
+        pending := position.PendingTranches()
+        if len(pending) == 0 {
+                return 0
+        }
+        
+        reduced := exchangeSize < position.Quantity*(1-1e-6)
+        filled := 0
+        for _, tranche := range pending {
+                if tranche.OrderID != "" {
+                        if !reduced {
+                                continue
+                        }
+                        status, err := bitgetAPI.GetPlanOrderStatus(position.Symbol, tranche.OrderID)
+                        if err != nil {
+                                log.Printf("⚠️ %v", err)
+                                continue
+                        }
+                        switch status {
+                        case "executed":
+                                te.fillTranche(position, tranche, tranche.TriggerPrice, true)
+                                filled++
+                                continue
+                        case "live":
+                                continue
+                        }
+                        // Cancelled or failed on Bitget: the monitor closes this step from now on
+                        log.Printf("⚠️ Take profit step %d of position %d is %s on Bitget, closing it locally", tranche.Step, position.ID, status)
+                        tranche.OrderID = ""
+                        te.saveTranche(tranche)
+                }
+                
+                if price <= 0 || price < tranche.TriggerPrice {
+                        continue
+                }
+                if te.closeTranche(position, tranche, bitgetAPI, price) {
+                        filled++
+                }
+        }
+        
+        // The trailing stop only covers what is left
+        if filled > 0 && price > 0 && position.TrailingOrderID != "" {
+                if err := syncTrailingOrder(bitgetAPI, position); err != nil {
+                        log.Printf("⚠️ Failed to resize trailing stop of position %d: %v", position.ID, err)
+                }
+        }
+        return filled
+}
+
+// closeTranche closes a due tranche with a reduce-only market order; a failure is retried on the next cycle
+func (te *TradingEngine) closeTranche(position *models.Position, tranche *models.PositionTranche, bitgetAPI Exchange, price float64) bool {
+        log.Printf("🪜 Take profit step %d triggered for position %d (%s): %.6f >= %.6f",
+                tranche.Step, position.ID, position.Symbol, price, tranche.TriggerPrice)
+        
+        orderResp, err := bitgetAPI.ReducePosition(position.Symbol, tranche.Quantity)
+        if err != nil {
+                log.Printf("❌ Failed to close take profit step %d of position %d: %v", tranche.Step, position.ID, err)
+                return false
+        }
+        if orderResp.Size > 0 {
+                tranche.Quantity = orderResp.Size
+        }
+        
+        fillPrice := price
+        if detail, err := bitgetAPI.GetOrderDetail(position.Symbol, orderResp.OrderID); err == nil {
+                if avg, err := strconv.ParseFloat(detail.PriceAvg, 64); err == nil && avg > 0 {
+                        fillPrice = avg
+                }
+        }
+        
+        te.fillTranche(position, tranche, fillPrice, false)
+        return true
+}
+
+// fillTranche records a filled tranche, takes it off the open quantity and tells the user its realized P&L
+func (te *TradingEngine) fillTranche(position *models.Position, tranche *models.PositionTranche, price float64, onExchange bool) {
+        pnl := tranche.MarkFilled(position.EntryPrice, price)
+        position.Quantity -= tranche.Quantity
+        if position.Quantity < 0 {
+                position.Quantity = 0
+        }
+        position.RealizedPNL += pnl
+        te.saveTranche(tranche)
+        
+        log.Printf("✅ Take profit step %d of position %d filled at %.6f: %.4f closed, P&L %.2f",
+                tranche.Step, position.ID, price, tranche.Quantity, pnl)
+        te.telegramBot.SendTrancheNotification(position.User.TelegramID, position, tranche, onExchange)
+}
+
+func (te *TradingEngine) saveTranche(tranche *models.PositionTranche) {
+        err := database.WithDB(func(db *gorm.DB) error {
+                return db.Save(tranche).Error
+        })
+        if err != nil {
+                log.Printf("⚠️ Failed to save take profit step %d of position %d: %v", tranche.Step, tranche.PositionID, err)
+        }
+}
+
+// ladderClosed reports whether the filled tranches closed the whole position
+func ladderClosed(position *models.Position) bool {
+        return len(position.Tranches) > 0 && position.Quantity <= 1e-9
+}
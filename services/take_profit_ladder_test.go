@@ -0,0 +1,144 @@
+package services
+
+import (
+        "math"
+        "testing"
+        "time"
+        "upbit-bitget-trading-bot/models"
+)
+
+func TestParseTakeProfitLadder(t *testing.T) {
+        steps, err := models.ParseTakeProfitLadder(" 30@50, 30%@+100% ")
+        if err != nil || len(steps) != 2 || steps[0] != (models.TakeProfitStep{SizePercent: 30, GainPercent: 50}) ||
+                steps[1] != (models.TakeProfitStep{SizePercent: 30, GainPercent: 100}) {
+                t.Fatalf("steps = %+v, err %v", steps, err)
+        }
+        if formatted := models.FormatTakeProfitLadder(steps); formatted != "30@50,30@100" {
+                t.Errorf("formatted = %q", formatted)
+        }
+        
+        for _, invalid := range []string{"30", "0@50", "30@-10", "30@100,30@50", "60@50,50@100"} {
+                if _, err := models.ParseTakeProfitLadder(invalid); err == nil {
+                        t.Errorf("%q parsed", invalid)
+                }
+        }
+}
+
+func TestBuildTranchesRoundsToContract(t *testing.T) {
+        spec := &ContractSpec{Symbol: "NEWUSDT", VolumePlace: 0, SizeMultiplier: 10, MinTradeNum: 40}
+        cases := []struct {
+                name       string
+                ladder     string
+                quantities []float64
+                percents   []float64
+        }{
+                {"rounded down to the step", "30@50,30@100", []float64{70, 70}, []float64{30, 30}},
+                {"small step merged into the next", "10@20,30@50,60@100", []float64{100, 150}, []float64{40, 60}},
+                {"small closing step merged into the previous", "90@50,10@100", []float64{250}, []float64{100}},
+        }
+        for _, tc := range cases {
+                t.Run(tc.name, func(t *testing.T) {
+                        steps, err := models.ParseTakeProfitLadder(tc.ladder)
+                        if err != nil {
+                                t.Fatalf("ParseTakeProfitLadder: %v", err)
+                        }
+                        position := models.Position{Symbol: "NEWUSDT", EntryPrice: 2, Quantity: 250}
+                        buildTranches(&position, steps, spec)
+                        
+                        if len(position.Tranches) != len(tc.quantities) {
+                                t.Fatalf("tranches = %+v", position.Tranches)
+                        }
+                        for i, tranche := range position.Tranches {
+                                if tranche.Step != i+1 || tranche.Quantity != tc.quantities[i] || tranche.SizePercent != tc.percents[i] {
+                                        t.Errorf("tranche %d = %+v, want %g (%g%%)", i+1, tranche, tc.quantities[i], tc.percents[i])
+                                }
+                        }
+                })
+        }
+}
+
+// newLadderPosition is a test position with the 30@50,30@100 ladder of a 250 position entered at 2
+func newLadderPosition(user models.User) models.Position {
+        position := newTestPosition(user, 2)
+        position.TakeProfitPrice = 5
+        buildTranches(&position, []models.TakeProfitStep{{SizePercent: 30, GainPercent: 50}, {SizePercent: 30, GainPercent: 100}}, nil)
+        return position
+}
+
+func TestProcessUserTradePlacesTakeProfitLadder(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setPrice("NEWUSDT", 2)
+        engine, _ := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        user.TakeProfitLadder = "30@50,30@100"
+        
+        if !engine.processUserTrade(user, CoinListing{Symbol: "NEW", Kind: ListingKindNew, DetectedAt: time.Now()}).Opened {
+                t.Fatal("processUserTrade did not open a position")
+        }
+        
+        // 30% of 250 at 3 and at 4, the rest with the fixed take profit
+        plans := fake.partialPlans("NEWUSDT")
+        if len(plans) != 2 || plans[0].size != 75 || plans[0].triggerPrice != 3 || plans[1].size != 75 || plans[1].triggerPrice != 4 {
+                t.Fatalf("partial take profits = %+v", plans)
+        }
+        if fake.plan("NEWUSDT", PlanTypePositionProfit) == nil {
+                t.Error("no take profit for the rest of the position")
+        }
+        
+        fake.setPrice("NEWUSDT", 3.1)
+        if position := fake.position("NEWUSDT"); position == nil || math.Abs(position.size-175) > 1e-9 {
+                t.Errorf("position after the first step = %+v, want 175 left", position)
+        }
+}
+
+func TestUpdatePositionPNLRecordsExchangeTranche(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setPrice("NEWUSDT", 2)
+        fake.openPosition("NEWUSDT", 250, 2)
+        engine, telegram := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        
+        position := newLadderPosition(user)
+        api := fake.exchange()(fake.apiKey, fake.apiSecret, fake.passphrase)
+        if err := placeTrancheOrders(api, &position); err != nil {
+                t.Fatalf("placeTrancheOrders: %v", err)
+        }
+        fake.setPrice("NEWUSDT", 3.2)
+        
+        engine.updatePositionPNL(position)
+        
+        // Bitget filled step 1 around its 3.00 trigger: (3 - 2) * 75
+        if !telegram.sentContaining(user.TelegramID, "KADEMELİ TAKE PROFIT 1/2") ||
+                !telegram.sentContaining(user.TelegramID, "Kademe P&L: $75.00") ||
+                !telegram.sentContaining(user.TelegramID, "Kalan: 175.0000") {
+                t.Errorf("no tranche notification: %v", telegram.sent(user.TelegramID))
+        }
+        if telegram.sentContaining(user.TelegramID, "2/2") {
+                t.Error("second step reported before its price")
+        }
+        if fake.requestCount("/api/v2/mix/order/place-order") != 0 {
+                t.Error("monitor closed a step Bitget already filled")
+        }
+}
+
+func TestUpdatePositionPNLClosesDueTrancheLocally(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setPrice("NEWUSDT", 3.5)
+        fake.openPosition("NEWUSDT", 250, 2)
+        engine, telegram := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        
+        // No Bitget orders: step 1 (at 3) is due, step 2 (at 4) is not
+        engine.updatePositionPNL(newLadderPosition(user))
+        
+        if position := fake.position("NEWUSDT"); position == nil || math.Abs(position.size-175) > 1e-9 {
+                t.Fatalf("position after the local close = %+v, want 175 left", position)
+        }
+        if !telegram.sentContaining(user.TelegramID, "Kademe P&L: $112.50") ||
+                !telegram.sentContaining(user.TelegramID, "Bot (yedek kontrol)") {
+                t.Errorf("no tranche notification: %v", telegram.sent(user.TelegramID))
+        }
+        if !telegram.sentContaining(user.TelegramID, "POZİSYON DURUMU") {
+                t.Errorf("no P&L update for the rest: %v", telegram.sent(user.TelegramID))
+        }
+}
@@ -19,6 +19,10 @@ type TelegramBot struct {
         EncryptionKey string
         UpdateChannel tgbotapi.UpdatesChannel
         upbitMonitor  *UpbitMonitor // For testing purposes
+        symbolMapper  *SymbolMapper // For admin symbol mapping overrides
+        newExchange   ExchangeFactory // Creates the exchange client for a user's credentials
+        tradingEngine *TradingEngine // Set by NewTradingEngine, nil until then
+        adminIDs      map[int64]bool // Telegram IDs allowed to run admin commands
         
         // Per-user rate limiting to prevent API overload
         userRateLimits map[int64]*time.Ticker
@@ -42,7 +46,7 @@ func min(a, b int) int {
 }
 
 // NewTelegramBot creates a new Telegram bot instance
-func NewTelegramBot(token, encryptionKey string, upbitMonitor *UpbitMonitor) (*TelegramBot, error) {
+func NewTelegramBot(token, encryptionKey string, adminIDs []int64, upbitMonitor *UpbitMonitor, symbolMapper *SymbolMapper) (*TelegramBot, error) {
         bot, err := tgbotapi.NewBotAPI(token)
         if err != nil {
                 return nil, fmt.Errorf("failed to create bot: %w", err)
@@ -57,11 +61,19 @@ func NewTelegramBot(token, encryptionKey string, upbitMonitor *UpbitMonitor) (*T
         
         updates := bot.GetUpdatesChan(u)
         
+        admins := make(map[int64]bool)
+        for _, id := range adminIDs {
+                admins[id] = true
+        }
+        
         return &TelegramBot{
                 Bot:            bot,
                 EncryptionKey:  encryptionKey,
                 UpdateChannel:  updates,
                 upbitMonitor:   upbitMonitor,
+                symbolMapper:   symbolMapper,
+                newExchange:    NewBitgetExchange,
+                adminIDs:       admins,
                 userRateLimits: make(map[int64]*time.Ticker),
                 rateLimitMutex: sync.RWMutex{},
         }, nil
@@ -196,6 +208,18 @@ func (tb *TelegramBot) handleMessage(message *tgbotapi.Message) {
                 tb.handleTestCommand(chatID, userID)
         case text == "/help" || text == "❓ Yardım":
                 tb.handleHelpCommand(chatID)
+        case text == "/processed":
+                tb.handleProcessedCommand(chatID, userID)
+        case strings.HasPrefix(text, "/reset_processed"):
+                tb.handleResetProcessedCommand(chatID, userID, strings.TrimSpace(strings.TrimPrefix(text, "/reset_processed")))
+        case strings.HasPrefix(text, "/symbol_map"):
+                tb.handleSymbolMapCommand(chatID, userID, strings.Fields(strings.TrimPrefix(text, "/symbol_map")))
+        case text == "/symbol_sync":
+                tb.handleSymbolSyncCommand(chatID, userID)
+        case strings.HasPrefix(text, "/decisions"):
+                tb.handleDecisionsCommand(chatID, userID, strings.TrimSpace(strings.TrimPrefix(text, "/decisions")))
+        case strings.HasPrefix(text, "/latency"):
+                tb.handleLatencyCommand(chatID, userID, strings.TrimSpace(strings.TrimPrefix(text, "/latency")))
         case state.State == "awaiting_api_key":
                 tb.handleAPIKeyInput(chatID, userID, text)
         case state.State == "awaiting_api_secret":
@@ -210,10 +234,22 @@ func (tb *TelegramBot) handleMessage(message *tgbotapi.Message) {
                 tb.handleUpdatePassphraseInput(chatID, userID, text)
         case state.State == "awaiting_trade_amount":
                 tb.handleTradeAmountInput(chatID, userID, text)
+        case state.State == "awaiting_bithumb_amount":
+                tb.handleBithumbAmountInput(chatID, userID, text)
         case state.State == "awaiting_leverage":
                 tb.handleLeverageInput(chatID, userID, text)
         case state.State == "awaiting_take_profit":
                 tb.handleTakeProfitInput(chatID, userID, text)
+        case state.State == "awaiting_stop_loss":
+                tb.handleStopLossInput(chatID, userID, text)
+        case state.State == "awaiting_trail_activation":
+                tb.handleTrailingInput(chatID, userID, "activation", text)
+        case state.State == "awaiting_trail_callback":
+                tb.handleTrailingInput(chatID, userID, "callback", text)
+        case state.State == "awaiting_tp_ladder":
+                tb.handleTakeProfitLadderInput(chatID, userID, text)
+        case state.State == "awaiting_entry_offset":
+                tb.handleEntryOffsetInput(chatID, userID, text)
         default:
                 tb.sendMessageWithMenu(chatID, "❓ Bilinmeyen komut. Menüden istediğiniz komutu seçin:")
         }
@@ -254,6 +290,24 @@ func (tb *TelegramBot) handleCallbackQuery(callbackQuery *tgbotapi.CallbackQuery
         case strings.HasPrefix(data, "tp_"):
                 takeProfit := strings.TrimPrefix(data, "tp_")
                 tb.handleTakeProfitSelectionCallback(chatID, userID, takeProfit)
+        case data == "set_stop_loss":
+                tb.handleStopLossCallback(chatID, userID)
+        case strings.HasPrefix(data, "sl_mode_"):
+                tb.handleStopLossModeCallback(chatID, userID, strings.TrimPrefix(data, "sl_mode_"))
+        case strings.HasPrefix(data, "sl_"):
+                tb.handleStopLossSelectionCallback(chatID, userID, strings.TrimPrefix(data, "sl_"))
+        case data == "set_exit_mode":
+                tb.handleExitModeCallback(chatID, userID)
+        case strings.HasPrefix(data, "exit_mode_"):
+                tb.handleExitModeSelectionCallback(chatID, userID, strings.TrimPrefix(data, "exit_mode_"))
+        case strings.HasPrefix(data, "trail_act_"):
+                tb.handleTrailingSelectionCallback(chatID, userID, "activation", strings.TrimPrefix(data, "trail_act_"))
+        case strings.HasPrefix(data, "trail_cb_"):
+                tb.handleTrailingSelectionCallback(chatID, userID, "callback", strings.TrimPrefix(data, "trail_cb_"))
+        case data == "set_tp_ladder":
+                tb.handleTakeProfitLadderCallback(chatID, userID)
+        case strings.HasPrefix(data, "ladder_"):
+                tb.handleTakeProfitLadderSelectionCallback(chatID, userID, strings.TrimPrefix(data, "ladder_"))
         case strings.HasPrefix(data, "test_"):
                 coinSymbol := strings.TrimPrefix(data, "test_")
                 tb.handleTestCoinCallback(chatID, userID, coinSymbol)
@@ -264,6 +318,24 @@ func (tb *TelegramBot) handleCallbackQuery(callbackQuery *tgbotapi.CallbackQuery
                 tb.sendMessage(chatID, "❌ Test iptali edildi.")
         case data == "toggle_active":
                 tb.handleToggleActiveCallback(chatID, userID)
+        case data == "set_market_filters":
+                tb.handleMarketFiltersCallback(chatID, userID)
+        case strings.HasPrefix(data, "filter_"):
+                tb.handleMarketFilterToggleCallback(chatID, userID, strings.TrimPrefix(data, "filter_"))
+        case data == "set_risk_policy":
+                tb.handleRiskPolicyCallback(chatID)
+        case strings.HasPrefix(data, "risk_"):
+                tb.handleRiskPolicySelectionCallback(chatID, userID, strings.TrimPrefix(data, "risk_"))
+        case data == "set_entry_mode":
+                tb.handleEntryModeCallback(chatID, userID)
+        case strings.HasPrefix(data, "entry_"):
+                tb.handleEntryModeSelectionCallback(chatID, userID, strings.TrimPrefix(data, "entry_"))
+        case data == "set_exchanges":
+                tb.handleExchangesCallback(chatID, userID)
+        case strings.HasPrefix(data, "exchange_"):
+                tb.handleExchangeToggleCallback(chatID, userID, strings.TrimPrefix(data, "exchange_"))
+        case strings.HasPrefix(data, "bithumb_amount_"):
+                tb.handleBithumbAmountSelectionCallback(chatID, userID, strings.TrimPrefix(data, "bithumb_amount_"))
         }
 }
 
@@ -404,10 +476,24 @@ func (tb *TelegramBot) handleSettingsCommand(chatID int64, userID int64) {
 💰 Trade Amount: %.0f USDT
 🔧 Leverage: %dx
 📈 Take Profit: %.0f%%
+🛑 Stop Loss: %s
+🎯 Çıkış: %s
+🪜 TP Kademeleri: %s
 %s Status: %s
 
+🏛️ Borsalar: %s
+🏦 Marketler: %s
+🏷️ Türler: %s
+⏱️ Giriş zamanı: %s
+
+🚨 *Risk Politikası:*
+⛔ Listeden çıkarma: %s
+⚠️ Yatırım uyarısı: %s
+
 🔧 *Ayarları Değiştir:*`, 
-                user.TradeAmount, user.Leverage, user.TakeProfitPercentage, statusEmoji, statusText)
+                user.TradeAmount, user.Leverage, user.TakeProfitPercentage, stopLossLabel(user), exitModeLabel(user), takeProfitLadderLabel(user), statusEmoji, statusText,
+                exchangeSummary(user), marketFilterSummary(user), listingTypeSummary(user), entryModeLabel(user),
+                riskActionLabel(user.RiskAction(true)), riskActionLabel(user.RiskAction(false)))
         
         keyboard := tgbotapi.NewInlineKeyboardMarkup(
                 tgbotapi.NewInlineKeyboardRow(
@@ -416,8 +502,23 @@ func (tb *TelegramBot) handleSettingsCommand(chatID int64, userID int64) {
                 ),
                 tgbotapi.NewInlineKeyboardRow(
                         tgbotapi.NewInlineKeyboardButtonData("📈 Take Profit", "set_take_profit"),
+                        tgbotapi.NewInlineKeyboardButtonData("🛑 Stop Loss", "set_stop_loss"),
+                ),
+                tgbotapi.NewInlineKeyboardRow(
+                        tgbotapi.NewInlineKeyboardButtonData("🎯 Çıkış Modu", "set_exit_mode"),
                         tgbotapi.NewInlineKeyboardButtonData("🔄 Aktif/Pasif", "toggle_active"),
                 ),
+                tgbotapi.NewInlineKeyboardRow(
+                        tgbotapi.NewInlineKeyboardButtonData("🪜 TP Kademeleri", "set_tp_ladder"),
+                ),
+                tgbotapi.NewInlineKeyboardRow(
+                        tgbotapi.NewInlineKeyboardButtonData("🏦 Market Filtreleri", "set_market_filters"),
+                        tgbotapi.NewInlineKeyboardButtonData("🚨 Risk Politikası", "set_risk_policy"),
+                ),
+                tgbotapi.NewInlineKeyboardRow(
+                        tgbotapi.NewInlineKeyboardButtonData("⏱️ Giriş Zamanı", "set_entry_mode"),
+                        tgbotapi.NewInlineKeyboardButtonData("🏛️ Borsalar", "set_exchanges"),
+                ),
         )
         
         msg := tgbotapi.NewMessage(chatID, text)
@@ -427,20 +528,26 @@ func (tb *TelegramBot) handleSettingsCommand(chatID int64, userID int64) {
 }
 
 // SendTradeNotification sends trading notification to user
-func (tb *TelegramBot) SendTradeNotification(userID int64, coin, positionID string, entryPrice, takeProfitPrice float64, leverage int, amount float64) {
+func (tb *TelegramBot) SendTradeNotification(userID int64, listing CoinListing, positionID string, entryPrice, takeProfitPrice float64, leverage int, amount float64) {
+        takeProfitText := "Trailing stop"
+        if takeProfitPrice > 0 {
+                takeProfitText = fmt.Sprintf("$%.6f (%.0f%%)", takeProfitPrice, ((takeProfitPrice/entryPrice)-1)*100)
+        }
+        
         text := fmt.Sprintf(`🚀 *YENİ POZİSYON AÇILDI*
 
 💰 Coin: %s/USDT
 💵 Miktar: %.0f USDT
 🔧 Leverage: %dx
 📊 Entry Price: $%.6f
-🎯 Take Profit: $%.6f (%.0f%%)
+🎯 Take Profit: %s
 🆔 Pozisyon ID: #%s
 ⏰ %s`, 
-                coin, amount, leverage, entryPrice, takeProfitPrice, 
-                ((takeProfitPrice/entryPrice)-1)*100, positionID, 
+                listing.Symbol, amount, leverage, entryPrice, takeProfitText, positionID, 
                 fmt.Sprintf("%s", "şimdi"))
         
+        text += "\n\n" + formatListingDetails(listing)
+        
         // Add emergency close button
         keyboard := tgbotapi.NewInlineKeyboardMarkup(
                 tgbotapi.NewInlineKeyboardRow(
@@ -454,6 +561,164 @@ func (tb *TelegramBot) SendTradeNotification(userID int64, coin, positionID stri
         tb.Bot.Send(msg)
 }
 
+// formatListingDetails describes where a listing came from (source, market, notice and latency)
+func formatListingDetails(listing CoinListing) string {
+        text := fmt.Sprintf("📡 Kaynak: %s", escapeMarkdown(listing.Source))
+        text += fmt.Sprintf("\n🏛️ Borsa: %s", exchangeLabel(listing.Exchange))
+        if listing.Kind == "" || listing.Kind == ListingKindNew {
+                text += fmt.Sprintf("\n🏷️ Tür: %s", listing.CategoryLabel())
+        }
+        if listing.MarketType != "" {
+                text += fmt.Sprintf("\n🏦 Market: %s", listing.MarketType)
+                if len(listing.Markets) > 1 {
+                        text += fmt.Sprintf(" (%s)", strings.Join(listing.Markets, ", "))
+                }
+        }
+        if listing.AnnouncementURL != "" {
+                text += fmt.Sprintf("\n📰 Duyuru: [#%s](%s)", listing.AnnouncementID, listing.AnnouncementURL)
+        } else if listing.AnnouncementID != "" {
+                text += fmt.Sprintf("\n📰 Duyuru: #%s", escapeMarkdown(listing.AnnouncementID))
+        }
+        if len(listing.Confirmations) > 1 {
+                text += fmt.Sprintf("\n🗳️ Onaylayan kaynaklar: %s", escapeMarkdown(strings.Join(listing.Confirmations, ", ")))
+        }
+        if listing.Confidence > 0 {
+                text += fmt.Sprintf("\n🎯 Sinyal güveni: %%%.0f", listing.Confidence*100)
+        }
+        if !listing.TradingStartsAt.IsZero() {
+                text += fmt.Sprintf("\n🔔 İşlem başlangıcı: %s", listing.TradingStartsAt.In(koreaLocation()).Format("2006-01-02 15:04 MST"))
+        }
+        if !listing.QueuedAt.IsZero() {
+                text += fmt.Sprintf("\n👀 İzleme listesinden girildi (bekleme: %s)", time.Since(listing.QueuedAt).Round(time.Second))
+        }
+        if listing.FromMarketList {
+                if listing.AnnouncementSeen {
+                        text += "\n📋 Market listesinden tespit (duyuru da görüldü)"
+                } else {
+                        text += "\n📋 Market listesinden tespit (duyuru henüz görülmedi)"
+                }
+        }
+        if !listing.AnnouncedAt.IsZero() {
+                text += fmt.Sprintf("\n🕐 Duyuru zamanı: %s", listing.AnnouncedAt.Format("2006-01-02 15:04:05 MST"))
+                text += fmt.Sprintf("\n⚡ Tespit gecikmesi: %s", listing.Latency.Round(time.Second))
+        }
+        return text
+}
+
+// SendEntryScheduledNotification tells a user their entry waits for the listing's trading start
+func (tb *TelegramBot) SendEntryScheduledNotification(userID int64, listing CoinListing, entryAt time.Time) {
+        text := fmt.Sprintf(`⏳ *GİRİŞ PLANLANDI*
+
+💰 Coin: %s/USDT
+⏰ Giriş zamanı: %s
+⌛ Kalan süre: %s
+
+Pozisyon, giriş zamanı geldiğinde ayarlarınızla açılacak.`,
+                listing.Symbol,
+                entryAt.In(koreaLocation()).Format("2006-01-02 15:04:05 MST"),
+                time.Until(entryAt).Round(time.Second))
+        
+        text += "\n\n" + formatListingDetails(listing)
+        
+        msg := tgbotapi.NewMessage(userID, text)
+        msg.ParseMode = "Markdown"
+        tb.Bot.Send(msg)
+}
+
+// SendEntrySkippedNotification tells a user a scheduled entry was not opened and why
+func (tb *TelegramBot) SendEntrySkippedNotification(userID int64, listing CoinListing, reason string) {
+        text := fmt.Sprintf(`⚠️ *GİRİŞ ATLANDI*
+
+💰 Coin: %s/USDT
+📝 Sebep: %s
+
+Pozisyon otomatik açılmadı, isterseniz manuel açabilirsiniz.`,
+                listing.Symbol, reason)
+        
+        text += "\n\n" + formatListingDetails(listing)
+        
+        msg := tgbotapi.NewMessage(userID, text)
+        msg.ParseMode = "Markdown"
+        tb.Bot.Send(msg)
+}
+
+// SendWatchlistQueuedNotification tells a user a coin waits on the watchlist for its Bitget contract
+func (tb *TelegramBot) SendWatchlistQueuedNotification(userID int64, listing CoinListing, bitgetSymbol string, expiresAt time.Time) {
+        text := fmt.Sprintf(`👀 *İZLEME LİSTESİNE ALINDI*
+
+💰 Coin: %s
+🔍 Bitget'te %s vadeli kontratı henüz yok.
+⏰ Son tarih: %s
+
+Kontrat açıldığında pozisyon ayarlarınızla otomatik açılacak.`,
+                listing.Symbol, bitgetSymbol, expiresAt.In(koreaLocation()).Format("2006-01-02 15:04 MST"))
+        
+        text += "\n\n" + formatListingDetails(listing)
+        
+        msg := tgbotapi.NewMessage(userID, text)
+        msg.ParseMode = "Markdown"
+        tb.Bot.Send(msg)
+}
+
+// SendWatchlistResolvedNotification tells a user a watchlist entry ended without a position
+func (tb *TelegramBot) SendWatchlistResolvedNotification(userID int64, entry *models.WatchlistEntry) {
+        var header string
+        switch entry.Status {
+        case models.WatchlistExpired:
+                header = "⌛ *İZLEME SÜRESİ DOLDU*"
+        case models.WatchlistSkipped:
+                header = "🚫 *GİRİŞ YAPILMADI*"
+        default:
+                header = "❌ *İZLEME LİSTESİ GİRİŞİ BAŞARISIZ*"
+        }
+        
+        text := fmt.Sprintf(`%s
+
+💰 Coin: %s
+🕐 Listeye alınma: %s
+📝 Sebep: %s`,
+                header,
+                entry.CoinSymbol,
+                entry.CreatedAt.In(koreaLocation()).Format("2006-01-02 15:04 MST"),
+                escapeMarkdown(entry.Note))
+        
+        tb.sendMessage(userID, text)
+}
+
+// SendRiskEventNotification tells a user about a delisting or investment warning affecting their position
+func (tb *TelegramBot) SendRiskEventNotification(userID int64, listing CoinListing, position *models.Position, failure string) {
+        header := "⚠️ *YATIRIM UYARISI*"
+        if listing.Kind == ListingKindDelisting {
+                header = "⛔ *LİSTEDEN ÇIKARMA DUYURUSU*"
+        }
+        
+        status := "🔔 Pozisyonunuz açık, kontrol etmeniz önerilir."
+        if failure != "" {
+                status = "❌ " + escapeMarkdown(failure)
+        } else if position.Status == models.PositionClosed {
+                status = "✅ Pozisyonunuz otomatik olarak kapatıldı."
+        }
+        
+        text := fmt.Sprintf(`%s
+
+💰 Coin: %s
+📊 Giriş: $%.6f
+📢 Duyuru: %s
+
+%s`,
+                header,
+                position.Symbol,
+                position.EntryPrice,
+                escapeMarkdown(listing.AnnouncementTitle),
+                status)
+        
+        text += "\n\n" + formatListingDetails(listing)
+        
+        msg := tgbotapi.NewMessage(userID, text)
+        msg.ParseMode = "Markdown"
+        tb.Bot.Send(msg)
+}
+
 // SendPNLUpdate sends P&L update to user
 func (tb *TelegramBot) SendPNLUpdate(userID int64, position *models.Position) {
         pnlEmoji := "📉"
@@ -485,6 +750,92 @@ func (tb *TelegramBot) SendPNLUpdate(userID int64, position *models.Position) {
         tb.Bot.Send(msg)
 }
 
+// SendTrancheNotification tells a user a take profit ladder step closed part of a position
+// onExchange is true when Bitget's partial take profit closed it (filled around the trigger price), false when the bot did
+func (tb *TelegramBot) SendTrancheNotification(userID int64, position *models.Position, tranche *models.PositionTranche, onExchange bool) {
+        closedBy := "Bot (yedek kontrol)"
+        priceText := fmt.Sprintf("$%.6f", tranche.FillPrice)
+        if onExchange {
+                closedBy = "Bitget TP emri"
+                priceText = "~" + priceText
+        }
+        
+        text := fmt.Sprintf(`🪜 *KADEMELİ TAKE PROFIT %d/%d*
+
+💰 Coin: %s
+📦 Kapatılan: %.4f (%g%%)
+📊 Giriş: $%.6f | Çıkış: %s
+💵 Kademe P&L: $%.2f
+💼 Toplam gerçekleşen: $%.2f
+📦 Kalan: %.4f
+⚙️ Kapatan: %s`,
+                tranche.Step, len(position.Tranches),
+                position.Symbol,
+                tranche.Quantity, tranche.SizePercent,
+                position.EntryPrice, priceText,
+                tranche.RealizedPNL,
+                position.RealizedPNL,
+                position.Quantity,
+                closedBy)
+        
+        tb.sendMessage(userID, text)
+}
+
+// SendStopLossNotification tells a user a position was closed by its stop loss
+// onExchange is true when Bitget's stop order closed it, false when the position monitor did
+func (tb *TelegramBot) SendStopLossNotification(userID int64, position *models.Position, onExchange bool) {
+        tb.sendExitNotification(userID, "🛑 *STOP LOSS TETİKLENDİ*",
+                fmt.Sprintf("🛡️ Stop: $%.6f (%s)", position.StopLossPrice, stopLossLabel(&position.User)),
+                position, onExchange)
+}
+
+// SendTrailingStopNotification tells a user a position was closed by its trailing stop
+func (tb *TelegramBot) SendTrailingStopNotification(userID int64, position *models.Position, onExchange bool) {
+        tb.sendExitNotification(userID, "📉 *TRAILING STOP TETİKLENDİ*",
+                fmt.Sprintf("🏔️ Zirve: $%.6f | Geri çekilme: %g%%", position.HighWaterPrice, position.TrailingCallbackRate),
+                position, onExchange)
+}
+
+// sendExitNotification formats the message of a position closed by a stop
+// onExchange is true when Bitget's plan order closed it, false when the bot did
+func (tb *TelegramBot) sendExitNotification(userID int64, header string, levels string, position *models.Position, onExchange bool) {
+        closedBy := "Bot (yedek kontrol)"
+        if onExchange {
+                closedBy = "Bitget stop emri"
+        }
+        
+        pnlEmoji := "📉"
+        if position.CurrentPNL > 0 {
+                pnlEmoji = "📈"
+        }
+        
+        text := fmt.Sprintf(`%s
+
+💰 Coin: %s
+📊 Giriş: $%.6f | Çıkış: $%.6f
+%s
+💸 P&L: $%.2f
+%s ROE: %.2f%%
+⚙️ Kapatan: %s
+⏰ Pozisyon süresi: %s`,
+                header,
+                position.Symbol,
+                position.EntryPrice, position.CurrentPrice,
+                levels,
+                position.CurrentPNL,
+                pnlEmoji, position.ROE,
+                closedBy,
+                time.Since(position.OpenedAt).Round(time.Second).String())
+        
+        tb.sendMessage(userID, text)
+}
+
+// escapeMarkdown escapes characters that break Telegram's legacy Markdown parse mode
+func escapeMarkdown(text string) string {
+        replacer := strings.NewReplacer("_", "\\_", "*", "\\*", "`", "\\`", "[", "\\[")
+        return replacer.Replace(text)
+}
+
 // Helper methods
 func (tb *TelegramBot) sendMessage(chatID int64, text string) {
         msg := tgbotapi.NewMessage(chatID, text)
@@ -553,6 +904,21 @@ func (tb *TelegramBot) clearUserState(userID int64) {
         delete(userStates, userID)
 }
 
+// isAdmin checks whether the Telegram user may run admin commands
+func (tb *TelegramBot) isAdmin(userID int64) bool {
+        return tb.adminIDs[userID]
+}
+
+// requireAdmin replies with an error and returns false for non-admin users
+func (tb *TelegramBot) requireAdmin(chatID int64, userID int64) bool {
+        if tb.isAdmin(userID) {
+                return true
+        }
+        log.Printf("⛔ Non-admin user %d tried to run an admin command", userID)
+        tb.sendMessage(chatID, "⛔ Bu komut sadece adminler içindir.")
+        return false
+}
+
 // Additional handlers for commands
 func (tb *TelegramBot) handleStatusCommand(chatID int64, userID int64) {
         user, err := tb.getUser(userID)
@@ -563,7 +929,7 @@ func (tb *TelegramBot) handleStatusCommand(chatID int64, userID int64) {
         
         // Get user positions
         var positions []models.Position
-        err = database.DB.Where("user_id = ? AND status = ?", user.ID, models.PositionOpen).Find(&positions).Error
+        err = database.DB.Preload("Tranches").Where("user_id = ? AND status = ?", user.ID, models.PositionOpen).Find(&positions).Error
         if err != nil {
                 tb.sendMessage(chatID, "❌ Pozisyonlar yüklenirken hata oluştu.")
                 return
@@ -576,13 +942,40 @@ func (tb *TelegramBot) handleStatusCommand(chatID int64, userID int64) {
         
         text := "📊 *Aktif Pozisyonlarınız:*\n\n"
         for _, pos := range positions {
-                text += fmt.Sprintf("💰 %s\n📊 Entry: $%.6f\n🎯 TP: $%.6f\n💵 P&L: $%.2f\n\n", 
-                        pos.Symbol, pos.EntryPrice, pos.TakeProfitPrice, pos.CurrentPNL)
+                text += fmt.Sprintf("💰 %s\n📊 Entry: $%.6f\n%s\n💵 P&L: $%.2f\n", 
+                        pos.Symbol, pos.EntryPrice, positionExitLine(&pos), pos.CurrentPNL)
+                if len(pos.Tranches) > 0 {
+                        text += positionTrancheLine(&pos) + "\n"
+                }
+                text += "\n"
         }
         
         tb.sendMessage(chatID, text)
 }
 
+// positionExitLine describes the profit exit of a position: the fixed target, or the trail and its high-water mark
+func positionExitLine(position *models.Position) string {
+        if position.TrailingCallbackRate <= 0 {
+                return fmt.Sprintf("🎯 TP: $%.6f", position.TakeProfitPrice)
+        }
+        if position.TrailingArmed() {
+                return fmt.Sprintf("📉 Trailing: aktif, zirve $%.6f, stop $%.6f (%g%%)",
+                        position.HighWaterPrice, position.TrailingStopPrice(), position.TrailingCallbackRate)
+        }
+        return fmt.Sprintf("📉 Trailing: $%.6f'da aktifleşir, zirve $%.6f (%g%%)",
+                position.TrailingActivationPrice, position.HighWaterPrice, position.TrailingCallbackRate)
+}
+
+// positionTrancheLine summarizes the take profit ladder of a position: filled steps, realized P&L and the next step
+func positionTrancheLine(position *models.Position) string {
+        filled := len(position.Tranches) - len(position.PendingTranches())
+        line := fmt.Sprintf("🪜 Kademeler: %d/%d, gerçekleşen $%.2f", filled, len(position.Tranches), position.RealizedPNL)
+        if pending := position.PendingTranches(); len(pending) > 0 {
+                line += fmt.Sprintf(", sıradaki $%.6f (%g%%)", pending[0].TriggerPrice, pending[0].SizePercent)
+        }
+        return line
+}
+
 func (tb *TelegramBot) handleBalanceCommand(chatID int64, userID int64) {
         user, err := tb.getUser(userID)
         if err != nil {
@@ -597,7 +990,7 @@ func (tb *TelegramBot) handleBalanceCommand(chatID int64, userID int64) {
                 return
         }
         
-        bitgetAPI := NewBitgetAPI(apiKey, apiSecret, passphrase)
+        bitgetAPI := tb.newExchange(apiKey, apiSecret, passphrase)
         balances, err := bitgetAPI.GetAccountBalance()
         if err != nil {
                 tb.sendMessage(chatID, "❌ Bakiye bilgisi alınamadı. API anahtarlarınızı kontrol edin.")
@@ -698,6 +1091,263 @@ func (tb *TelegramBot) handleHelpCommand(chatID int64) {
         tb.sendMessageWithMenu(chatID, helpText)
 }
 
+// handleProcessedCommand lists persisted listing events (admin only)
+func (tb *TelegramBot) handleProcessedCommand(chatID int64, userID int64) {
+        if !tb.requireAdmin(chatID, userID) {
+                return
+        }
+        
+        if tb.upbitMonitor == nil {
+                tb.sendMessage(chatID, "❌ Upbit monitor mevcut değil.")
+                return
+        }
+        
+        events, err := tb.upbitMonitor.GetProcessedCoins()
+        if err != nil {
+                tb.sendMessage(chatID, fmt.Sprintf("❌ İşlenmiş coinler alınamadı: %v", err))
+                return
+        }
+        
+        if len(events) == 0 {
+                tb.sendMessage(chatID, "📭 İşlenmiş coin bulunmuyor.")
+                return
+        }
+        
+        text := fmt.Sprintf("🗂 *İşlenmiş Coinler* (%d)\n\n", len(events))
+        for i, event := range events {
+                if i >= 30 {
+                        text += fmt.Sprintf("... ve %d tane daha\n", len(events)-i)
+                        break
+                }
+                if event.ID == 0 {
+                        // In-memory fallback entry (database unavailable)
+                        text += fmt.Sprintf("• %s\n", event.Symbol)
+                        continue
+                }
+                text += fmt.Sprintf("• %s | #%s | %s | %s\n",
+                        event.Symbol, escapeMarkdown(event.AnnouncementID), escapeMarkdown(event.Source), event.DetectedAt.Format("2006-01-02 15:04"))
+        }
+        text += "\n🧹 Sıfırlamak için: /reset\\_processed SYMBOL veya /reset\\_processed all"
+        
+        tb.sendMessage(chatID, text)
+}
+
+// handleResetProcessedCommand clears one or all processed coins so they can be detected again (admin only)
+func (tb *TelegramBot) handleResetProcessedCommand(chatID int64, userID int64, arg string) {
+        if !tb.requireAdmin(chatID, userID) {
+                return
+        }
+        
+        if tb.upbitMonitor == nil {
+                tb.sendMessage(chatID, "❌ Upbit monitor mevcut değil.")
+                return
+        }
+        
+        if arg == "" {
+                tb.sendMessage(chatID, "ℹ️ Kullanım: /reset\\_processed SYMBOL veya /reset\\_processed all")
+                return
+        }
+        
+        symbol := strings.ToUpper(arg)
+        if strings.EqualFold(arg, "all") {
+                symbol = ""
+        }
+        
+        cleared, deleted, err := tb.upbitMonitor.ClearProcessedCoins(symbol)
+        if err != nil {
+                log.Printf("🧹 Admin %d reset processed coins in memory only (symbol=%q)", userID, symbol)
+                tb.sendMessage(chatID, fmt.Sprintf("⚠️ Bellekten %d kayıt silindi, veritabanı temizlenemedi: %v", cleared, err))
+                return
+        }
+        
+        log.Printf("🧹 Admin %d reset processed coins (symbol=%q)", userID, symbol)
+        if symbol == "" {
+                tb.sendMessage(chatID, fmt.Sprintf("✅ Tüm işlenmiş coinler sıfırlandı (%d kayıt silindi).", deleted))
+        } else {
+                tb.sendMessage(chatID, fmt.Sprintf("✅ %s sıfırlandı (%d kayıt silindi).", symbol, deleted))
+        }
+}
+
+// handleSymbolMapCommand shows or overrides Upbit -> Bitget symbol mappings (admin only)
+// /symbol_map                            list admin overrides
+// /symbol_map SYMBOL                     show the mapping decision
+// /symbol_map SYMBOL BITGETSYMBOL [MULT]  set an override
+// /symbol_map SYMBOL off                 never trade the ticker
+// /symbol_map SYMBOL reset               remove the override
+func (tb *TelegramBot) handleSymbolMapCommand(chatID int64, userID int64, args []string) {
+        if !tb.requireAdmin(chatID, userID) {
+                return
+        }
+        
+        if tb.symbolMapper == nil {
+                tb.sendMessage(chatID, "❌ Sembol eşleştirme mevcut değil.")
+                return
+        }
+        
+        if len(args) == 0 {
+                contracts, lastSync := tb.symbolMapper.Stats()
+                syncText := "henüz yapılmadı"
+                if !lastSync.IsZero() {
+                        syncText = lastSync.In(koreaLocation()).Format("2006-01-02 15:04 MST")
+                }
+                
+                text := fmt.Sprintf("🗺️ *Sembol Eşleştirme*\n\n📋 Kontrat eşleştirmesi: %d\n🔄 Son senkron: %s\n", contracts, syncText)
+                overrides := tb.symbolMapper.Overrides()
+                if len(overrides) == 0 {
+                        text += "\n✏️ Admin override yok.\n"
+                } else {
+                        text += fmt.Sprintf("\n✏️ *Admin override* (%d)\n", len(overrides))
+                        for _, mapping := range overrides {
+                                if mapping.Disabled {
+                                        text += fmt.Sprintf("• %s → ⛔ kapalı\n", mapping.UpbitSymbol)
+                                } else {
+                                        text += fmt.Sprintf("• %s → %s x%g\n", mapping.UpbitSymbol, mapping.BitgetSymbol, mapping.Multiplier)
+                                }
+                        }
+                }
+                text += "\nℹ️ Kullanım: /symbol\\_map SYMBOL, /symbol\\_map SYMBOL BITGETSYMBOL \\[çarpan], /symbol\\_map SYMBOL off, /symbol\\_map SYMBOL reset"
+                tb.sendMessage(chatID, text)
+                return
+        }
+        
+        symbol := strings.ToUpper(args[0])
+        var decision SymbolDecision
+        var err error
+        
+        switch {
+        case len(args) == 1:
+                decision = tb.symbolMapper.Resolve(symbol)
+        case strings.EqualFold(args[1], "off"):
+                decision, err = tb.symbolMapper.SetOverride(symbol, "", 1, strings.Join(args[2:], " "), userID)
+        case strings.EqualFold(args[1], "reset"):
+                decision, err = tb.symbolMapper.ClearOverride(symbol, userID)
+        default:
+                multiplier := 1.0
+                if len(args) > 2 {
+                        multiplier, err = strconv.ParseFloat(args[2], 64)
+                        if err != nil || multiplier <= 0 {
+                                tb.sendMessage(chatID, "❌ Geçersiz çarpan. Pozitif bir sayı girin (örn. 1000).")
+                                return
+                        }
+                }
+                bitgetSymbol := strings.ToUpper(args[1])
+                if !strings.HasSuffix(bitgetSymbol, "USDT") {
+                        bitgetSymbol += "USDT"
+                }
+                decision, err = tb.symbolMapper.SetOverride(symbol, bitgetSymbol, multiplier, strings.Join(args[min(3, len(args)):], " "), userID)
+        }
+        
+        if err != nil {
+                tb.sendMessage(chatID, fmt.Sprintf("❌ Eşleştirme güncellenemedi: %v", err))
+                return
+        }
+        
+        tb.sendMessage(chatID, "🗺️ "+escapeMarkdown(decision.String()))
+}
+
+// handleSymbolSyncCommand reloads the Bitget contract list now (admin only)
+func (tb *TelegramBot) handleSymbolSyncCommand(chatID int64, userID int64) {
+        if !tb.requireAdmin(chatID, userID) {
+                return
+        }
+        
+        if tb.symbolMapper == nil {
+                tb.sendMessage(chatID, "❌ Sembol eşleştirme mevcut değil.")
+                return
+        }
+        
+        if err := tb.symbolMapper.Sync(); err != nil {
+                tb.sendMessage(chatID, fmt.Sprintf("❌ Senkron başarısız: %v", err))
+                return
+        }
+        
+        contracts, _ := tb.symbolMapper.Stats()
+        tb.sendMessage(chatID, fmt.Sprintf("✅ Bitget kontrat listesi senkronlandı: %d sembol.", contracts))
+}
+
+// handleDecisionsCommand shows the latest detection quorum decisions, optionally for one symbol (admin only)
+func (tb *TelegramBot) handleDecisionsCommand(chatID int64, userID int64, symbol string) {
+        if !tb.requireAdmin(chatID, userID) {
+                return
+        }
+        
+        if tb.upbitMonitor == nil {
+                tb.sendMessage(chatID, "❌ Upbit monitor mevcut değil.")
+                return
+        }
+        
+        symbol = strings.ToUpper(symbol)
+        decisions, err := tb.upbitMonitor.GetDetectionDecisions(symbol, 20)
+        if err != nil {
+                tb.sendMessage(chatID, fmt.Sprintf("❌ Kararlar alınamadı: %v", err))
+                return
+        }
+        if len(decisions) == 0 {
+                tb.sendMessage(chatID, "📭 Tespit kararı bulunmuyor.")
+                return
+        }
+        
+        text := fmt.Sprintf("🗳️ *Tespit Kararları* (son %d)\n\n", len(decisions))
+        for _, decision := range decisions {
+                icon := "✅"
+                if decision.Decision == models.DetectionExpired {
+                        icon = "⌛"
+                }
+                text += fmt.Sprintf("%s %s | %s | skor %.2f/%.2f | %s\n   %s\n",
+                        icon, decision.Symbol, decision.DecidedAt.Format("01-02 15:04:05"), decision.Score, decision.Threshold,
+                        escapeMarkdown(decision.Sources), escapeMarkdown(decision.Reason))
+        }
+        
+        tb.sendMessage(chatID, text)
+}
+
+// handleLatencyCommand shows detection and entry latency percentiles, over the last N hours (default 168) (admin only)
+func (tb *TelegramBot) handleLatencyCommand(chatID int64, userID int64, arg string) {
+        if !tb.requireAdmin(chatID, userID) {
+                return
+        }
+        
+        hours := 24 * 7
+        if arg != "" {
+                value, err := strconv.Atoi(arg)
+                if err != nil || value <= 0 {
+                        tb.sendMessage(chatID, "❌ Kullanım: /latency [saat]")
+                        return
+                }
+                hours = value
+        }
+        
+        report, err := GetLatencyReport(time.Now().Add(-time.Duration(hours) * time.Hour))
+        if err != nil {
+                tb.sendMessage(chatID, fmt.Sprintf("❌ Gecikme raporu alınamadı: %v", err))
+                return
+        }
+        
+        text := fmt.Sprintf("⏱️ *Gecikme Raporu* (son %d saat)\n\n", hours)
+        text += formatLatencyStats("📰 Duyuru → tespit", report.Detection, "ms")
+        text += formatLatencyStats("🧠 Tespit → emir", report.Decision, "ms")
+        text += formatLatencyStats("📤 Emir → onay", report.Ack, "ms")
+        text += formatLatencyStats("🏁 Duyuru → onay", report.Total, "ms")
+        text += formatLatencyStats("⏳ Planlı bekleme", report.Wait, "ms")
+        text += formatLatencyStats("💹 Dolum vs duyuru öncesi fiyat", report.Slippage, "%")
+        
+        tb.sendMessage(chatID, text)
+}
+
+// formatLatencyStats renders one line group of the latency report
+func formatLatencyStats(label string, stats LatencyStats, unit string) string {
+        if stats.Count == 0 {
+                return fmt.Sprintf("%s: veri yok\n\n", label)
+        }
+        format := "%.0f" + unit
+        if unit == "%" {
+                format = "%.2f%%"
+        }
+        value := func(v float64) string { return fmt.Sprintf(format, v) }
+        return fmt.Sprintf("%s (%d)\n   p50 %s | p90 %s | p99 %s | max %s\n\n", label, stats.Count,
+                value(stats.P50), value(stats.P90), value(stats.P99), value(stats.Max))
+}
+
 // handleUpdateAPICommand handles /update_api command
 func (tb *TelegramBot) handleUpdateAPICommand(chatID int64, userID int64) {
         // Check if user exists
@@ -994,7 +1644,7 @@ func (tb *TelegramBot) handleConfirmCloseCallback(chatID int64, userID int64) {
         }
         
         // Initialize Bitget API
-        bitgetAPI := NewBitgetAPI(apiKey, apiSecret, passphrase)
+        bitgetAPI := tb.newExchange(apiKey, apiSecret, passphrase)
         
         // Close position on Bitget using Flash Close (market price instantly)
         log.Printf("🚨 EMERGENCY CLOSE: Flash closing position %s for user %d", position.PositionID, userID)
@@ -1007,9 +1657,7 @@ func (tb *TelegramBot) handleConfirmCloseCallback(chatID int64, userID int64) {
                         log.Printf("ℹ️ Position %s already closed on Bitget, updating database", position.PositionID)
                         
                         // Position already closed on Bitget, just update our database
-                        now := time.Now()
-                        position.Status = models.PositionClosed
-                        position.ClosedAt = &now
+                        position.MarkClosed(exchangeExitReason(bitgetAPI, position))
                         
                         if err := database.DB.Save(&position).Error; err != nil {
                                 log.Printf("❌ Failed to update position in database: %v", err)
@@ -1031,9 +1679,7 @@ func (tb *TelegramBot) handleConfirmCloseCallback(chatID int64, userID int64) {
         }
         
         // Update position status in database
-        now := time.Now()
-        position.Status = models.PositionClosed
-        position.ClosedAt = &now
+        position.MarkClosed(models.ExitReasonManual)
         
         if err := database.DB.Save(&position).Error; err != nil {
                 log.Printf("❌ Failed to update position in database: %v", err)
@@ -1222,31 +1868,388 @@ func (tb *TelegramBot) handleTakeProfitSelectionCallback(chatID int64, userID in
         }
         
         tb.sendMessage(chatID, fmt.Sprintf("✅ Take profit %.0f%% olarak güncellendi.", takeProfitValue))
+        tb.updateExitOrders(chatID, user)
 }
 
-func (tb *TelegramBot) handleTestCoinCallback(chatID int64, userID int64, coinSymbol string) {
-        if coinSymbol == "custom" {
-                tb.sendMessage(chatID, "🧪 *Custom Test Coin*\n\nLütfen test etmek istediğiniz coin symbol'ını girin:\n(Örnek: AVAX, LINK, UNI)")
-                tb.setUserState(userID, "awaiting_test_coin", nil)
+func (tb *TelegramBot) handleStopLossCallback(chatID int64, userID int64) {
+        user, err := tb.getUser(userID)
+        if err != nil {
+                tb.sendMessage(chatID, "❌ Kullanıcı bulunamadı.")
                 return
         }
         
-        // Execute test trade for the selected coin
-        tb.executeTestTrade(chatID, userID, coinSymbol)
-}
+        text := fmt.Sprintf(`🛑 *Stop Loss*
 
-func (tb *TelegramBot) executeTestTrade(chatID int64, userID int64, coinSymbol string) {
-        if tb.upbitMonitor == nil {
-                tb.sendMessage(chatID, "❌ Test sistemi kullanılamıyor.")
-                return
-        }
-        
-        confirmText := fmt.Sprintf(`🧪 *TEST TRADİNG ONAY*
+Pozisyon stop seviyesine inerse Bitget'teki stop emriyle piyasa fiyatından kapatılır.
 
-🪙 Test Coin: %s
-⚠️ *DİKKAT:* Bu gerçek para ile işlem yapar!
+📏 *Fiyat*: giriş fiyatından %%X düşüş
+🚀 *ROE*: marjinin %%X'i kayıp (fiyat düşüşü = X / leverage)
 
-Test yapmak istediğinizden emin misiniz?`, coinSymbol)
+🛑 Mevcut: %s`, stopLossLabel(user))
+        
+        keyboard := tgbotapi.NewInlineKeyboardMarkup(
+                tgbotapi.NewInlineKeyboardRow(
+                        tgbotapi.NewInlineKeyboardButtonData(filterButtonLabel("📏 Fiyat", user.StopLossMode != models.StopLossModeROE), "sl_mode_price"),
+                        tgbotapi.NewInlineKeyboardButtonData(filterButtonLabel("🚀 ROE", user.StopLossMode == models.StopLossModeROE), "sl_mode_roe"),
+                ),
+                tgbotapi.NewInlineKeyboardRow(
+                        tgbotapi.NewInlineKeyboardButtonData("Kapalı", "sl_0"),
+                        tgbotapi.NewInlineKeyboardButtonData("10%", "sl_10"),
+                        tgbotapi.NewInlineKeyboardButtonData("20%", "sl_20"),
+                ),
+                tgbotapi.NewInlineKeyboardRow(
+                        tgbotapi.NewInlineKeyboardButtonData("30%", "sl_30"),
+                        tgbotapi.NewInlineKeyboardButtonData("50%", "sl_50"),
+                        tgbotapi.NewInlineKeyboardButtonData("🔢 Custom", "sl_custom"),
+                ),
+        )
+        
+        msg := tgbotapi.NewMessage(chatID, text)
+        msg.ReplyMarkup = keyboard
+        msg.ParseMode = "Markdown"
+        tb.Bot.Send(msg)
+}
+
+func (tb *TelegramBot) handleStopLossModeCallback(chatID int64, userID int64, mode string) {
+        if mode != models.StopLossModePrice && mode != models.StopLossModeROE {
+                tb.sendMessage(chatID, "❌ Geçersiz stop loss modu.")
+                return
+        }
+        
+        user, err := tb.getUser(userID)
+        if err != nil {
+                tb.sendMessage(chatID, "❌ Kullanıcı bulunamadı.")
+                return
+        }
+        
+        user.StopLossMode = mode
+        if err := database.DB.Save(user).Error; err != nil {
+                tb.sendMessage(chatID, "❌ Ayar kaydedilirken hata oluştu.")
+                return
+        }
+        
+        tb.handleStopLossCallback(chatID, userID)
+        tb.updateExitOrders(chatID, user)
+}
+
+func (tb *TelegramBot) handleStopLossSelectionCallback(chatID int64, userID int64, stopLoss string) {
+        if stopLoss == "custom" {
+                tb.sendMessage(chatID, "🛑 *Custom Stop Loss*\n\nLütfen stop loss yüzdesini girin (0 kapatır):\n(Örnek: 15 -> %15)")
+                tb.setUserState(userID, "awaiting_stop_loss", nil)
+                return
+        }
+        
+        var stopLossValue float64
+        switch stopLoss {
+        case "0": stopLossValue = 0
+        case "10": stopLossValue = 10
+        case "20": stopLossValue = 20
+        case "30": stopLossValue = 30
+        case "50": stopLossValue = 50
+        default:
+                tb.sendMessage(chatID, "❌ Geçersiz stop loss seçimi.")
+                return
+        }
+        
+        tb.saveStopLoss(chatID, userID, stopLossValue)
+}
+
+func (tb *TelegramBot) handleStopLossInput(chatID int64, userID int64, input string) {
+        stopLoss, err := strconv.ParseFloat(strings.TrimSpace(input), 64)
+        if err != nil || stopLoss < 0 || stopLoss >= 100 {
+                tb.sendMessage(chatID, "❌ Geçersiz stop loss. 0 ile 100 arasında bir yüzde değeri girin (0 kapatır).")
+                return
+        }
+        
+        tb.clearUserState(userID)
+        tb.saveStopLoss(chatID, userID, stopLoss)
+}
+
+// saveStopLoss stores the stop loss percentage and moves the stops of open positions
+func (tb *TelegramBot) saveStopLoss(chatID int64, userID int64, stopLoss float64) {
+        user, err := tb.getUser(userID)
+        if err != nil {
+                tb.sendMessage(chatID, "❌ Kullanıcı bulunamadı.")
+                return
+        }
+        
+        user.StopLossPercentage = stopLoss
+        if err := database.DB.Save(user).Error; err != nil {
+                tb.sendMessage(chatID, "❌ Ayar kaydedilirken hata oluştu.")
+                return
+        }
+        
+        tb.sendMessage(chatID, fmt.Sprintf("✅ Stop loss güncellendi: %s", stopLossLabel(user)))
+        tb.updateExitOrders(chatID, user)
+}
+
+func (tb *TelegramBot) handleExitModeCallback(chatID int64, userID int64) {
+        user, err := tb.getUser(userID)
+        if err != nil {
+                tb.sendMessage(chatID, "❌ Kullanıcı bulunamadı.")
+                return
+        }
+        
+        trailing := user.ExitMode == models.ExitModeTrailing
+        text := fmt.Sprintf(`🎯 *Çıkış Modu*
+
+🎯 *Sabit TP*: fiyat take profit seviyesine gelince kapatılır
+📉 *Trailing*: fiyat aktivasyon seviyesine çıkınca zirve takip edilir, zirveden geri çekilme kadar düşünce kapatılır
+
+%g%%'ye kadar geri çekilmeler Bitget'te trailing emriyle, daha genişleri bot tarafından izlenir.
+
+🎯 Mevcut: %s`, bitgetMaxCallbackRate, exitModeLabel(user))
+        
+        keyboard := tgbotapi.NewInlineKeyboardMarkup(
+                tgbotapi.NewInlineKeyboardRow(
+                        tgbotapi.NewInlineKeyboardButtonData(filterButtonLabel("🎯 Sabit TP", !trailing), "exit_mode_fixed"),
+                        tgbotapi.NewInlineKeyboardButtonData(filterButtonLabel("📉 Trailing", trailing), "exit_mode_trailing"),
+                ),
+                tgbotapi.NewInlineKeyboardRow(
+                        tgbotapi.NewInlineKeyboardButtonData("⬆️ +50%", "trail_act_50"),
+                        tgbotapi.NewInlineKeyboardButtonData("⬆️ +100%", "trail_act_100"),
+                        tgbotapi.NewInlineKeyboardButtonData("⬆️ +200%", "trail_act_200"),
+                        tgbotapi.NewInlineKeyboardButtonData("⬆️ Custom", "trail_act_custom"),
+                ),
+                tgbotapi.NewInlineKeyboardRow(
+                        tgbotapi.NewInlineKeyboardButtonData("↩️ 5%", "trail_cb_5"),
+                        tgbotapi.NewInlineKeyboardButtonData("↩️ 10%", "trail_cb_10"),
+                        tgbotapi.NewInlineKeyboardButtonData("↩️ 20%", "trail_cb_20"),
+                        tgbotapi.NewInlineKeyboardButtonData("↩️ Custom", "trail_cb_custom"),
+                ),
+        )
+        
+        msg := tgbotapi.NewMessage(chatID, text)
+        msg.ReplyMarkup = keyboard
+        msg.ParseMode = "Markdown"
+        tb.Bot.Send(msg)
+}
+
+func (tb *TelegramBot) handleExitModeSelectionCallback(chatID int64, userID int64, mode string) {
+        if mode != models.ExitModeFixed && mode != models.ExitModeTrailing {
+                tb.sendMessage(chatID, "❌ Geçersiz çıkış modu.")
+                return
+        }
+        tb.saveExitSetting(chatID, userID, func(user *models.User) {
+                user.ExitMode = mode
+        })
+}
+
+func (tb *TelegramBot) handleTrailingSelectionCallback(chatID int64, userID int64, field string, value string) {
+        if value == "custom" {
+                if field == "activation" {
+                        tb.sendMessage(chatID, "⬆️ *Trailing Aktivasyon*\n\nGiriş fiyatından yüzde kaç yükselişte trailing başlasın?\n(Örnek: 150 -> %150)")
+                        tb.setUserState(userID, "awaiting_trail_activation", nil)
+                } else {
+                        tb.sendMessage(chatID, "↩️ *Trailing Geri Çekilme*\n\nZirveden yüzde kaç düşüşte kapatılsın?\n(Örnek: 7.5 -> %7.5)")
+                        tb.setUserState(userID, "awaiting_trail_callback", nil)
+                }
+                return
+        }
+        
+        percentage, err := strconv.ParseFloat(value, 64)
+        if err != nil || percentage <= 0 {
+                tb.sendMessage(chatID, "❌ Geçersiz trailing seçimi.")
+                return
+        }
+        tb.saveTrailingPercentage(chatID, userID, field, percentage)
+}
+
+func (tb *TelegramBot) handleTrailingInput(chatID int64, userID int64, field string, input string) {
+        percentage, err := strconv.ParseFloat(strings.TrimSpace(input), 64)
+        if err != nil || percentage <= 0 || (field == "callback" && percentage >= 100) {
+                tb.sendMessage(chatID, "❌ Geçersiz değer. Pozitif bir yüzde girin (geri çekilme 100'den küçük olmalı).")
+                return
+        }
+        
+        tb.clearUserState(userID)
+        tb.saveTrailingPercentage(chatID, userID, field, percentage)
+}
+
+// saveTrailingPercentage stores the activation or callback percentage of the trailing stop
+func (tb *TelegramBot) saveTrailingPercentage(chatID int64, userID int64, field string, percentage float64) {
+        tb.saveExitSetting(chatID, userID, func(user *models.User) {
+                if field == "activation" {
+                        user.TrailingActivationPercentage = percentage
+                } else {
+                        user.TrailingCallbackPercentage = percentage
+                }
+        })
+}
+
+// saveExitSetting applies an exit mode change, shows the menu again and moves the exit orders of open positions
+func (tb *TelegramBot) saveExitSetting(chatID int64, userID int64, apply func(user *models.User)) {
+        user, err := tb.getUser(userID)
+        if err != nil {
+                tb.sendMessage(chatID, "❌ Kullanıcı bulunamadı.")
+                return
+        }
+        
+        apply(user)
+        if err := database.DB.Save(user).Error; err != nil {
+                tb.sendMessage(chatID, "❌ Ayar kaydedilirken hata oluştu.")
+                return
+        }
+        
+        tb.handleExitModeCallback(chatID, userID)
+        tb.updateExitOrders(chatID, user)
+}
+
+// exitModeLabel describes how a user's positions take profit
+func exitModeLabel(user *models.User) string {
+        if user.UsesTrailingStop() {
+                return fmt.Sprintf("Trailing (+%g%%'de aktif, %g%% geri çekilme)", user.TrailingActivationPercentage, user.TrailingCallbackPercentage)
+        }
+        return fmt.Sprintf("Sabit TP (%.0f%%)", user.TakeProfitPercentage)
+}
+
+// stopLossLabel describes a user's stop loss setting
+func stopLossLabel(user *models.User) string {
+        if user.StopLossPercentage <= 0 {
+                return "Kapalı"
+        }
+        if user.StopLossMode == models.StopLossModeROE {
+                return fmt.Sprintf("%g%% (ROE)", user.StopLossPercentage)
+        }
+        return fmt.Sprintf("%g%% (fiyat)", user.StopLossPercentage)
+}
+
+// updateExitOrders moves the Bitget exit orders of the user's open positions to the new settings
+func (tb *TelegramBot) updateExitOrders(chatID int64, user *models.User) {
+        if tb.tradingEngine == nil {
+                return
+        }
+        
+        updated, err := tb.tradingEngine.SyncUserExitOrders(*user)
+        if err != nil {
+                log.Printf("⚠️ Failed to sync exit orders for user %d: %v", user.TelegramID, err)
+                tb.sendMessage(chatID, fmt.Sprintf("⚠️ Açık pozisyonların Bitget TP/SL emirleri güncellenemedi: %v", err))
+                return
+        }
+        if updated > 0 {
+                tb.sendMessage(chatID, fmt.Sprintf("🛡️ %d açık pozisyonun Bitget TP/SL emirleri güncellendi.", updated))
+        }
+}
+
+func (tb *TelegramBot) handleTakeProfitLadderCallback(chatID int64, userID int64) {
+        user, err := tb.getUser(userID)
+        if err != nil {
+                tb.sendMessage(chatID, "❌ Kullanıcı bulunamadı.")
+                return
+        }
+        
+        text := fmt.Sprintf(`🪜 *TP Kademeleri*
+
+Pozisyonun bir kısmı belirlenen yükselişlerde kapatılır, kalan kısım %s ile çıkar.
+Kademeler Bitget'te kısmi TP emri olarak kurulur ve yeni açılan pozisyonlarda geçerli olur.
+
+🪜 Mevcut: %s`, exitModeLabel(user), takeProfitLadderLabel(user))
+        
+        keyboard := tgbotapi.NewInlineKeyboardMarkup(
+                tgbotapi.NewInlineKeyboardRow(
+                        tgbotapi.NewInlineKeyboardButtonData("50% @ +100%", "ladder_50@100"),
+                        tgbotapi.NewInlineKeyboardButtonData("30% @ +50%, 30% @ +100%", "ladder_30@50,30@100"),
+                ),
+                tgbotapi.NewInlineKeyboardRow(
+                        tgbotapi.NewInlineKeyboardButtonData("25% @ +50%/+100%/+200%", "ladder_25@50,25@100,25@200"),
+                ),
+                tgbotapi.NewInlineKeyboardRow(
+                        tgbotapi.NewInlineKeyboardButtonData("❌ Kapalı", "ladder_off"),
+                        tgbotapi.NewInlineKeyboardButtonData("✏️ Custom", "ladder_custom"),
+                ),
+        )
+        
+        msg := tgbotapi.NewMessage(chatID, text)
+        msg.ReplyMarkup = keyboard
+        msg.ParseMode = "Markdown"
+        tb.Bot.Send(msg)
+}
+
+func (tb *TelegramBot) handleTakeProfitLadderSelectionCallback(chatID int64, userID int64, value string) {
+        switch value {
+        case "custom":
+                tb.sendMessage(chatID, "🪜 *Custom TP Kademeleri*\n\nHer kademe için pozisyon yüzdesi@fiyat yükselişi girin, virgülle ayırın.\n(Örnek: 30@50, 30@100 -> %30'u +%50'de, %30'u +%100'de)")
+                tb.setUserState(userID, "awaiting_tp_ladder", nil)
+                return
+        case "off":
+                value = ""
+        }
+        
+        steps, err := models.ParseTakeProfitLadder(value)
+        if err != nil {
+                tb.sendMessage(chatID, "❌ Geçersiz kademe seçimi.")
+                return
+        }
+        tb.saveTakeProfitLadder(chatID, userID, steps)
+}
+
+func (tb *TelegramBot) handleTakeProfitLadderInput(chatID int64, userID int64, input string) {
+        steps, err := models.ParseTakeProfitLadder(input)
+        if err == nil && len(steps) == 0 {
+                err = fmt.Errorf("kademe girilmedi")
+        }
+        if err != nil {
+                tb.sendMessage(chatID, fmt.Sprintf("❌ Geçersiz kademeler: %v\nÖrnek: 30@50, 30@100 (yüzdeler toplamı en fazla 100, yükselişler artan sırada)", err))
+                return
+        }
+        
+        tb.clearUserState(userID)
+        tb.saveTakeProfitLadder(chatID, userID, steps)
+}
+
+// saveTakeProfitLadder stores the take profit ladder; open positions keep the ladder they were opened with
+func (tb *TelegramBot) saveTakeProfitLadder(chatID int64, userID int64, steps []models.TakeProfitStep) {
+        user, err := tb.getUser(userID)
+        if err != nil {
+                tb.sendMessage(chatID, "❌ Kullanıcı bulunamadı.")
+                return
+        }
+        
+        user.TakeProfitLadder = models.FormatTakeProfitLadder(steps)
+        if err := database.DB.Save(user).Error; err != nil {
+                tb.sendMessage(chatID, "❌ Ayar kaydedilirken hata oluştu.")
+                return
+        }
+        
+        tb.sendMessage(chatID, fmt.Sprintf("✅ TP kademeleri güncellendi: %s\nYeni açılan pozisyonlarda geçerli olur.", takeProfitLadderLabel(user)))
+}
+
+// takeProfitLadderLabel describes a user's take profit ladder
+func takeProfitLadderLabel(user *models.User) string {
+        steps := user.TakeProfitSteps()
+        if len(steps) == 0 {
+                return "Kapalı"
+        }
+        parts := make([]string, len(steps))
+        for i, step := range steps {
+                parts[i] = fmt.Sprintf("%g%% @ +%g%%", step.SizePercent, step.GainPercent)
+        }
+        return strings.Join(parts, ", ")
+}
+
+func (tb *TelegramBot) handleTestCoinCallback(chatID int64, userID int64, coinSymbol string) {
+        if coinSymbol == "custom" {
+                tb.sendMessage(chatID, "🧪 *Custom Test Coin*\n\nLütfen test etmek istediğiniz coin symbol'ını girin:\n(Örnek: AVAX, LINK, UNI)")
+                tb.setUserState(userID, "awaiting_test_coin", nil)
+                return
+        }
+        
+        // Execute test trade for the selected coin
+        tb.executeTestTrade(chatID, userID, coinSymbol)
+}
+
+func (tb *TelegramBot) executeTestTrade(chatID int64, userID int64, coinSymbol string) {
+        if tb.upbitMonitor == nil {
+                tb.sendMessage(chatID, "❌ Test sistemi kullanılamıyor.")
+                return
+        }
+        
+        confirmText := fmt.Sprintf(`🧪 *TEST TRADİNG ONAY*
+
+🪙 Test Coin: %s
+⚠️ *DİKKAT:* Bu gerçek para ile işlem yapar!
+
+Test yapmak istediğinizden emin misiniz?`, coinSymbol)
 
         keyboard := tgbotapi.NewInlineKeyboardMarkup(
                 tgbotapi.NewInlineKeyboardRow(
@@ -1294,6 +2297,400 @@ func (tb *TelegramBot) handleToggleActiveCallback(chatID int64, userID int64) {
         tb.sendMessage(chatID, fmt.Sprintf("✅ Bot durumu güncellendi: %s", status))
 }
 
+func (tb *TelegramBot) handleMarketFiltersCallback(chatID int64, userID int64) {
+        user, err := tb.getUser(userID)
+        if err != nil {
+                tb.sendMessage(chatID, "❌ Kullanıcı bulunamadı.")
+                return
+        }
+        
+        text := fmt.Sprintf(`🏦 *Market Filtreleri*
+
+Hangi duyurular trade açsın? Değiştirmek için dokunun.
+
+🏦 Marketler: %s
+🏷️ Türler: %s
+
+🆕 Yeni listeleme: coin Upbit'te ilk kez listeleniyor
+➕ Market ekleme: zaten listelenmiş coin'e yeni market açılıyor`,
+                marketFilterSummary(user), listingTypeSummary(user))
+        
+        keyboard := tgbotapi.NewInlineKeyboardMarkup(
+                tgbotapi.NewInlineKeyboardRow(
+                        tgbotapi.NewInlineKeyboardButtonData(filterButtonLabel("KRW", user.TradeKRWMarket), "filter_krw"),
+                        tgbotapi.NewInlineKeyboardButtonData(filterButtonLabel("BTC", user.TradeBTCMarket), "filter_btc"),
+                        tgbotapi.NewInlineKeyboardButtonData(filterButtonLabel("USDT", user.TradeUSDTMarket), "filter_usdt"),
+                ),
+                tgbotapi.NewInlineKeyboardRow(
+                        tgbotapi.NewInlineKeyboardButtonData(filterButtonLabel("🆕 Yeni listeleme", user.TradeNewListings), "filter_new"),
+                        tgbotapi.NewInlineKeyboardButtonData(filterButtonLabel("➕ Market ekleme", user.TradeAddedMarkets), "filter_added"),
+                ),
+        )
+        
+        msg := tgbotapi.NewMessage(chatID, text)
+        msg.ReplyMarkup = keyboard
+        msg.ParseMode = "Markdown"
+        tb.Bot.Send(msg)
+}
+
+func (tb *TelegramBot) handleMarketFilterToggleCallback(chatID int64, userID int64, filter string) {
+        user, err := tb.getUser(userID)
+        if err != nil {
+                tb.sendMessage(chatID, "❌ Kullanıcı bulunamadı.")
+                return
+        }
+        
+        switch filter {
+        case "krw":
+                user.TradeKRWMarket = !user.TradeKRWMarket
+        case "btc":
+                user.TradeBTCMarket = !user.TradeBTCMarket
+        case "usdt":
+                user.TradeUSDTMarket = !user.TradeUSDTMarket
+        case "new":
+                user.TradeNewListings = !user.TradeNewListings
+        case "added":
+                user.TradeAddedMarkets = !user.TradeAddedMarkets
+        default:
+                tb.sendMessage(chatID, "❌ Geçersiz filtre seçimi.")
+                return
+        }
+        
+        if err := database.DB.Save(user).Error; err != nil {
+                tb.sendMessage(chatID, "❌ Ayar kaydedilirken hata oluştu.")
+                return
+        }
+        
+        tb.handleMarketFiltersCallback(chatID, userID)
+}
+
+// marketFilterSummary lists the quote markets a user trades
+func marketFilterSummary(user *models.User) string {
+        var markets []string
+        if user.TradeKRWMarket {
+                markets = append(markets, "KRW")
+        }
+        if user.TradeBTCMarket {
+                markets = append(markets, "BTC")
+        }
+        if user.TradeUSDTMarket {
+                markets = append(markets, "USDT")
+        }
+        if len(markets) == 0 {
+                return "Hiçbiri"
+        }
+        return strings.Join(markets, ", ")
+}
+
+// listingTypeSummary lists the listing types a user trades
+func listingTypeSummary(user *models.User) string {
+        var types []string
+        if user.TradeNewListings {
+                types = append(types, "Yeni listeleme")
+        }
+        if user.TradeAddedMarkets {
+                types = append(types, "Market ekleme")
+        }
+        if len(types) == 0 {
+                return "Hiçbiri"
+        }
+        return strings.Join(types, ", ")
+}
+
+// filterButtonLabel marks a filter button as on or off
+func filterButtonLabel(label string, enabled bool) string {
+        if enabled {
+                return "✅ " + label
+        }
+        return "❌ " + label
+}
+
+func (tb *TelegramBot) handleExchangesCallback(chatID int64, userID int64) {
+        user, err := tb.getUser(userID)
+        if err != nil {
+                tb.sendMessage(chatID, "❌ Kullanıcı bulunamadı.")
+                return
+        }
+        
+        text := fmt.Sprintf(`🏛️ *Borsalar*
+
+Hangi borsanın listeleme duyuruları trade açsın? Değiştirmek için dokunun.
+
+🏛️ Borsalar: %s
+💰 Bithumb trade amount: %s
+
+Bithumb listelemeleri genelde daha küçük fiyat hareketi yaratır, bu yüzden ayrı bir miktar seçebilirsiniz.`,
+                exchangeSummary(user), bithumbAmountLabel(user))
+        
+        keyboard := tgbotapi.NewInlineKeyboardMarkup(
+                tgbotapi.NewInlineKeyboardRow(
+                        tgbotapi.NewInlineKeyboardButtonData(filterButtonLabel("Upbit", user.TradeUpbit), "exchange_upbit"),
+                        tgbotapi.NewInlineKeyboardButtonData(filterButtonLabel("Bithumb", user.TradeBithumb), "exchange_bithumb"),
+                ),
+                tgbotapi.NewInlineKeyboardRow(
+                        tgbotapi.NewInlineKeyboardButtonData("Upbit ile aynı", "bithumb_amount_0"),
+                        tgbotapi.NewInlineKeyboardButtonData("20 USDT", "bithumb_amount_20"),
+                        tgbotapi.NewInlineKeyboardButtonData("50 USDT", "bithumb_amount_50"),
+                ),
+                tgbotapi.NewInlineKeyboardRow(
+                        tgbotapi.NewInlineKeyboardButtonData("100 USDT", "bithumb_amount_100"),
+                        tgbotapi.NewInlineKeyboardButtonData("200 USDT", "bithumb_amount_200"),
+                        tgbotapi.NewInlineKeyboardButtonData("✏️ Özel", "bithumb_amount_custom"),
+                ),
+        )
+        
+        msg := tgbotapi.NewMessage(chatID, text)
+        msg.ReplyMarkup = keyboard
+        msg.ParseMode = "Markdown"
+        tb.Bot.Send(msg)
+}
+
+func (tb *TelegramBot) handleExchangeToggleCallback(chatID int64, userID int64, exchange string) {
+        user, err := tb.getUser(userID)
+        if err != nil {
+                tb.sendMessage(chatID, "❌ Kullanıcı bulunamadı.")
+                return
+        }
+        
+        switch exchange {
+        case models.ExchangeUpbit:
+                user.TradeUpbit = !user.TradeUpbit
+        case models.ExchangeBithumb:
+                user.TradeBithumb = !user.TradeBithumb
+        default:
+                tb.sendMessage(chatID, "❌ Geçersiz borsa seçimi.")
+                return
+        }
+        
+        if err := database.DB.Save(user).Error; err != nil {
+                tb.sendMessage(chatID, "❌ Ayar kaydedilirken hata oluştu.")
+                return
+        }
+        
+        tb.handleExchangesCallback(chatID, userID)
+}
+
+func (tb *TelegramBot) handleBithumbAmountSelectionCallback(chatID int64, userID int64, amount string) {
+        if amount == "custom" {
+                tb.sendMessage(chatID, "💰 *Bithumb Trade Amount*\n\nLütfen Bithumb listelemeleri için trade amount'ı USDT cinsinden girin:\n(Örnek: 40)")
+                tb.setUserState(userID, "awaiting_bithumb_amount", nil)
+                return
+        }
+        
+        var amountValue float64
+        switch amount {
+        case "0": amountValue = 0
+        case "20": amountValue = 20
+        case "50": amountValue = 50
+        case "100": amountValue = 100
+        case "200": amountValue = 200
+        default:
+                tb.sendMessage(chatID, "❌ Geçersiz amount seçimi.")
+                return
+        }
+        
+        user, err := tb.getUser(userID)
+        if err != nil {
+                tb.sendMessage(chatID, "❌ Kullanıcı bulunamadı.")
+                return
+        }
+        
+        user.BithumbTradeAmount = amountValue
+        if err := database.DB.Save(user).Error; err != nil {
+                tb.sendMessage(chatID, "❌ Ayar kaydedilirken hata oluştu.")
+                return
+        }
+        
+        tb.sendMessage(chatID, fmt.Sprintf("✅ Bithumb trade amount: %s", bithumbAmountLabel(user)))
+}
+
+// exchangeSummary lists the exchanges whose listings a user trades
+func exchangeSummary(user *models.User) string {
+        var exchanges []string
+        if user.TradeUpbit {
+                exchanges = append(exchanges, "Upbit")
+        }
+        if user.TradeBithumb {
+                exchanges = append(exchanges, "Bithumb")
+        }
+        if len(exchanges) == 0 {
+                return "Hiçbiri"
+        }
+        return strings.Join(exchanges, ", ")
+}
+
+// bithumbAmountLabel describes the amount traded on Bithumb listings
+func bithumbAmountLabel(user *models.User) string {
+        if user.BithumbTradeAmount <= 0 {
+                return fmt.Sprintf("Upbit ile aynı (%.0f USDT)", user.TradeAmount)
+        }
+        return fmt.Sprintf("%.0f USDT", user.BithumbTradeAmount)
+}
+
+// exchangeLabel returns the display name of the exchange that announced a listing
+func exchangeLabel(exchange string) string {
+        switch exchange {
+        case models.ExchangeBithumb:
+                return "Bithumb"
+        default:
+                return "Upbit"
+        }
+}
+
+func (tb *TelegramBot) handleRiskPolicyCallback(chatID int64) {
+        text := `🚨 *Risk Politikası Seçin*
+
+⛔ *Listeden çıkarma* (거래지원 종료) ve ⚠️ *yatırım uyarısı* (투자유의 종목 지정) duyurularında açık pozisyonlarınıza ne yapılsın?
+
+🔴 Kapat: pozisyon anında piyasa fiyatından kapatılır
+🔔 Bildir: sadece uyarı mesajı gönderilir
+🔕 Yoksay: hiçbir işlem yapılmaz`
+        
+        keyboard := tgbotapi.NewInlineKeyboardMarkup(
+                tgbotapi.NewInlineKeyboardRow(
+                        tgbotapi.NewInlineKeyboardButtonData("⛔ 🔴 Kapat", "risk_delisting_close"),
+                        tgbotapi.NewInlineKeyboardButtonData("⛔ 🔔 Bildir", "risk_delisting_notify"),
+                        tgbotapi.NewInlineKeyboardButtonData("⛔ 🔕 Yoksay", "risk_delisting_ignore"),
+                ),
+                tgbotapi.NewInlineKeyboardRow(
+                        tgbotapi.NewInlineKeyboardButtonData("⚠️ 🔴 Kapat", "risk_warning_close"),
+                        tgbotapi.NewInlineKeyboardButtonData("⚠️ 🔔 Bildir", "risk_warning_notify"),
+                        tgbotapi.NewInlineKeyboardButtonData("⚠️ 🔕 Yoksay", "risk_warning_ignore"),
+                ),
+        )
+        
+        msg := tgbotapi.NewMessage(chatID, text)
+        msg.ReplyMarkup = keyboard
+        msg.ParseMode = "Markdown"
+        tb.Bot.Send(msg)
+}
+
+func (tb *TelegramBot) handleRiskPolicySelectionCallback(chatID int64, userID int64, selection string) {
+        parts := strings.SplitN(selection, "_", 2)
+        if len(parts) != 2 {
+                tb.sendMessage(chatID, "❌ Geçersiz risk politikası seçimi.")
+                return
+        }
+        
+        kind, action := parts[0], parts[1]
+        switch action {
+        case models.RiskActionClose, models.RiskActionNotify, models.RiskActionIgnore:
+        default:
+                tb.sendMessage(chatID, "❌ Geçersiz risk politikası seçimi.")
+                return
+        }
+        
+        user, err := tb.getUser(userID)
+        if err != nil {
+                tb.sendMessage(chatID, "❌ Kullanıcı bulunamadı.")
+                return
+        }
+        
+        var label string
+        switch kind {
+        case string(ListingKindDelisting):
+                user.DelistingAction = action
+                label = "Listeden çıkarma"
+        case string(ListingKindWarning):
+                user.WarningAction = action
+                label = "Yatırım uyarısı"
+        default:
+                tb.sendMessage(chatID, "❌ Geçersiz risk politikası seçimi.")
+                return
+        }
+        
+        if err := database.DB.Save(user).Error; err != nil {
+                tb.sendMessage(chatID, "❌ Ayar kaydedilirken hata oluştu.")
+                return
+        }
+        
+        tb.sendMessage(chatID, fmt.Sprintf("✅ %s politikası güncellendi: %s", label, riskActionLabel(action)))
+}
+
+// riskActionLabel returns the Turkish label of a risk action
+func riskActionLabel(action string) string {
+        switch action {
+        case models.RiskActionClose:
+                return "🔴 Kapat"
+        case models.RiskActionNotify:
+                return "🔔 Bildir"
+        default:
+                return "🔕 Yoksay"
+        }
+}
+
+func (tb *TelegramBot) handleEntryModeCallback(chatID int64, userID int64) {
+        user, err := tb.getUser(userID)
+        if err != nil {
+                tb.sendMessage(chatID, "❌ Kullanıcı bulunamadı.")
+                return
+        }
+        
+        text := fmt.Sprintf(`⏱️ *Giriş Zamanı Seçin*
+
+Şu an: %s
+
+⚡ Hemen: duyuru tespit edilir edilmez pozisyon açılır
+🔔 İşlem başlangıcında: duyuruda yazan Upbit işlem açılış saatinde açılır
+🎯 Başlangıca göre: işlem açılışından belirlediğiniz saniye kadar önce/sonra açılır
+
+İşlem başlangıç saati bulunamayan duyurularda pozisyon her zaman hemen açılır.`, entryModeLabel(user))
+        
+        keyboard := tgbotapi.NewInlineKeyboardMarkup(
+                tgbotapi.NewInlineKeyboardRow(
+                        tgbotapi.NewInlineKeyboardButtonData("⚡ Hemen", "entry_immediate"),
+                        tgbotapi.NewInlineKeyboardButtonData("🔔 İşlem başlangıcında", "entry_trading_start"),
+                ),
+                tgbotapi.NewInlineKeyboardRow(
+                        tgbotapi.NewInlineKeyboardButtonData("🎯 Başlangıca göre (saniye)", "entry_offset"),
+                ),
+        )
+        
+        msg := tgbotapi.NewMessage(chatID, text)
+        msg.ReplyMarkup = keyboard
+        msg.ParseMode = "Markdown"
+        tb.Bot.Send(msg)
+}
+
+func (tb *TelegramBot) handleEntryModeSelectionCallback(chatID int64, userID int64, mode string) {
+        switch mode {
+        case models.EntryModeOffset:
+                tb.sendMessage(chatID, "🎯 İşlem başlangıcına göre kaç saniye sonra girilsin? Önce girmek için negatif değer yazın (örn. 30 veya -10, en fazla ±3600):")
+                tb.setUserState(userID, "awaiting_entry_offset", nil)
+                return
+        case models.EntryModeImmediate, models.EntryModeTradingStart:
+        default:
+                tb.sendMessage(chatID, "❌ Geçersiz giriş zamanı seçimi.")
+                return
+        }
+        
+        user, err := tb.getUser(userID)
+        if err != nil {
+                tb.sendMessage(chatID, "❌ Kullanıcı bulunamadı.")
+                return
+        }
+        
+        user.EntryMode = mode
+        if err := database.DB.Save(user).Error; err != nil {
+                tb.sendMessage(chatID, "❌ Ayar kaydedilirken hata oluştu.")
+                return
+        }
+        
+        tb.sendMessage(chatID, fmt.Sprintf("✅ Giriş zamanı güncellendi: %s", entryModeLabel(user)))
+}
+
+// entryModeLabel returns the Turkish description of a user's entry mode
+func entryModeLabel(user *models.User) string {
+        switch user.EntryMode {
+        case models.EntryModeTradingStart:
+                return "🔔 İşlem başlangıcında"
+        case models.EntryModeOffset:
+                return fmt.Sprintf("🎯 İşlem başlangıcı %+d sn", user.EntryOffsetSeconds)
+        default:
+                return "⚡ Hemen"
+        }
+}
+
 // Input handlers for settings
 func (tb *TelegramBot) handleTradeAmountInput(chatID int64, userID int64, input string) {
         amount, err := strconv.ParseFloat(input, 64)
@@ -1320,6 +2717,31 @@ func (tb *TelegramBot) handleTradeAmountInput(chatID int64, userID int64, input
         tb.clearUserState(userID)
 }
 
+func (tb *TelegramBot) handleBithumbAmountInput(chatID int64, userID int64, input string) {
+        amount, err := strconv.ParseFloat(input, 64)
+        if err != nil || amount <= 0 {
+                tb.sendMessage(chatID, "❌ Geçersiz miktar. Lütfen pozitif bir sayı girin.")
+                return
+        }
+        
+        user, err := tb.getUser(userID)
+        if err != nil {
+                tb.sendMessage(chatID, "❌ Kullanıcı bulunamadı.")
+                tb.clearUserState(userID)
+                return
+        }
+        
+        user.BithumbTradeAmount = amount
+        if err := database.DB.Save(user).Error; err != nil {
+                tb.sendMessage(chatID, "❌ Ayar kaydedilirken hata oluştu.")
+                tb.clearUserState(userID)
+                return
+        }
+        
+        tb.sendMessage(chatID, fmt.Sprintf("✅ Bithumb trade amount %.0f USDT olarak güncellendi.", amount))
+        tb.clearUserState(userID)
+}
+
 func (tb *TelegramBot) handleLeverageInput(chatID int64, userID int64, input string) {
         leverage, err := strconv.Atoi(input)
         if err != nil || leverage < 1 || leverage > 125 {
@@ -1368,4 +2790,31 @@ func (tb *TelegramBot) handleTakeProfitInput(chatID int64, userID int64, input s
         
         tb.sendMessage(chatID, fmt.Sprintf("✅ Take profit %.0f%% olarak güncellendi.", takeProfit))
         tb.clearUserState(userID)
+        tb.updateExitOrders(chatID, user)
+}
+
+func (tb *TelegramBot) handleEntryOffsetInput(chatID int64, userID int64, input string) {
+        offset, err := strconv.Atoi(strings.TrimSpace(input))
+        if err != nil || offset < -3600 || offset > 3600 {
+                tb.sendMessage(chatID, "❌ Geçersiz değer. -3600 ile 3600 arasında bir saniye değeri girin.")
+                return
+        }
+        
+        user, err := tb.getUser(userID)
+        if err != nil {
+                tb.sendMessage(chatID, "❌ Kullanıcı bulunamadı.")
+                tb.clearUserState(userID)
+                return
+        }
+        
+        user.EntryMode = models.EntryModeOffset
+        user.EntryOffsetSeconds = offset
+        if err := database.DB.Save(user).Error; err != nil {
+                tb.sendMessage(chatID, "❌ Ayar kaydedilirken hata oluştu.")
+                tb.clearUserState(userID)
+                return
+        }
+        
+        tb.sendMessage(chatID, fmt.Sprintf("✅ Giriş zamanı güncellendi: %s", entryModeLabel(user)))
+        tb.clearUserState(userID)
 }
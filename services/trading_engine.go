@@ -1,6 +1,7 @@
 package services
 
 import (
+        "errors"
         "fmt"
         "log"
         "strconv"
@@ -9,6 +10,8 @@ import (
         "time"
         "upbit-bitget-trading-bot/database"
         "upbit-bitget-trading-bot/models"
+        
+        "gorm.io/gorm"
 )
 
 // safeGoTE starts a goroutine with panic recovery and restart-on-panic loop
@@ -38,30 +41,51 @@ func safeGoTE(name string, fn func()) {
 type TradingEngine struct {
         upbitMonitor  *UpbitMonitor
         telegramBot   *TelegramBot
+        symbolMapper  *SymbolMapper // Upbit ticker -> Bitget contract
+        newExchange   ExchangeFactory // Creates the exchange client for a user's credentials
         encryptionKey string
         isRunning     bool
         stopChannel   chan bool
+        done          chan struct{} // Closed on stop to cancel scheduled entries
         
         // Concurrency controls to prevent crashes under multi-user load
         apiWorkerPool   chan struct{}           // Bounded worker pool for Bitget API calls (max 10 concurrent)
         userMutexes     map[int64]*sync.Mutex   // Per-user locks to prevent race conditions
         userMutexLock   sync.RWMutex           // Protects userMutexes map access
         updating        sync.Mutex             // Prevents overlapping position update cycles
+        
+        watchlist WatchlistConfig // Wait-and-enter settings for coins not yet on Bitget futures
+        watchlistScheduled sync.Map // Watchlist entry IDs waiting for their entry time, left out of watchlist polls
+        
+        streamURL    string                   // Private WebSocket URL, empty disables the streams
+        streams      map[int64]*positionStream // Private streams of users with open positions
+        streamsMutex sync.Mutex               // Protects streams
 }
 
 // NewTradingEngine creates a new trading engine
-func NewTradingEngine(upbitMonitor *UpbitMonitor, telegramBot *TelegramBot, encryptionKey string) *TradingEngine {
-        return &TradingEngine{
+func NewTradingEngine(upbitMonitor *UpbitMonitor, telegramBot *TelegramBot, symbolMapper *SymbolMapper, encryptionKey string) *TradingEngine {
+        te := &TradingEngine{
                 upbitMonitor:    upbitMonitor,
                 telegramBot:     telegramBot,
+                symbolMapper:    symbolMapper,
+                newExchange:     NewBitgetExchange,
                 encryptionKey:   encryptionKey,
                 isRunning:       false,
                 stopChannel:     make(chan bool),
+                done:            make(chan struct{}),
                 apiWorkerPool:   make(chan struct{}, 10), // Max 10 concurrent API calls
                 userMutexes:     make(map[int64]*sync.Mutex),
                 userMutexLock:   sync.RWMutex{},
                 updating:        sync.Mutex{},
+                watchlist:       defaultWatchlistConfig(),
+                streams:         make(map[int64]*positionStream),
         }
+        
+        // Settings changes made in Telegram move the exit orders of open positions
+        if telegramBot != nil {
+                telegramBot.tradingEngine = te
+        }
+        return te
 }
 
 // Start starts the trading engine (blocking function)
@@ -75,6 +99,12 @@ func (te *TradingEngine) Start() {
         // Start P&L monitoring for existing positions with panic recovery  
         safeGoTE("monitorPositions", te.monitorPositions)
         
+        // Enter queued coins once Bitget lists their contract
+        safeGoTE("monitorWatchlist", te.monitorWatchlist)
+        
+        // Follow the highs of trailing stop positions
+        safeGoTE("monitorTrailingStops", te.monitorTrailingStops)
+        
         // Block here to keep the main TradingEngine alive
         // This prevents supervised restart from spawning duplicate goroutines
         select {
@@ -88,6 +118,8 @@ func (te *TradingEngine) Start() {
 // Stop stops the trading engine
 func (te *TradingEngine) Stop() {
         te.isRunning = false
+        close(te.done)
+        te.stopStreams()
         te.stopChannel <- true
         log.Println("🛑 Trading engine stopped")
 }
@@ -98,9 +130,12 @@ func (te *TradingEngine) processCoinDetections() {
         
         for {
                 select {
-                case coinSymbol := <-te.upbitMonitor.GetNewCoinChannel():
-                        log.Printf("🎯 Processing new coin: %s", coinSymbol)
-                        te.handleNewCoin(coinSymbol)
+                case listing := <-te.upbitMonitor.GetNewCoinChannel():
+                        log.Printf("🎯 Processing new coin: %s (source: %s, market: %s)", listing.Symbol, listing.Source, listing.MarketType)
+                        te.handleNewCoin(listing)
+                case listing := <-te.upbitMonitor.GetRiskEventChannel():
+                        log.Printf("🚨 Processing %s event: %s (source: %s)", listing.Kind, listing.Symbol, listing.Source)
+                        te.handleRiskEvent(listing)
                 case testData := <-te.upbitMonitor.GetTestCoinChannel():
                         log.Printf("🧪 Processing test coin data: %s", testData)
                         te.handleTestCoin(testData)
@@ -111,12 +146,12 @@ func (te *TradingEngine) processCoinDetections() {
 }
 
 // handleNewCoin processes a newly detected coin with bounded concurrency
-func (te *TradingEngine) handleNewCoin(coinSymbol string) {
-        log.Printf("💰 Processing new coin detection: %s", coinSymbol)
+func (te *TradingEngine) handleNewCoin(listing CoinListing) {
+        log.Printf("💰 Processing new coin detection: %s (announcement #%s, latency %v)", listing.Symbol, listing.AnnouncementID, listing.Latency)
         
         // Check database connectivity before trading
         if !database.IsConnected() {
-                log.Printf("⚠️ Database not connected, skipping trading for coin %s", coinSymbol)
+                log.Printf("⚠️ Database not connected, skipping trading for coin %s", listing.Symbol)
                 return
         }
         
@@ -143,10 +178,20 @@ func (te *TradingEngine) handleNewCoin(coinSymbol string) {
         
         // Process trades for each active user with bounded concurrency
         for _, user := range users {
+                if !userTakesListing(user, listing) {
+                        continue
+                }
+                
                 // Capture loop variable to avoid closure issues
                 userData := user
-                coinData := coinSymbol
+                coinData := listing
                 safeGoTE("processUserTrade", func() {
+                        // Wait for the user's entry time before taking a worker slot
+                        userData, ok := te.waitForEntry(userData, &coinData)
+                        if !ok {
+                                return
+                        }
+                        
                         // Acquire worker pool slot to prevent unbounded goroutines
                         te.apiWorkerPool <- struct{}{}
                         defer func() { <-te.apiWorkerPool }() // Release slot
@@ -161,6 +206,166 @@ func (te *TradingEngine) handleNewCoin(coinSymbol string) {
         }
 }
 
+// userTakesListing reports whether a user's exchange and market filters let a listing through, logging why not
+func userTakesListing(user models.User, listing CoinListing) bool {
+        if !user.TradesExchange(listing.Exchange) {
+                log.Printf("⏭️ Skipping user %d: %s listings disabled", user.TelegramID, listing.Exchange)
+                return false
+        }
+        if !user.TradesListing(listing.Markets, listing.AddedMarket) {
+                log.Printf("⏭️ Skipping user %d: %s (%s) filtered out by market settings", user.TelegramID, listing.Symbol, listing.CategoryLabel())
+                return false
+        }
+        return true
+}
+
+// maxEntryDelay caps how long an entry waits for trading start; later start times are treated as misparsed
+const maxEntryDelay = 48 * time.Hour
+
+// waitForEntry blocks until the user's entry time for a listing (per EntryMode) and returns the user as of then
+// It returns false if the engine stops, the start time looks misparsed, or the user deactivated the bot or
+// filtered the listing out while waiting
+func (te *TradingEngine) waitForEntry(user models.User, listing *CoinListing) (models.User, bool) {
+        entryAt := user.EntryTime(listing.TradingStartsAt)
+        if entryAt.IsZero() {
+                return user, true
+        }
+        
+        delay := time.Until(entryAt)
+        if delay <= 0 {
+                return user, true
+        }
+        if delay > maxEntryDelay {
+                log.Printf("⚠️ Entry for user %d on %s is %v away, start time looks misparsed, skipping entry", user.TelegramID, listing.Symbol, delay.Round(time.Second))
+                te.telegramBot.SendEntrySkippedNotification(user.TelegramID, *listing,
+                        fmt.Sprintf("İşlem başlangıcı %s olarak okundu (%v sonra), zaman hatalı olabilir.",
+                                entryAt.In(koreaLocation()).Format("2006-01-02 15:04 MST"), delay.Round(time.Hour)))
+                return user, false
+        }
+        
+        log.Printf("⏳ User %d enters %s at %s (%s mode, in %v)", user.TelegramID, listing.Symbol,
+                entryAt.In(koreaLocation()).Format("2006-01-02 15:04:05 MST"), user.EntryMode, delay.Round(time.Second))
+        te.telegramBot.SendEntryScheduledNotification(user.TelegramID, *listing, entryAt)
+        
+        timer := time.NewTimer(delay)
+        defer timer.Stop()
+        
+        select {
+        case <-timer.C:
+                log.Printf("⏰ Entry time reached for user %d on %s", user.TelegramID, listing.Symbol)
+                listing.ReleasedAt = time.Now()
+                return te.reloadEntryUser(user, *listing)
+        case <-te.done:
+                log.Printf("🛑 Scheduled entry for user %d on %s cancelled, engine stopping", user.TelegramID, listing.Symbol)
+                return user, false
+        }
+}
+
+// reloadEntryUser reloads a user after a scheduled wait so settings changed meanwhile apply
+// A user who deactivated the bot is skipped, as is everyone while the database is unavailable
+func (te *TradingEngine) reloadEntryUser(user models.User, listing CoinListing) (models.User, bool) {
+        var current models.User
+        err := database.WithDB(func(db *gorm.DB) error {
+                return db.Where("id = ? AND is_active = ?", user.ID, true).First(&current).Error
+        })
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+                log.Printf("⏭️ Skipping scheduled entry for user %d on %s: bot deactivated", user.TelegramID, listing.Symbol)
+                return user, false
+        }
+        if err != nil {
+                log.Printf("❌ Failed to reload user %d for scheduled entry on %s: %v", user.TelegramID, listing.Symbol, err)
+                te.telegramBot.SendEntrySkippedNotification(user.TelegramID, listing, "Güncel ayarlarınız okunamadı.")
+                return user, false
+        }
+        return current, userTakesListing(current, listing)
+}
+
+// handleRiskEvent applies each user's delisting/warning policy to their open positions in the coin
+func (te *TradingEngine) handleRiskEvent(listing CoinListing) {
+        if !database.IsConnected() {
+                log.Printf("⚠️ Database not connected, cannot look up positions for %s event on %s", listing.Kind, listing.Symbol)
+                return
+        }
+        
+        var positions []models.Position
+        err := database.WithDB(func(db *gorm.DB) error {
+                return db.Preload("User").Where("coin_symbol = ? AND status = ?", listing.Symbol, models.PositionOpen).Find(&positions).Error
+        })
+        if err != nil {
+                log.Printf("❌ Failed to get open positions for %s: %v", listing.Symbol, err)
+                return
+        }
+        
+        if len(positions) == 0 {
+                log.Printf("ℹ️ No open positions in %s, nothing to do for %s event", listing.Symbol, listing.Kind)
+                return
+        }
+        
+        log.Printf("🚨 %d open positions affected by %s event on %s", len(positions), listing.Kind, listing.Symbol)
+        
+        for _, position := range positions {
+                positionData := position
+                eventData := listing
+                safeGoTE("processRiskEvent", func() {
+                        te.apiWorkerPool <- struct{}{}
+                        defer func() { <-te.apiWorkerPool }()
+                        
+                        userMutex := te.getUserMutex(positionData.User.TelegramID)
+                        userMutex.Lock()
+                        defer userMutex.Unlock()
+                        
+                        te.processRiskEvent(positionData, eventData)
+                })
+        }
+}
+
+// processRiskEvent applies the position owner's policy for a single risk event
+func (te *TradingEngine) processRiskEvent(position models.Position, listing CoinListing) {
+        action := position.User.RiskAction(listing.Kind == ListingKindDelisting)
+        log.Printf("🚨 %s event on %s for user %d: action %s", listing.Kind, position.Symbol, position.User.TelegramID, action)
+        
+        switch action {
+        case models.RiskActionIgnore:
+                return
+        case models.RiskActionNotify:
+                te.telegramBot.SendRiskEventNotification(position.User.TelegramID, listing, &position, "")
+                return
+        }
+        
+        apiKey, apiSecret, passphrase, err := position.User.GetAPICredentials(te.encryptionKey)
+        if err != nil {
+                log.Printf("❌ Failed to get API credentials for position %d: %v", position.ID, err)
+                te.telegramBot.SendRiskEventNotification(position.User.TelegramID, listing, &position, "API bilgileri alınamadı, pozisyon kapatılamadı")
+                return
+        }
+        
+        bitgetAPI := te.newExchange(apiKey, apiSecret, passphrase)
+        
+        orderResp, err := bitgetAPI.FlashClosePosition(position.Symbol, "long")
+        if err != nil {
+                if !strings.Contains(err.Error(), "22002") && !strings.Contains(err.Error(), "No position to close") {
+                        log.Printf("❌ Failed to close position %d on %s event: %v", position.ID, listing.Kind, err)
+                        te.telegramBot.SendRiskEventNotification(position.User.TelegramID, listing, &position,
+                                fmt.Sprintf("Pozisyon kapatılamadı: %v", err))
+                        return
+                }
+                log.Printf("ℹ️ Position %s already closed on Bitget", position.PositionID)
+        } else {
+                log.Printf("✅ Position %d closed on %s event, order ID: %s", position.ID, listing.Kind, orderResp.OrderID)
+        }
+        
+        position.MarkClosed(models.ExitReasonRiskEvent)
+        
+        err = database.WithDB(func(db *gorm.DB) error {
+                return db.Save(&position).Error
+        })
+        if err != nil {
+                log.Printf("❌ Failed to mark position %d closed after %s event: %v", position.ID, listing.Kind, err)
+        }
+        
+        te.telegramBot.SendRiskEventNotification(position.User.TelegramID, listing, &position, "")
+}
+
 // getUserMutex gets or creates a per-user mutex for synchronization
 func (te *TradingEngine) getUserMutex(userID int64) *sync.Mutex {
         te.userMutexLock.RLock()
@@ -184,62 +389,100 @@ func (te *TradingEngine) getUserMutex(userID int64) *sync.Mutex {
         return mutex
 }
 
-// processUserTrade processes trading for a specific user
-func (te *TradingEngine) processUserTrade(user models.User, coinSymbol string) {
-        log.Printf("🔄 Processing trade for user %d, coin %s", user.TelegramID, coinSymbol)
+// tradeOutcome is the result of processUserTrade
+type tradeOutcome struct {
+        Opened  bool
+        Skipped bool   // No position on purpose (one is already open, mapping disabled), not a failure
+        Note    string // Why no position was opened, recorded on watchlist entries
+}
+
+// processUserTrade processes trading for a specific user and reports whether a position was opened
+func (te *TradingEngine) processUserTrade(user models.User, listing CoinListing) tradeOutcome {
+        coinSymbol := listing.Symbol
+        log.Printf("🔄 Processing trade for user %d, coin %s (source: %s)", user.TelegramID, coinSymbol, listing.Source)
+        tradeAmount := user.TradeAmountFor(listing.Exchange)
         log.Printf("👤 User settings - TradeAmount: %.2f USDT, Leverage: %dx, TakeProfit: %.0f%%", 
-                user.TradeAmount, user.Leverage, user.TakeProfitPercentage)
+                tradeAmount, user.Leverage, user.TakeProfitPercentage)
+        
+        // A coin can be announced again for another market; don't stack a second position on it
+        var openCount int64
+        err := database.WithDB(func(db *gorm.DB) error {
+                return db.Model(&models.Position{}).
+                        Where("user_id = ? AND coin_symbol = ? AND status = ?", user.ID, coinSymbol, models.PositionOpen).
+                        Count(&openCount).Error
+        })
+        if err == nil && openCount > 0 {
+                log.Printf("⏭️ User %d already has an open %s position, skipping %s", user.TelegramID, coinSymbol, listing.CategoryLabel())
+                te.telegramBot.sendMessage(user.TelegramID,
+                        fmt.Sprintf("ℹ️ %s için zaten açık pozisyonunuz var, yeni pozisyon açılmadı (%s).", coinSymbol, listing.CategoryLabel()))
+                return tradeOutcome{Skipped: true, Note: "Bu coin için zaten açık pozisyon var"}
+        }
         
         // Get user's API credentials
         apiKey, apiSecret, passphrase, err := user.GetAPICredentials(te.encryptionKey)
         if err != nil {
                 log.Printf("❌ Failed to get API credentials for user %d: %v", user.TelegramID, err)
-                return
+                return tradeOutcome{Note: "API bilgileri alınamadı"}
         }
         
         // Initialize Bitget API
-        bitgetAPI := NewBitgetAPI(apiKey, apiSecret, passphrase)
-        
-        // Format symbol for Bitget (e.g., TOSHI -> TOSHIUSDT)
-        symbol := bitgetAPI.FormatSymbol(coinSymbol)
-        log.Printf("🪙 Formatted symbol: %s", symbol)
+        bitgetAPI := te.newExchange(apiKey, apiSecret, passphrase)
+        
+        // Map the Upbit ticker to its Bitget contract (e.g., TOSHI -> TOSHIUSDT, SATS -> 1000SATSUSDT)
+        decision := te.symbolMapper.Resolve(coinSymbol)
+        log.Printf("🗺️ Symbol mapping for user %d: %s", user.TelegramID, decision)
+        if !decision.Tradeable {
+                te.telegramBot.sendMessage(user.TelegramID,
+                        fmt.Sprintf("⏭️ %s işlem görmedi: Bitget sembol eşleştirmesi devre dışı (%s).", coinSymbol, escapeMarkdown(decision.Reason)))
+                return tradeOutcome{Skipped: true, Note: "Bitget sembol eşleştirmesi devre dışı"}
+        }
+        symbol := decision.BitgetSymbol
         
-        // Check if symbol exists on Bitget
+        // Check if symbol exists on Bitget; Bitget often lists the contract later, so wait for it
         if !bitgetAPI.IsSymbolValid(symbol) {
                 log.Printf("⚠️ Symbol %s not available on Bitget for user %d", symbol, user.TelegramID)
-                return
+                if listing.QueuedAt.IsZero() {
+                        te.queueForWatchlist(user, listing, symbol)
+                }
+                return tradeOutcome{Note: fmt.Sprintf("%s Bitget'te bulunamadı", symbol)}
         }
         
         // Get current price
         currentPrice, err := bitgetAPI.GetSymbolPrice(symbol)
         if err != nil {
                 log.Printf("❌ Failed to get price for %s: %v", symbol, err)
-                return
+                return tradeOutcome{Note: "Fiyat alınamadı"}
         }
         
         log.Printf("📊 Current price for %s: $%.6f", symbol, currentPrice)
         
-        // Calculate take profit price
-        takeProfitPrice := currentPrice * (1 + user.TakeProfitPercentage/100)
-        
         // Open long position using user's configured settings
         log.Printf("🚀 Opening long position for user %d: %s, amount: %.2f USDT, leverage: %dx", 
-                user.TelegramID, symbol, user.TradeAmount, user.Leverage)
+                user.TelegramID, symbol, tradeAmount, user.Leverage)
         
-        orderResp, err := bitgetAPI.OpenLongPosition(symbol, user.TradeAmount, user.Leverage)
+        orderResp, err := bitgetAPI.OpenLongPosition(symbol, tradeAmount, user.Leverage)
         if err != nil {
                 log.Printf("❌ Failed to open position for user %d: %v", user.TelegramID, err)
                 // Notify user about the error
                 te.telegramBot.sendMessage(user.TelegramID, 
                         fmt.Sprintf("❌ %s pozisyonu açılamadı: %v", symbol, err))
-                return
+                return tradeOutcome{Note: fmt.Sprintf("Pozisyon açılamadı: %v", err)}
         }
         
         log.Printf("✅ Position opened successfully for user %d, order ID: %s", user.TelegramID, orderResp.OrderID)
+        te.recordTradeLatency(bitgetAPI, user, listing, symbol, orderResp)
         
-        // Calculate position quantity based on margin and leverage
-        marginUsed := user.TradeAmount
-        quantity := (marginUsed * float64(user.Leverage)) / currentPrice
+        // Calculate position quantity based on margin and leverage, preferring what was actually sent
+        // (the size is rounded to the contract step and the leverage capped at the contract maximum)
+        leverage := user.Leverage
+        if orderResp.Leverage > 0 {
+                leverage = orderResp.Leverage
+        }
+        marginUsed := tradeAmount
+        quantity := (marginUsed * float64(leverage)) / currentPrice
+        if orderResp.Size > 0 {
+                quantity = orderResp.Size
+        }
         
         // Save position to database
         position := &models.Position{
@@ -247,15 +490,25 @@ func (te *TradingEngine) processUserTrade(user models.User, coinSymbol string) {
                 UserID:          user.ID,
                 CoinSymbol:      coinSymbol,
                 Symbol:          symbol,
+                ListingSource:   listing.Source,
+                AnnouncementID:  listing.AnnouncementID,
+                MarketType:      listing.MarketType,
                 EntryPrice:      currentPrice,
                 CurrentPrice:    currentPrice,
                 Quantity:        quantity,
-                Leverage:        user.Leverage,
-                TakeProfitPrice: takeProfitPrice,
+                Leverage:        leverage,
+                HighWaterPrice:  currentPrice,
                 CurrentPNL:      0,
                 ROE:             0,
                 Status:          models.PositionOpen,
         }
+        applyExitSettings(position, user)
+        var spec *ContractSpec
+        if contract, ok := bitgetAPI.GetContractSpec(symbol); ok {
+                spec = &contract
+        }
+        buildTranches(position, user.TakeProfitSteps(), spec)
+        te.attachExitOrders(bitgetAPI, user.TelegramID, position)
         
         err = database.WithDB(func(db *gorm.DB) error {
                 return db.Create(position).Error
@@ -273,15 +526,19 @@ func (te *TradingEngine) processUserTrade(user models.User, coinSymbol string) {
         // Send notification to user
         te.telegramBot.SendTradeNotification(
                 user.TelegramID,
-                coinSymbol,
+                listing,
                 orderResp.OrderID,
                 currentPrice,
-                takeProfitPrice,
-                user.Leverage,
-                user.TradeAmount,
+                position.TakeProfitPrice,
+                leverage,
+                tradeAmount,
         )
         
         log.Printf("📱 Trade notification sent to user %d", user.TelegramID)
+        
+        // Fills, closes and plan executions of the new position arrive over the private stream
+        te.ensureStream(user)
+        return tradeOutcome{Opened: true}
 }
 
 // monitorPositions monitors existing positions for P&L updates and take profit
@@ -319,7 +576,7 @@ func (te *TradingEngine) updateAllPositions() {
         // Get all open positions
         var positions []models.Position
         err := database.WithDB(func(db *gorm.DB) error {
-                return db.Preload("User").Where("status = ?", models.PositionOpen).Find(&positions).Error
+                return db.Preload("User").Preload("Tranches").Where("status = ?", models.PositionOpen).Find(&positions).Error
         })
         if err != nil {
                 if err.Error() == "database not available" {
@@ -330,6 +587,9 @@ func (te *TradingEngine) updateAllPositions() {
                 return
         }
         
+        // Users with open positions get a private stream, the others' streams are closed
+        te.syncStreams(positions)
+        
         if len(positions) == 0 {
                 return // No positions to update
         }
@@ -340,6 +600,12 @@ func (te *TradingEngine) updateAllPositions() {
                 // Capture loop variable to avoid closure issues
                 posData := position
                 safeGoTE("updatePositionPNL", func() {
+                        // The private stream keeps these positions current; only the P&L update is sent
+                        if te.streamConnected(posData.User.TelegramID) {
+                                te.telegramBot.SendPNLUpdate(posData.User.TelegramID, &posData)
+                                return
+                        }
+                        
                         // Use worker pool to prevent unbounded goroutines
                         te.apiWorkerPool <- struct{}{}
                         defer func() { <-te.apiWorkerPool }()
@@ -356,6 +622,12 @@ func (te *TradingEngine) updateAllPositions() {
 
 // updatePositionPNL updates P&L for a specific position
 func (te *TradingEngine) updatePositionPNL(position models.Position) {
+        te.refreshPosition(position, true)
+}
+
+// refreshPosition reads a position and its price from Bitget over REST and applies them
+// report sends the user a P&L update when the position stays open
+func (te *TradingEngine) refreshPosition(position models.Position, report bool) {
         // Get user's API credentials
         apiKey, apiSecret, passphrase, err := position.User.GetAPICredentials(te.encryptionKey)
         if err != nil {
@@ -364,34 +636,17 @@ func (te *TradingEngine) updatePositionPNL(position models.Position) {
         }
         
         // Initialize Bitget API
-        bitgetAPI := NewBitgetAPI(apiKey, apiSecret, passphrase)
+        bitgetAPI := te.newExchange(apiKey, apiSecret, passphrase)
         
         // First check if position actually exists on Bitget
+        // Only an empty answer means it is gone; rate limits and network errors keep it open until the next cycle
         bitgetPosition, err := bitgetAPI.GetPosition(position.Symbol)
+        if err != nil && !strings.Contains(err.Error(), "no position found") {
+                log.Printf("⚠️ Failed to check position %s on Bitget, keeping it open: %v", position.PositionID, err)
+                return
+        }
         if err != nil || bitgetPosition == nil || bitgetPosition.Size == "0" {
-                log.Printf("📊 Position %s no longer exists on Bitget, marking as closed in database", position.PositionID)
-                
-                // Position doesn't exist on Bitget anymore, mark as closed
-                now := time.Now()
-                position.Status = models.PositionClosed
-                position.ClosedAt = &now
-                
-                err = database.WithDB(func(db *gorm.DB) error {
-                        return db.Save(&position).Error
-                })
-                if err != nil {
-                        if err.Error() == "database not available" {
-                                log.Printf("⚠️ Database unavailable, position close not saved")
-                        } else {
-                                log.Printf("❌ Failed to close position %d in database: %v", position.ID, err)
-                        }
-                } else {
-                        log.Printf("✅ Position %s automatically closed in database", position.PositionID)
-                        
-                        // Notify user that position was closed
-                        te.telegramBot.sendMessage(position.User.TelegramID, 
-                                fmt.Sprintf("ℹ️ Position %s was automatically closed (no longer exists on Bitget)", position.Symbol))
-                }
+                te.closeGonePosition(position, bitgetAPI)
                 return
         }
         
@@ -402,12 +657,72 @@ func (te *TradingEngine) updatePositionPNL(position models.Position) {
                 return
         }
         
+        exchangeSize, _ := strconv.ParseFloat(bitgetPosition.Size, 64)
+        te.applyPositionState(position, bitgetAPI, exchangeSize, currentPrice, report)
+}
+
+// closeGonePosition records a position that no longer exists on Bitget as closed and tells the user why
+func (te *TradingEngine) closeGonePosition(position models.Position, bitgetAPI Exchange) {
+        // Position doesn't exist on Bitget anymore, mark as closed with the plan order that closed it
+        // Take profit steps filled on the way are recorded first; the last one may have closed it
+        te.processTranches(&position, bitgetAPI, 0, 0)
+        reason := exchangeExitReason(bitgetAPI, position)
+        if reason == models.ExitReasonExternal && ladderClosed(&position) {
+                reason = models.ExitReasonTakeProfit
+        }
+        log.Printf("📊 Position %s no longer exists on Bitget (%s), marking as closed in database", position.PositionID, reason)
+        position.MarkClosed(reason)
+        
+        err := database.WithDB(func(db *gorm.DB) error {
+                return db.Save(&position).Error
+        })
+        if err != nil {
+                if err.Error() == "database not available" {
+                        log.Printf("⚠️ Database unavailable, position close not saved")
+                } else {
+                        log.Printf("❌ Failed to close position %d in database: %v", position.ID, err)
+                }
+        } else {
+                log.Printf("✅ Position %s automatically closed in database", position.PositionID)
+                
+                // Notify user that position was closed
+                switch reason {
+                case models.ExitReasonStopLoss:
+                        position.CurrentPrice = position.StopLossPrice
+                        position.CalculatePNL()
+                        te.telegramBot.SendStopLossNotification(position.User.TelegramID, &position, true)
+                case models.ExitReasonTrailingStop:
+                        position.CurrentPrice = position.TrailingStopPrice()
+                        position.CalculatePNL()
+                        te.telegramBot.SendTrailingStopNotification(position.User.TelegramID, &position, true)
+                case models.ExitReasonTakeProfit:
+                        if ladderClosed(&position) {
+                                te.telegramBot.sendMessage(position.User.TelegramID,
+                                        fmt.Sprintf("🪜 %s kademeli take profit tamamlandı, pozisyon kapandı. Toplam gerçekleşen P&L: $%.2f", position.Symbol, position.RealizedPNL))
+                                break
+                        }
+                        te.telegramBot.sendMessage(position.User.TelegramID,
+                                fmt.Sprintf("🎯 %s take profit emri Bitget'te tetiklendi, pozisyon $%.6f seviyesinden kapandı.", position.Symbol, position.TakeProfitPrice))
+                default:
+                        te.telegramBot.sendMessage(position.User.TelegramID, 
+                                fmt.Sprintf("ℹ️ Position %s was automatically closed (no longer exists on Bitget)", position.Symbol))
+                }
+        }
+}
+
+// applyPositionState applies the Bitget size and price of an open position: P&L, take profit steps and the
+// local exit fallbacks; report sends the user a P&L update when the position stays open
+func (te *TradingEngine) applyPositionState(position models.Position, bitgetAPI Exchange, exchangeSize float64, currentPrice float64, report bool) {
         // Update position with current price and calculate P&L
         position.CurrentPrice = currentPrice
+        position.UpdateHighWater(currentPrice)
+        
+        // Take profit steps filled on Bitget, or due with no order, shrink the open quantity
+        te.processTranches(&position, bitgetAPI, exchangeSize, currentPrice)
         position.CalculatePNL()
         
-        // Save updated position
-        err = database.WithDB(func(db *gorm.DB) error {
+        // Save updated position; take profit still runs when the database is down
+        err := database.WithDB(func(db *gorm.DB) error {
                 return db.Save(&position).Error
         })
         if err != nil {
@@ -416,6 +731,19 @@ func (te *TradingEngine) updatePositionPNL(position models.Position) {
                 } else {
                         log.Printf("❌ Failed to update position %d: %v", position.ID, err)
                 }
+        }
+        
+        // Fallback for the exchange-side stop: a missing or failed plan order must not let the position ride to liquidation
+        if position.ShouldStopLoss() {
+                log.Printf("🛑 Stop loss triggered for position %d (%s)", position.ID, position.Symbol)
+                te.executeStopLoss(position, bitgetAPI)
+                return
+        }
+        
+        // Trailing stops without a Bitget order are normally closed by monitorTrailingStops
+        if position.TrailingOrderID == "" && position.ShouldTrailingStop() {
+                log.Printf("📉 Trailing stop triggered for position %d (%s)", position.ID, position.Symbol)
+                te.executeTrailingStop(position, bitgetAPI)
                 return
         }
         
@@ -427,37 +755,28 @@ func (te *TradingEngine) updatePositionPNL(position models.Position) {
         }
         
         // Send P&L update to user
+        if !report {
+                return
+        }
         te.telegramBot.SendPNLUpdate(position.User.TelegramID, &position)
 }
 
 // executeTakeProfit executes take profit for a position
-func (te *TradingEngine) executeTakeProfit(position models.Position, bitgetAPI *BitgetAPI) {
+func (te *TradingEngine) executeTakeProfit(position models.Position, bitgetAPI Exchange) {
         log.Printf("💰 Executing take profit for position %d", position.ID)
         
-        // Close the position
-        _, err := bitgetAPI.ClosePosition(position.Symbol, position.Quantity, PositionSideLong)
+        alreadyClosed, err := te.closeForExit(&position, bitgetAPI, models.ExitReasonTakeProfit)
         if err != nil {
-                log.Printf("❌ Failed to close position %d: %v", position.ID, err)
                 // Notify user about the error
                 te.telegramBot.sendMessage(position.User.TelegramID,
                         fmt.Sprintf("❌ Take profit pozisyonu kapatılamadı: %v", err))
                 return
         }
         
-        // Update position status
-        position.Status = models.PositionClosed
-        closedAt := time.Now()
-        position.ClosedAt = &closedAt
-        
-        err = database.WithDB(func(db *gorm.DB) error {
-                return db.Save(&position).Error
-        })
-        if err != nil {
-                if err.Error() == "database not available" {
-                        log.Printf("⚠️ Database unavailable, take profit close not saved")
-                } else {
-                        log.Printf("❌ Failed to update closed position %d: %v", position.ID, err)
-                }
+        if alreadyClosed {
+                te.telegramBot.sendMessage(position.User.TelegramID,
+                        fmt.Sprintf("ℹ️ %s take profit seviyesine ulaştı ancak pozisyon Bitget'te zaten kapalıydı.", position.Symbol))
+                return
         }
         
         // Notify user about successful take profit
@@ -481,6 +800,57 @@ func (te *TradingEngine) executeTakeProfit(position models.Position, bitgetAPI *
         log.Printf("✅ Take profit executed successfully for position %d", position.ID)
 }
 
+// executeStopLoss closes a position that fell through its stop price before the exchange-side stop did
+func (te *TradingEngine) executeStopLoss(position models.Position, bitgetAPI Exchange) {
+        log.Printf("🛑 Executing stop loss for position %d", position.ID)
+        
+        alreadyClosed, err := te.closeForExit(&position, bitgetAPI, models.ExitReasonStopLoss)
+        if err != nil {
+                te.telegramBot.sendMessage(position.User.TelegramID,
+                        fmt.Sprintf("❌ Stop loss pozisyonu kapatılamadı: %v", err))
+                return
+        }
+        
+        if alreadyClosed && position.ExitReason != models.ExitReasonStopLoss {
+                te.telegramBot.sendMessage(position.User.TelegramID,
+                        fmt.Sprintf("ℹ️ %s stop loss seviyesine indi ancak pozisyon Bitget'te zaten kapalıydı.", position.Symbol))
+                return
+        }
+        
+        // Already gone with an executed stop plan means the Bitget stop got there first
+        te.telegramBot.SendStopLossNotification(position.User.TelegramID, &position, alreadyClosed)
+        log.Printf("✅ Stop loss executed for position %d", position.ID)
+}
+
+// closeForExit flash closes a position and records it closed with the exit reason
+// A position already gone on Bitget is only closed in the database, with the reason read from its plan orders
+func (te *TradingEngine) closeForExit(position *models.Position, bitgetAPI Exchange, reason string) (bool, error) {
+        _, err := bitgetAPI.ClosePosition(position.Symbol, position.Quantity, PositionSideLong)
+        alreadyClosed := err != nil && (strings.Contains(err.Error(), "22002") || strings.Contains(err.Error(), "No position to close"))
+        if alreadyClosed {
+                // Closed on Bitget in the meantime (plan order, manually or by liquidation); only the record is left to close
+                log.Printf("ℹ️ Position %s already closed on Bitget", position.PositionID)
+                reason = exchangeExitReason(bitgetAPI, *position)
+        } else if err != nil {
+                log.Printf("❌ Failed to close position %d: %v", position.ID, err)
+                return false, err
+        }
+        
+        position.MarkClosed(reason)
+        
+        err = database.WithDB(func(db *gorm.DB) error {
+                return db.Save(position).Error
+        })
+        if err != nil {
+                if err.Error() == "database not available" {
+                        log.Printf("⚠️ Database unavailable, %s close not saved", reason)
+                } else {
+                        log.Printf("❌ Failed to update closed position %d: %v", position.ID, err)
+                }
+        }
+        return alreadyClosed, nil
+}
+
 // handleTestCoin processes a test coin for a specific user only
 func (te *TradingEngine) handleTestCoin(testData string) {
         // Parse testData: "coinSymbol:userID"
@@ -518,5 +888,9 @@ func (te *TradingEngine) handleTestCoin(testData string) {
         log.Printf("🧪 Test trade for user %d with coin %s", user.ID, coinSymbol)
         
         // Process trade for this user only - NO OTHER USERS
-        te.processUserTrade(user, coinSymbol)
+        te.processUserTrade(user, CoinListing{
+                Symbol:     coinSymbol,
+                Source:     "user_test",
+                DetectedAt: time.Now(),
+        })
 }
@@ -0,0 +1,277 @@
+package services
+
+import (
+        "math"
+        "testing"
+        "time"
+        "upbit-bitget-trading-bot/models"
+)
+
+const testEncryptionKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=" // base64 of 32 bytes
+
+// newTestEngine wires a trading engine to the fake exchange and a fake Telegram; the database stays disconnected
+func newTestEngine(t *testing.T, fake *fakeBitget) (*TradingEngine, *fakeTelegram) {
+        t.Helper()
+        
+        bot, telegram := newFakeTelegramBot(t)
+        engine := NewTradingEngine(nil, bot, NewSymbolMapper(), testEncryptionKey)
+        engine.newExchange = fake.exchange()
+        return engine, telegram
+}
+
+func newTestUser(t *testing.T, fake *fakeBitget, secret string) models.User {
+        t.Helper()
+        
+        user := models.User{
+                ID:                   7,
+                TelegramID:           1001,
+                TradeAmount:          100,
+                Leverage:             5,
+                TakeProfitPercentage: 100,
+                IsActive:             true,
+                TradeUpbit:           true,
+                TradeNewListings:     true,
+        }
+        if err := user.SetAPICredentials(fake.apiKey, secret, fake.passphrase, testEncryptionKey); err != nil {
+                t.Fatalf("SetAPICredentials: %v", err)
+        }
+        return user
+}
+
+func TestProcessUserTradeOpensPosition(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setPrice("NEWUSDT", 2)
+        engine, telegram := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        
+        listing := CoinListing{Symbol: "NEW", Kind: ListingKindNew, Source: "upbit_notice_api", MarketType: MarketKRW, DetectedAt: time.Now()}
+        if !engine.processUserTrade(user, listing).Opened {
+                t.Fatal("processUserTrade did not open a position")
+        }
+        
+        // 100 USDT margin at 5x and price 2 buys 250
+        position := fake.position("NEWUSDT")
+        if position == nil || math.Abs(position.size-250) > 1e-6 {
+                t.Fatalf("unexpected exchange position %+v", position)
+        }
+        if fake.leverage["NEWUSDT"] != 5 {
+                t.Errorf("leverage = %d, want 5", fake.leverage["NEWUSDT"])
+        }
+        if !telegram.sentContaining(user.TelegramID, "NEW") {
+                t.Errorf("no trade notification sent: %v", telegram.sent(user.TelegramID))
+        }
+}
+
+func TestProcessUserTradeRejectedSignature(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setPrice("NEWUSDT", 2)
+        engine, telegram := newTestEngine(t, fake)
+        user := newTestUser(t, fake, "wrong-secret")
+        
+        if engine.processUserTrade(user, CoinListing{Symbol: "NEW", Kind: ListingKindNew, DetectedAt: time.Now()}).Opened {
+                t.Fatal("trade succeeded with a bad signature")
+        }
+        if fake.position("NEWUSDT") != nil || fake.requestCount("/api/v2/mix/order/place-order") != 0 {
+                t.Error("order reached the exchange")
+        }
+        if !telegram.sentContaining(user.TelegramID, "açılamadı") {
+                t.Errorf("user not told about the failure: %v", telegram.sent(user.TelegramID))
+        }
+}
+
+func TestProcessUserTradeRetriesRateLimit(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setPrice("NEWUSDT", 2)
+        fake.fail("/api/v2/mix/order/place-order", fakeRateLimit, 2)
+        engine, _ := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        
+        if !engine.processUserTrade(user, CoinListing{Symbol: "NEW", Kind: ListingKindNew, DetectedAt: time.Now()}).Opened {
+                t.Fatal("rate limited order was not retried")
+        }
+        if got := fake.requestCount("/api/v2/mix/order/place-order"); got != 3 {
+                t.Errorf("place-order requests = %d, want 3", got)
+        }
+        if fake.position("NEWUSDT") == nil {
+                t.Error("no position after retry")
+        }
+}
+
+func newTestPosition(user models.User, entryPrice float64) models.Position {
+        return models.Position{
+                ID:              1,
+                PositionID:      "order-0",
+                UserID:          user.ID,
+                User:            user,
+                CoinSymbol:      "NEW",
+                Symbol:          "NEWUSDT",
+                EntryPrice:      entryPrice,
+                CurrentPrice:    entryPrice,
+                Quantity:        250,
+                Leverage:        5,
+                TakeProfitPrice: entryPrice * 2,
+                Status:          models.PositionOpen,
+                OpenedAt:        time.Now().Add(-time.Hour),
+        }
+}
+
+func TestUpdatePositionPNLTakesProfit(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setPrice("NEWUSDT", 4.5)
+        fake.openPosition("NEWUSDT", 250, 2)
+        engine, telegram := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        
+        engine.updatePositionPNL(newTestPosition(user, 2))
+        
+        if fake.position("NEWUSDT") != nil {
+                t.Error("position still open on the exchange after take profit")
+        }
+        if !telegram.sentContaining(user.TelegramID, "TAKE PROFIT EXECUTED") {
+                t.Errorf("no take profit notification: %v", telegram.sent(user.TelegramID))
+        }
+}
+
+func TestUpdatePositionPNLBelowTarget(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setPrice("NEWUSDT", 2.5)
+        fake.openPosition("NEWUSDT", 250, 2)
+        engine, telegram := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        
+        engine.updatePositionPNL(newTestPosition(user, 2))
+        
+        if fake.position("NEWUSDT") == nil {
+                t.Error("position closed below the take profit price")
+        }
+        if !telegram.sentContaining(user.TelegramID, "POZİSYON DURUMU") {
+                t.Errorf("no P&L update: %v", telegram.sent(user.TelegramID))
+        }
+}
+
+func TestUpdatePositionPNLKeepsPositionWhenRateLimited(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setPrice("NEWUSDT", 4.5)
+        fake.openPosition("NEWUSDT", 250, 2)
+        fake.fail("/api/v2/mix/position/single-position", fakeRateLimit, 4)
+        engine, telegram := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        
+        engine.updatePositionPNL(newTestPosition(user, 2))
+        
+        if fake.position("NEWUSDT") == nil || fake.requestCount("/api/v2/mix/order/close-positions") != 0 {
+                t.Error("rate limited position check closed the position")
+        }
+        if len(telegram.sent(user.TelegramID)) != 0 {
+                t.Errorf("unexpected messages: %v", telegram.sent(user.TelegramID))
+        }
+}
+
+func TestUpdatePositionPNLGoneOnExchange(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setPrice("NEWUSDT", 4.5)
+        engine, telegram := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        
+        engine.updatePositionPNL(newTestPosition(user, 2))
+        
+        // The close is not persisted with the database down, so the user isn't told it was
+        if fake.requestCount("/api/v2/mix/order/close-positions") != 0 {
+                t.Error("tried to close a position that no longer exists")
+        }
+        if len(telegram.sent(user.TelegramID)) != 0 {
+                t.Errorf("unexpected messages: %v", telegram.sent(user.TelegramID))
+        }
+}
+
+func TestExecuteTakeProfitAlreadyClosed(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setPrice("NEWUSDT", 4.5)
+        engine, telegram := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        
+        position := newTestPosition(user, 2)
+        position.CurrentPrice = 4.5
+        engine.executeTakeProfit(position, engine.newExchange(fake.apiKey, fake.apiSecret, fake.passphrase))
+        
+        if !telegram.sentContaining(user.TelegramID, "zaten kapalıydı") {
+                t.Errorf("22002 not reported as already closed: %v", telegram.sent(user.TelegramID))
+        }
+}
+
+func TestProcessUserTradeRoundsToContractStep(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setContract(ContractInfo{Symbol: "NEWUSDT", SymbolStatus: "normal", PricePlace: "6", PriceEndStep: "1",
+                VolumePlace: "0", SizeMultiplier: "10", MinTradeNum: "10", MinTradeUSDT: "5", MaxLever: "3"})
+        fake.setPrice("NEWUSDT", 0.0333)
+        engine, telegram := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        
+        if !engine.processUserTrade(user, CoinListing{Symbol: "NEW", Kind: ListingKindNew, DetectedAt: time.Now()}).Opened {
+                t.Fatalf("trade failed: %v", telegram.sent(user.TelegramID))
+        }
+        
+        // 100 USDT at 3x (capped from 5x) and price 0.0333 is 9009.009, rounded down to a multiple of 10
+        position := fake.position("NEWUSDT")
+        if position == nil || position.size != 9000 {
+                t.Fatalf("unexpected exchange position %+v", position)
+        }
+        if fake.leverage["NEWUSDT"] != 3 {
+                t.Errorf("leverage = %d, want the contract maximum 3", fake.leverage["NEWUSDT"])
+        }
+}
+
+func TestProcessUserTradeBelowContractMinimum(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setContract(ContractInfo{Symbol: "NEWUSDT", SymbolStatus: "normal", VolumePlace: "2", SizeMultiplier: "0.01",
+                MinTradeNum: "0.01", MinTradeUSDT: "5", MaxLever: "125"})
+        fake.setPrice("NEWUSDT", 2)
+        engine, telegram := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        user.TradeAmount = 0.5
+        user.Leverage = 2
+        
+        if engine.processUserTrade(user, CoinListing{Symbol: "NEW", Kind: ListingKindNew, DetectedAt: time.Now()}).Opened {
+                t.Fatal("order below the minimum value succeeded")
+        }
+        if fake.requestCount("/api/v2/mix/order/place-order") != 0 {
+                t.Error("order below the minimum reached the exchange")
+        }
+        if fake.requestCount("/api/v2/mix/account/set-leverage") != 0 {
+                t.Error("refused order changed the account's leverage")
+        }
+        if !telegram.sentContaining(user.TelegramID, "minimum") {
+                t.Errorf("user not told about the minimum: %v", telegram.sent(user.TelegramID))
+        }
+}
+
+func TestWaitForEntrySkipsMisparsedStartTime(t *testing.T) {
+        fake := newFakeBitget(t)
+        engine, telegram := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        user.EntryMode = models.EntryModeTradingStart
+        
+        listing := CoinListing{Symbol: "NEW", Kind: ListingKindNew, TradingStartsAt: time.Now().Add(72 * time.Hour)}
+        if _, ok := engine.waitForEntry(user, &listing); ok {
+                t.Fatal("entry three days out was not skipped")
+        }
+        if !telegram.sentContaining(user.TelegramID, "GİRİŞ ATLANDI") {
+                t.Errorf("no skip notification: %v", telegram.sent(user.TelegramID))
+        }
+}
+
+func TestWaitForEntryReloadsUser(t *testing.T) {
+        fake := newFakeBitget(t)
+        engine, telegram := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        user.EntryMode = models.EntryModeTradingStart
+        
+        // Without a database the settings after the wait are unknown, so the snapshot must not trade
+        listing := CoinListing{Symbol: "NEW", Kind: ListingKindNew, TradingStartsAt: time.Now().Add(20 * time.Millisecond)}
+        if _, ok := engine.waitForEntry(user, &listing); ok {
+                t.Fatal("entered with the user snapshot from detection time")
+        }
+        if !telegram.sentContaining(user.TelegramID, "GİRİŞ PLANLANDI") || !telegram.sentContaining(user.TelegramID, "GİRİŞ ATLANDI") {
+                t.Errorf("unexpected messages: %v", telegram.sent(user.TelegramID))
+        }
+}
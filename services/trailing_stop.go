@@ -0,0 +1,126 @@
+package services
+
+import (
+        "fmt"
+        "log"
+        "time"
+        "upbit-bitget-trading-bot/database"
+        "upbit-bitget-trading-bot/models"
+
+        "gorm.io/gorm"
+)
+
+// trailingPollInterval is how often the trailing watcher reads prices; much shorter than the P&L cycle
+// because a trail only protects profit if the high and the drop are both seen
+var trailingPollInterval = 5 * time.Second
+
+// monitorTrailingStops follows the high-water mark of every trailing stop position and closes positions
+// whose trail is not held by a Bitget moving_plan (callbacks wider than Bitget allows, or a failed order)
+func (te *TradingEngine) monitorTrailingStops() {
+        log.Println("📉 Starting trailing stop monitoring...")
+        
+        ticker := time.NewTicker(trailingPollInterval)
+        defer ticker.Stop()
+        
+        for {
+                select {
+                case <-ticker.C:
+                        te.updateTrailingStops()
+                case <-te.done:
+                        return
+                }
+        }
+}
+
+// updateTrailingStops reads one public price per symbol and applies it to the trailing positions in it
+func (te *TradingEngine) updateTrailingStops() {
+        if !database.IsConnected() {
+                return
+        }
+        
+        var positions []models.Position
+        err := database.WithDB(func(db *gorm.DB) error {
+                return db.Preload("User").Where("status = ? AND trailing_callback_rate > 0", models.PositionOpen).Find(&positions).Error
+        })
+        if err != nil || len(positions) == 0 {
+                return
+        }
+        
+        publicAPI := te.newExchange("", "", "")
+        prices := make(map[string]float64)
+        for _, position := range positions {
+                price, ok := prices[position.Symbol]
+                if !ok {
+                        price, err = publicAPI.GetSymbolPrice(position.Symbol)
+                        if err != nil {
+                                log.Printf("⚠️ Trailing stop price for %s unavailable: %v", position.Symbol, err)
+                                continue
+                        }
+                        prices[position.Symbol] = price
+                }
+                
+                // A position busy with a trade or P&L update is picked up on the next tick
+                userMutex := te.getUserMutex(position.User.TelegramID)
+                if !userMutex.TryLock() {
+                        continue
+                }
+                te.trailPosition(position, price)
+                userMutex.Unlock()
+        }
+}
+
+// trailPosition raises the high-water mark of a trailing position and closes it when the trail is hit
+func (te *TradingEngine) trailPosition(position models.Position, price float64) {
+        position.CurrentPrice = price
+        wasArmed := position.TrailingArmed()
+        
+        if position.UpdateHighWater(price) {
+                err := database.WithDB(func(db *gorm.DB) error {
+                        return db.Model(&position).Update("high_water_price", position.HighWaterPrice).Error
+                })
+                if err != nil {
+                        log.Printf("⚠️ Failed to save high-water mark of position %d: %v", position.ID, err)
+                }
+                
+                if !wasArmed && position.TrailingArmed() {
+                        log.Printf("📈 Trailing stop armed for position %d (%s) at %.6f", position.ID, position.Symbol, price)
+                        te.telegramBot.sendMessage(position.User.TelegramID,
+                                fmt.Sprintf("📈 %s trailing stop aktif: zirve $%.6f, stop $%.6f (%g%% geri çekilme)",
+                                        position.Symbol, position.HighWaterPrice, position.TrailingStopPrice(), position.TrailingCallbackRate))
+                }
+        }
+        
+        // Bitget's moving_plan holds the trail; it is read back when the position disappears
+        if position.TrailingOrderID != "" || !position.ShouldTrailingStop() {
+                return
+        }
+        
+        log.Printf("📉 Trailing stop hit for position %d (%s): %.6f <= %.6f (high %.6f)",
+                position.ID, position.Symbol, price, position.TrailingStopPrice(), position.HighWaterPrice)
+        
+        apiKey, apiSecret, passphrase, err := position.User.GetAPICredentials(te.encryptionKey)
+        if err != nil {
+                log.Printf("❌ Failed to get API credentials for position %d: %v", position.ID, err)
+                return
+        }
+        position.CalculatePNL()
+        te.executeTrailingStop(position, te.newExchange(apiKey, apiSecret, passphrase))
+}
+
+// executeTrailingStop closes a position whose locally followed trail was hit
+func (te *TradingEngine) executeTrailingStop(position models.Position, bitgetAPI Exchange) {
+        alreadyClosed, err := te.closeForExit(&position, bitgetAPI, models.ExitReasonTrailingStop)
+        if err != nil {
+                te.telegramBot.sendMessage(position.User.TelegramID,
+                        fmt.Sprintf("❌ Trailing stop pozisyonu kapatılamadı: %v", err))
+                return
+        }
+        if alreadyClosed && position.ExitReason != models.ExitReasonTrailingStop {
+                te.telegramBot.sendMessage(position.User.TelegramID,
+                        fmt.Sprintf("ℹ️ %s trailing stop seviyesine indi ancak pozisyon Bitget'te zaten kapalıydı.", position.Symbol))
+                return
+        }
+        
+        te.telegramBot.SendTrailingStopNotification(position.User.TelegramID, &position, alreadyClosed)
+        log.Printf("✅ Trailing stop executed for position %d", position.ID)
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+        "strings"
+        "testing"
+        "time"
+        "upbit-bitget-trading-bot/models"
+)
+
+func TestProcessUserTradeAttachesTrailingStop(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setPrice("NEWUSDT", 2)
+        engine, _ := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        user.ExitMode = models.ExitModeTrailing
+        user.TrailingActivationPercentage = 100
+        user.TrailingCallbackPercentage = 10
+        
+        if !engine.processUserTrade(user, CoinListing{Symbol: "NEW", Kind: ListingKindNew, DetectedAt: time.Now()}).Opened {
+                t.Fatal("processUserTrade did not open a position")
+        }
+        
+        if fake.plan("NEWUSDT", PlanTypePositionProfit) != nil {
+                t.Error("fixed take profit placed in trailing mode")
+        }
+        trail := fake.plan("NEWUSDT", PlanTypeMovingPlan)
+        if trail == nil || trail.triggerPrice != 4 || trail.rangeRate != 10 {
+                t.Fatalf("trailing plan = %+v, want trigger 4 and 10%% callback", trail)
+        }
+        
+        // Armed at 4.5, new high at 5; an 8% pullback holds, a 12% one closes
+        for _, price := range []float64{4.5, 5, 4.6} {
+                fake.setPrice("NEWUSDT", price)
+        }
+        if fake.position("NEWUSDT") == nil {
+                t.Fatal("trailing stop closed within the callback")
+        }
+        fake.setPrice("NEWUSDT", 4.4)
+        if fake.position("NEWUSDT") != nil {
+                t.Error("trailing stop did not close after the callback")
+        }
+}
+
+func TestTrailPositionLocalFallback(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setPrice("NEWUSDT", 2)
+        fake.openPosition("NEWUSDT", 250, 2)
+        engine, telegram := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        
+        // A 20% callback is wider than Bitget's moving_plan allows, so no order is placed and the watcher trails it
+        position := newTestPosition(user, 2)
+        position.TakeProfitPrice = 0
+        position.TrailingActivationPrice = 4
+        position.TrailingCallbackRate = 20
+        position.HighWaterPrice = 2
+        api := fake.exchange()(fake.apiKey, fake.apiSecret, fake.passphrase)
+        if err := syncExitOrders(api, &position); err != nil || position.TrailingOrderID != "" {
+                t.Fatalf("wide trail: order %q, err %v", position.TrailingOrderID, err)
+        }
+        
+        engine.trailPosition(position, 5)
+        if !telegram.sentContaining(user.TelegramID, "trailing stop aktif") {
+                t.Errorf("arming not reported: %v", telegram.sent(user.TelegramID))
+        }
+        
+        position.HighWaterPrice = 5 // Saved by trailPosition when the database is up
+        engine.trailPosition(position, 4.1)
+        if fake.position("NEWUSDT") == nil {
+                t.Fatal("closed within the callback")
+        }
+        
+        engine.trailPosition(position, 3.9)
+        if fake.position("NEWUSDT") != nil {
+                t.Error("position still open 22% below the high")
+        }
+        if !telegram.sentContaining(user.TelegramID, "TRAILING STOP TETİKLENDİ") {
+                t.Errorf("no trailing stop notification: %v", telegram.sent(user.TelegramID))
+        }
+}
+
+func TestPositionExitLine(t *testing.T) {
+        position := &models.Position{TakeProfitPrice: 4}
+        if line := positionExitLine(position); !strings.Contains(line, "TP: $4.000000") {
+                t.Errorf("fixed: %s", line)
+        }
+        
+        position = &models.Position{TrailingActivationPrice: 4, TrailingCallbackRate: 10, HighWaterPrice: 3}
+        if line := positionExitLine(position); !strings.Contains(line, "aktifleşir") || !strings.Contains(line, "zirve $3.000000") {
+                t.Errorf("waiting: %s", line)
+        }
+        
+        position.HighWaterPrice = 5
+        if line := positionExitLine(position); !strings.Contains(line, "aktif, zirve $5.000000, stop $4.500000") {
+                t.Errorf("armed: %s", line)
+        }
+}
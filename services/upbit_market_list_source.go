@@ -0,0 +1,231 @@
+package services
+
+import (
+        "encoding/json"
+        "fmt"
+        "io"
+        "log"
+        "net/http"
+        "strings"
+        "sync"
+        "time"
+        "upbit-bitget-trading-bot/database"
+        "upbit-bitget-trading-bot/models"
+
+        "gorm.io/gorm"
+        "gorm.io/gorm/clause"
+)
+
+// UpbitMarketListSource polls Upbit's tradeable market list and diffs it against a stored snapshot
+// Catches new KRW-/BTC-/USDT- markets even when no announcement was parsed
+type UpbitMarketListSource struct {
+        endpoint   string
+        interval   time.Duration
+        httpClient *http.Client
+        state      httpPollState
+        
+        snapshotMutex sync.Mutex
+        snapshot      map[string]bool // Known market codes (KRW-BTC, ...)
+        snapshotReady bool
+        unsaved       []models.UpbitMarket // Snapshot entries not yet persisted (database unavailable)
+}
+
+// maxNewMarketsPerPoll caps how many new markets a single diff may emit
+// A larger diff means the stored snapshot is stale or partial, so it is treated as a re-baseline
+const maxNewMarketsPerPoll = 10
+
+// upbitMarket is one entry of the /v1/market/all response
+type upbitMarket struct {
+        Market      string `json:"market"`
+        KoreanName  string `json:"korean_name"`
+        EnglishName string `json:"english_name"`
+}
+
+// NewUpbitMarketListSource creates the market list diff source
+func NewUpbitMarketListSource(interval time.Duration, client *http.Client) *UpbitMarketListSource {
+        return &UpbitMarketListSource{
+                endpoint:   "https://api.upbit.com/v1/market/all?is_details=false",
+                interval:   interval,
+                httpClient: client,
+                state:      httpPollState{name: "upbit_market_list"},
+                snapshot:   make(map[string]bool),
+        }
+}
+
+// Name returns the source identifier
+func (s *UpbitMarketListSource) Name() string {
+        return "upbit_market_list"
+}
+
+// Interval returns the base polling interval
+func (s *UpbitMarketListSource) Interval() time.Duration {
+        return s.interval
+}
+
+// Poll fetches the market list and returns one listing per market not in the snapshot
+func (s *UpbitMarketListSource) Poll() ([]CoinListing, error) {
+        if s.state.inBackoff() {
+                return nil, nil
+        }
+        
+        req, err := http.NewRequest("GET", s.endpoint, nil)
+        if err != nil {
+                s.state.handleError()
+                return nil, fmt.Errorf("failed to create request: %w", err)
+        }
+        req.Header.Set("Accept", "application/json")
+        s.state.applyConditionalHeaders(req)
+        
+        resp, err := s.httpClient.Do(req)
+        if err != nil {
+                s.state.handleError()
+                return nil, fmt.Errorf("failed to fetch Upbit market list: %w", err)
+        }
+        defer resp.Body.Close()
+        
+        if !s.state.handleStatus(resp) {
+                return nil, nil
+        }
+        
+        body, err := io.ReadAll(resp.Body)
+        if err != nil {
+                s.state.handleError()
+                return nil, fmt.Errorf("failed to read market list: %w", err)
+        }
+        
+        var markets []upbitMarket
+        if err := json.Unmarshal(body, &markets); err != nil {
+                s.state.handleError()
+                return nil, fmt.Errorf("failed to parse market list: %w", err)
+        }
+        
+        return s.diff(markets), nil
+}
+
+// diff compares the fetched list with the snapshot, stores new markets and returns them as listings
+func (s *UpbitMarketListSource) diff(markets []upbitMarket) []CoinListing {
+        s.snapshotMutex.Lock()
+        defer s.snapshotMutex.Unlock()
+        
+        s.loadSnapshot()
+        
+        // Coins already tradeable before this diff, to tell added markets from brand new listings
+        listedBases := make(map[string]bool)
+        for code := range s.snapshot {
+                if _, base, ok := splitUpbitMarket(code); ok {
+                        listedBases[base] = true
+                }
+        }
+        
+        now := time.Now()
+        var newMarkets []models.UpbitMarket
+        for _, market := range markets {
+                if s.snapshot[market.Market] {
+                        continue
+                }
+                
+                quote, base, ok := splitUpbitMarket(market.Market)
+                if !ok {
+                        log.Printf("⚠️ [%s] Ignoring malformed market code: %s", s.Name(), market.Market)
+                        continue
+                }
+                
+                s.snapshot[market.Market] = true
+                newMarkets = append(newMarkets, models.UpbitMarket{
+                        Market:        market.Market,
+                        QuoteCurrency: quote,
+                        BaseCurrency:  base,
+                        KoreanName:    market.KoreanName,
+                        EnglishName:   market.EnglishName,
+                        FirstSeenAt:   now,
+                })
+        }
+        
+        s.saveSnapshot(newMarkets)
+        
+        // First run without a stored snapshot only establishes the baseline
+        if !s.snapshotReady {
+                s.snapshotReady = true
+                log.Printf("📸 [%s] Baseline snapshot of %d markets stored", s.Name(), len(s.snapshot))
+                return nil
+        }
+        
+        if len(newMarkets) > maxNewMarketsPerPoll {
+                log.Printf("⚠️ [%s] %d new markets in one diff, treating as re-baseline instead of listings", s.Name(), len(newMarkets))
+                return nil
+        }
+        
+        var listings []CoinListing
+        for _, market := range newMarkets {
+                if market.QuoteCurrency != MarketKRW && market.QuoteCurrency != MarketBTC && market.QuoteCurrency != MarketUSDT {
+                        continue
+                }
+                
+                log.Printf("🆕 [%s] New Upbit market detected: %s (%s)", s.Name(), market.Market, market.EnglishName)
+                listings = append(listings, CoinListing{
+                        Symbol:            market.BaseCurrency,
+                        Kind:              ListingKindNew,
+                        Source:            s.Name(),
+                        AnnouncementID:    "market:" + market.Market,
+                        AnnouncementTitle: fmt.Sprintf("New Upbit market %s (%s / %s)", market.Market, market.EnglishName, market.KoreanName),
+                        MarketType:        market.QuoteCurrency,
+                        Markets:           []string{market.QuoteCurrency},
+                        DetectedAt:        now,
+                        FromMarketList:    true,
+                        AddedMarket:       listedBases[market.BaseCurrency],
+                })
+        }
+        
+        return listings
+}
+
+// loadSnapshot seeds the in-memory snapshot from the upbit_markets table (once)
+func (s *UpbitMarketListSource) loadSnapshot() {
+        if s.snapshotReady || len(s.snapshot) > 0 {
+                return
+        }
+        
+        var codes []string
+        err := database.WithDB(func(db *gorm.DB) error {
+                return db.Model(&models.UpbitMarket{}).Pluck("market", &codes).Error
+        })
+        if err != nil {
+                log.Printf("⚠️ [%s] Stored market snapshot unavailable: %v", s.Name(), err)
+                return
+        }
+        
+        for _, code := range codes {
+                s.snapshot[code] = true
+        }
+        if len(codes) > 0 {
+                s.snapshotReady = true
+                log.Printf("💾 [%s] Loaded snapshot of %d markets", s.Name(), len(codes))
+        }
+}
+
+// saveSnapshot persists newly seen markets, retrying earlier entries that could not be stored
+func (s *UpbitMarketListSource) saveSnapshot(markets []models.UpbitMarket) {
+        pending := append(s.unsaved, markets...)
+        if len(pending) == 0 {
+                return
+        }
+        
+        err := database.WithDB(func(db *gorm.DB) error {
+                return db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(pending, 200).Error
+        })
+        if err != nil {
+                log.Printf("⚠️ [%s] Failed to store %d markets in snapshot, will retry: %v", s.Name(), len(pending), err)
+                s.unsaved = pending
+                return
+        }
+        s.unsaved = nil
+}
+
+// splitUpbitMarket splits "KRW-BTC" into quote "KRW" and base "BTC"
+func splitUpbitMarket(code string) (quote string, base string, ok bool) {
+        parts := strings.SplitN(code, "-", 2)
+        if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+                return "", "", false
+        }
+        return parts[0], parts[1], true
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+        "encoding/json"
+        "fmt"
+        "net/http"
+        "os"
+        "testing"
+        "time"
+)
+
+// loadMarketFixture reads a recorded /v1/market/all response
+func loadMarketFixture(t *testing.T, fixture string) []upbitMarket {
+        t.Helper()
+        
+        body, err := os.ReadFile("testdata/" + fixture)
+        if err != nil {
+                t.Fatalf("failed to read fixture %s: %v", fixture, err)
+        }
+        var markets []upbitMarket
+        if err := json.Unmarshal(body, &markets); err != nil {
+                t.Fatalf("failed to parse fixture %s: %v", fixture, err)
+        }
+        return markets
+}
+
+func TestUpbitMarketListFirstPollIsBaseline(t *testing.T) {
+        server := newNoticeFixtureServer(t, "upbit_markets.json", func(w http.ResponseWriter, r *http.Request, body []byte) {
+                w.Header().Set("Content-Type", "application/json")
+                w.Write(body)
+        })
+        
+        // No stored snapshot (the test database is down): every market already listed is the baseline
+        source := NewUpbitMarketListSource(time.Minute, &http.Client{Timeout: 5 * time.Second})
+        source.endpoint = server.URL
+        listings, err := source.Poll()
+        if err != nil {
+                t.Fatalf("Poll returned error: %v", err)
+        }
+        if len(listings) != 0 {
+                t.Fatalf("baseline emitted %d listings: %+v", len(listings), listings)
+        }
+        if len(source.snapshot) != 6 || !source.snapshot["USDT-XRP"] {
+                t.Errorf("baseline snapshot = %v", source.snapshot)
+        }
+        
+        if listings, _ := source.Poll(); len(listings) != 0 {
+                t.Errorf("unchanged market list emitted %d listings", len(listings))
+        }
+}
+
+func TestUpbitMarketListDiffDetectsNewMarkets(t *testing.T) {
+        source := NewUpbitMarketListSource(time.Minute, nil)
+        source.diff(loadMarketFixture(t, "upbit_markets.json"))
+        
+        listings := source.diff(loadMarketFixture(t, "upbit_markets_new.json"))
+        if len(listings) != 2 {
+                t.Fatalf("expected KRW-TOSHI and BTC-XRP, got %d: %+v", len(listings), listings)
+        }
+        
+        added, toshi := listings[0], listings[1]
+        if added.Symbol != "XRP" || added.MarketType != MarketBTC || !added.AddedMarket {
+                t.Errorf("BTC-XRP should be an added market for listed XRP: %+v", added)
+        }
+        if toshi.Symbol != "TOSHI" || toshi.MarketType != MarketKRW || toshi.AddedMarket {
+                t.Errorf("KRW-TOSHI should be a new listing: %+v", toshi)
+        }
+        if !toshi.FromMarketList || toshi.Kind != ListingKindNew || toshi.AnnouncementID != "market:KRW-TOSHI" {
+                t.Errorf("unexpected market list event: %+v", toshi)
+        }
+        
+        // ETH-TOSHI (no tradeable quote) is remembered but not emitted; the malformed code is ignored
+        if !source.snapshot["ETH-TOSHI"] || source.snapshot["TOSHI"] {
+                t.Errorf("snapshot after diff = %v", source.snapshot)
+        }
+        if listings := source.diff(loadMarketFixture(t, "upbit_markets_new.json")); len(listings) != 0 {
+                t.Errorf("markets emitted twice: %+v", listings)
+        }
+}
+
+func TestUpbitMarketListLargeDiffIsRebaseline(t *testing.T) {
+        source := NewUpbitMarketListSource(time.Minute, nil)
+        markets := loadMarketFixture(t, "upbit_markets.json")
+        source.diff(markets)
+        
+        for i := 0; i <= maxNewMarketsPerPoll; i++ {
+                markets = append(markets, upbitMarket{Market: fmt.Sprintf("KRW-COIN%d", i), EnglishName: "Coin"})
+        }
+        if listings := source.diff(markets); len(listings) != 0 {
+                t.Fatalf("%d new markets at once should re-baseline, got %d listings", maxNewMarketsPerPoll+1, len(listings))
+        }
+        if !source.snapshot["KRW-COIN0"] {
+                t.Error("re-baselined markets not kept in the snapshot")
+        }
+        
+        markets = append(markets, upbitMarket{Market: "KRW-NEW", EnglishName: "New"})
+        if listings := source.diff(markets); len(listings) != 1 || listings[0].Symbol != "NEW" {
+                t.Errorf("listing after the re-baseline = %+v", listings)
+        }
+}
@@ -5,15 +5,10 @@ import (
         "log"
         "math/rand"
         "net/http"
-        "net/url"
-        "os"
-        "regexp"
-        "strconv"
         "strings"
         "sync"
         "time"
-
-        "github.com/PuerkitoBio/goquery"
+        "upbit-bitget-trading-bot/models"
 )
 
 // Initialize random seed for jitter
@@ -21,82 +16,192 @@ func init() {
         rand.Seed(time.Now().UnixNano())
 }
 
-// UpbitMonitor monitors Upbit announcements for new coin listings
+// UpbitMonitor monitors listing sources and merges their detections into one deduplicated stream
 type UpbitMonitor struct {
         checkInterval   time.Duration
+        sources         []ListingSource // Polled concurrently, each on its own schedule
         processedCoins  map[string]bool
+        processedLoaded bool            // processedCoins has been seeded from listing_events
+        loadDeferLogged bool            // The missing database has been reported once
+        announcedCoins  map[string]bool // Coins seen in announcements this run (market list confirmation)
         coinMutex      sync.RWMutex
-        newCoinChannel chan string
+        newCoinChannel chan CoinListing
+        riskEventChannel chan CoinListing // Delisting and investment warning events
         testCoinChannel chan string  // For user-specific test coins
         stopChannel    chan bool
-        // Rate limiting fields
-        lastETag       string        // For conditional GET requests
-        lastModified   string        // For conditional GET requests
-        backoffUntil   time.Time     // Exponential backoff timestamp
-        failureCount   int           // Consecutive failure count for backoff
-        httpClient     *http.Client  // Reusable HTTP client with potential proxy
+        done           chan struct{} // Closed on stop to terminate source loops
+        proxyPool      *ProxyPool
+        schedule       *PollSchedule // KST-aware intervals and request budget (nil = fixed intervals)
+        noticeDetails  *noticeDetailFetcher // Notice bodies for trading start times
+        quorum         *DetectionQuorum     // Multi-source confirmation before trading (nil = first detection trades)
+        maxNoticeAge   time.Duration        // Notices announced longer ago are neither traded nor acted on (0 = no limit)
 }
 
 // CoinListing represents a detected coin listing
 type CoinListing struct {
         Symbol      string
+        Kind              ListingKind // listing, delisting or warning
+        Source            string    // Listing source that detected it (upbit_notice_api, upbit_notice_html, ...)
+        AnnouncementID    string    // Exchange notice ID when the source provides one
+        AnnouncementURL   string    // Link to the notice
         AnnouncementTitle string
+        Category          string    // Notice category as published by the exchange
+        MarketType        string    // Most significant market of the listing: KRW, USDT or BTC
+        AnnouncedAt       time.Time // Publication time of the notice (zero if unknown)
         DetectedAt  time.Time
+        Latency           time.Duration // DetectedAt - AnnouncedAt (zero if announcement time unknown)
         Markets     []string // KRW, USDT markets
+        FromMarketList    bool // Detected by the market list diff rather than an announcement
+        AnnouncementSeen  bool // For market list detections: an announcement for the coin was also seen
+        AddedMarket       bool // Coin was already tradeable on Upbit in another market (not a brand new listing)
+        TradingStartsAt   time.Time // When trading opens on Upbit according to the notice body (zero if unknown)
+        QueuedAt          time.Time // Entered from the watchlist: when the coin was queued (zero otherwise)
+        ReleasedAt        time.Time // End of a deliberate wait before the entry (entry time, watchlist), zero without one
+        Exchange          string    // Exchange that announced the listing (models.ExchangeUpbit, models.ExchangeBithumb)
+        Confidence        float64   // Confidence (0-1) reported by an external signal source (zero for Upbit sources)
+        Confirmations     []string  // Sources that confirmed the listing before it was traded
 }
 
-// NewUpbitMonitor creates a new Upbit monitor instance
-func NewUpbitMonitor(checkInterval time.Duration) *UpbitMonitor {
-        // Create HTTP client with optional proxy support
-        client := &http.Client{
-                Timeout: 30 * time.Second,
+// CategoryLabel describes a listing for users, e.g. "KRW yeni listeleme" or "BTC market ekleme"
+func (l CoinListing) CategoryLabel() string {
+        label := "yeni listeleme"
+        if l.AddedMarket {
+                label = "market ekleme"
         }
-        
-        // Check for proxy configuration
-        if proxyURL := os.Getenv("UPBIT_PROXY_URL"); proxyURL != "" {
-                if proxy, err := url.Parse(proxyURL); err == nil {
-                        client.Transport = &http.Transport{
-                                Proxy: http.ProxyURL(proxy),
+        if l.MarketType == "" {
+                return label
+        }
+        return l.MarketType + " " + label
+}
+
+// Market types in order of price impact
+const (
+        MarketKRW  = "KRW"
+        MarketUSDT = "USDT"
+        MarketBTC  = "BTC"
+)
+
+// primaryMarket picks the most significant market (KRW > USDT > BTC)
+func primaryMarket(markets []string) string {
+        for _, candidate := range []string{MarketKRW, MarketUSDT, MarketBTC} {
+                for _, market := range markets {
+                        if market == candidate {
+                                return candidate
                         }
-                        log.Printf("🌐 Using proxy for Upbit requests: %s", proxyURL)
-                } else {
-                        log.Printf("⚠️ Invalid proxy URL: %s", proxyURL)
                 }
         }
+        return ""
+}
+
+// NewUpbitMonitor creates a new Upbit monitor instance
+// All Upbit requests rotate over the proxy pool (nil uses a direct-only pool)
+func NewUpbitMonitor(checkInterval time.Duration, proxyPool *ProxyPool) *UpbitMonitor {
+        if proxyPool == nil {
+                proxyPool = NewProxyPool(nil)
+        }
         
-        return &UpbitMonitor{
+        um := &UpbitMonitor{
                 checkInterval:   checkInterval,
                 processedCoins:  make(map[string]bool),
+                announcedCoins:  make(map[string]bool),
                 coinMutex:      sync.RWMutex{},
-                newCoinChannel: make(chan string, 100),
+                newCoinChannel: make(chan CoinListing, 100),
+                riskEventChannel: make(chan CoinListing, 100),
                 testCoinChannel: make(chan string, 10),  // Smaller buffer for tests
                 stopChannel:    make(chan bool),
-                httpClient:     client,
+                done:           make(chan struct{}),
+                proxyPool:      proxyPool,
         }
+        um.noticeDetails = newNoticeDetailFetcher(um.sourceClient("upbit_notice_detail", proxyPool))
+        
+        // Notice JSON API and page scraper are always registered as the default sources
+        um.AddSource(NewUpbitNoticeAPISource(checkInterval, um.sourceClient("upbit_notice_api", proxyPool)))
+        um.AddSource(NewUpbitNoticeScraper(checkInterval, um.sourceClient("upbit_notice_html", proxyPool)))
+        
+        return um
+}
+
+// AddSource registers an additional listing source (must be called before Start)
+func (um *UpbitMonitor) AddSource(source ListingSource) {
+        um.sources = append(um.sources, source)
 }
 
-// Start begins monitoring Upbit announcements with improved rate limiting
+// IngestSignal emits a listing pushed by an external signal source through the same dedupe and trading path as polled sources
+func (um *UpbitMonitor) IngestSignal(listing CoinListing) bool {
+        return um.emit(listing.Source, listing)
+}
+
+// AddBithumbNoticeSource registers the Bithumb notice source with its own direct HTTP client
+func (um *UpbitMonitor) AddBithumbNoticeSource(interval time.Duration) {
+        um.AddSource(NewBithumbNoticeSource(interval, um.sourceClient("bithumb_notice", http.DefaultTransport)))
+}
+
+// SetQuorum holds new listings until enough sources confirm them (must be called before Start)
+func (um *UpbitMonitor) SetQuorum(quorum *DetectionQuorum) {
+        um.quorum = quorum
+}
+
+// SetMaxNoticeAge drops listings and risk events whose notice was announced longer ago than maxAge (must be called before Start)
+func (um *UpbitMonitor) SetMaxNoticeAge(maxAge time.Duration) {
+        um.maxNoticeAge = maxAge
+}
+
+// staleNotice reports whether a detection's notice is older than the configured age limit
+func (um *UpbitMonitor) staleNotice(listing CoinListing) bool {
+        return um.maxNoticeAge > 0 && !listing.AnnouncedAt.IsZero() && listing.DetectedAt.Sub(listing.AnnouncedAt) > um.maxNoticeAge
+}
+
+// AddMarketListSource registers the Upbit market list diff detector on the monitor's proxy pool
+func (um *UpbitMonitor) AddMarketListSource(interval time.Duration) {
+        um.AddSource(NewUpbitMarketListSource(interval, um.sourceClient("upbit_market_list", um.proxyPool)))
+}
+
+// sourceClient returns an HTTP client whose requests count against a source's hourly budget
+func (um *UpbitMonitor) sourceClient(source string, transport http.RoundTripper) *http.Client {
+        return &http.Client{
+                Timeout:   30 * time.Second,
+                Transport: &budgetTransport{monitor: um, source: source, next: transport},
+        }
+}
+
+// budgetTransport records every request of a source on the poll schedule, proxy retries included, so the
+// hourly budget counts HTTP requests rather than polls
+type budgetTransport struct {
+        monitor *UpbitMonitor
+        source  string
+        next    http.RoundTripper
+}
+
+func (t *budgetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+        record := func() {
+                if schedule := t.monitor.schedule; schedule != nil {
+                        schedule.Record(t.source, time.Now())
+                }
+        }
+        if pool, ok := t.next.(*ProxyPool); ok {
+                return pool.roundTrip(req, record)
+        }
+        record()
+        return t.next.RoundTrip(req)
+}
+
+// Start runs every registered listing source concurrently (blocking function)
 func (um *UpbitMonitor) Start() {
-        log.Printf("🚀 Starting Upbit monitor - checking every %v with jitter", um.checkInterval)
+        log.Printf("🚀 Starting Upbit monitor with %d listing source(s)", len(um.sources))
         
-        // Initial check
-        um.checkAnnouncements()
+        // Load coins emitted before the last restart
+        um.ensureProcessedCoinsLoaded()
         
-        for {
-                // Calculate next check time with jitter (±10% randomness)
-                jitter := time.Duration(float64(um.checkInterval) * (0.9 + rand.Float64()*0.2))
-                timer := time.NewTimer(jitter)
-                
-                select {
-                case <-timer.C:
-                        um.checkAnnouncements()
-                case <-um.stopChannel:
-                        timer.Stop()
-                        log.Println("🛑 Upbit monitor stopped")
-                        return
-                }
-                timer.Stop()
+        for _, source := range um.sources {
+                go um.runSource(source)
+        }
+        if um.quorum != nil {
+                go um.expireCandidates()
         }
+        
+        <-um.stopChannel
+        close(um.done)
+        log.Println("🛑 Upbit monitor stopped")
 }
 
 // Stop stops the monitoring service
@@ -105,10 +210,15 @@ func (um *UpbitMonitor) Stop() {
 }
 
 // GetNewCoinChannel returns the channel for new coin notifications
-func (um *UpbitMonitor) GetNewCoinChannel() <-chan string {
+func (um *UpbitMonitor) GetNewCoinChannel() <-chan CoinListing {
         return um.newCoinChannel
 }
 
+// GetRiskEventChannel returns the channel for delisting and investment warning events
+func (um *UpbitMonitor) GetRiskEventChannel() <-chan CoinListing {
+        return um.riskEventChannel
+}
+
 // GetTestCoinChannel returns the channel for test coin notifications  
 func (um *UpbitMonitor) GetTestCoinChannel() <-chan string {
         return um.testCoinChannel
@@ -130,9 +240,19 @@ func (um *UpbitMonitor) InjectTestCoin(coinSymbol string) {
         um.processedCoins[coinSymbol] = true
         um.coinMutex.Unlock()
         
+        listing := CoinListing{
+                Symbol:            coinSymbol,
+                Kind:              ListingKindNew,
+                Source:            "manual_test",
+                AnnouncementID:    "manual-test",
+                AnnouncementTitle: "Manual test injection",
+                DetectedAt:        time.Now(),
+        }
+        recordListingEvent(listing)
+        
         // Send to trading engine via channel
         select {
-        case um.newCoinChannel <- coinSymbol:
+        case um.newCoinChannel <- listing:
                 log.Printf("✅ Test coin %s sent to trading engine", coinSymbol)
         default:
                 log.Printf("⚠️ Channel full, could not inject test coin %s", coinSymbol)
@@ -154,316 +274,267 @@ func (um *UpbitMonitor) InjectTestCoinForUser(coinSymbol string, userID int64) {
         }
 }
 
-// checkAnnouncements scrapes Upbit announcements page with rate limiting and caching
-func (um *UpbitMonitor) checkAnnouncements() {
-        // Check if we're in backoff period
-        if time.Now().Before(um.backoffUntil) {
-                log.Printf("⏳ In backoff period until %v, skipping check", um.backoffUntil.Format("15:04:05"))
-                return
-        }
-        
-        log.Println("🔍 Checking Upbit announcements...")
-        
-        req, err := http.NewRequest("GET", "https://upbit.com/service_center/notice", nil)
-        if err != nil {
-                log.Printf("❌ Failed to create request: %v", err)
-                um.handleError()
-                return
-        }
-        
-        // Set proper headers to mimic browser
-        req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
-        req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8")
-        req.Header.Set("Accept-Language", "en-US,en;q=0.5")
-        
-        // Add conditional GET headers if we have cached data
-        if um.lastETag != "" {
-                req.Header.Set("If-None-Match", um.lastETag)
-        }
-        if um.lastModified != "" {
-                req.Header.Set("If-Modified-Since", um.lastModified)
-        }
+// SetSchedule enables the KST-aware polling schedule; must be called before Start
+func (um *UpbitMonitor) SetSchedule(schedule *PollSchedule) {
+        um.schedule = schedule
+}
+
+// runSource polls a single listing source on its own schedule until the monitor stops
+func (um *UpbitMonitor) runSource(source ListingSource) {
+        log.Printf("📡 Starting listing source %s - base interval %v with jitter", source.Name(), source.Interval())
         
-        resp, err := um.httpClient.Do(req)
-        if err != nil {
-                log.Printf("❌ Failed to fetch Upbit announcements: %v", err)
-                um.handleError()
-                return
-        }
-        defer resp.Body.Close()
+        // Initial check
+        um.scheduledPoll(source)
         
-        // Handle rate limiting and other HTTP errors
-        switch resp.StatusCode {
-        case 200:
-                // Success - reset failure count
-                um.failureCount = 0
-                
-                // Cache ETag and Last-Modified for next request
-                if etag := resp.Header.Get("ETag"); etag != "" {
-                        um.lastETag = etag
-                }
-                if lastModified := resp.Header.Get("Last-Modified"); lastModified != "" {
-                        um.lastModified = lastModified
+        for {
+                // Calculate next check time with jitter (±10% randomness)
+                interval := source.Interval()
+                if um.schedule != nil {
+                        interval = um.schedule.Interval(source.Name(), interval, time.Now())
                 }
+                jitter := time.Duration(float64(interval) * (0.9 + rand.Float64()*0.2))
+                timer := time.NewTimer(jitter)
                 
-                // Parse HTML
-                doc, err := goquery.NewDocumentFromReader(resp.Body)
-                if err != nil {
-                        log.Printf("❌ Failed to parse HTML: %v", err)
-                        um.handleError()
+                select {
+                case <-timer.C:
+                        um.scheduledPoll(source)
+                case <-um.done:
+                        timer.Stop()
+                        log.Printf("🛑 Listing source %s stopped", source.Name())
                         return
                 }
-                
-                // Extract announcements
-                um.parseAnnouncements(doc)
-                
-        case 304:
-                // Not Modified - page hasn't changed, this is good!
-                log.Println("📄 Page not modified since last check (304)")
-                um.failureCount = 0
-                
-        case 429:
-                // Too Many Requests - honor Retry-After if provided, otherwise exponential backoff
-                retryAfter := resp.Header.Get("Retry-After")
-                if retryAfter != "" {
-                        if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds > 0 {
-                                retryDuration := time.Duration(seconds) * time.Second
-                                um.backoffUntil = time.Now().Add(retryDuration)
-                                log.Printf("🚫 Rate limited by Upbit (429) - honoring Retry-After: %v", retryDuration)
-                        } else {
-                                log.Printf("🚫 Rate limited by Upbit (429) - invalid Retry-After, applying exponential backoff")
-                                um.applyBackoff()
+                timer.Stop()
+        }
+}
+
+// expireCandidates closes candidates that missed their quorum window until the monitor stops
+func (um *UpbitMonitor) expireCandidates() {
+        interval := um.quorum.Window() / 4
+        if interval < time.Second {
+                interval = time.Second
+        }
+        ticker := time.NewTicker(interval)
+        defer ticker.Stop()
+        
+        for {
+                select {
+                case <-ticker.C:
+                        for _, decision := range um.quorum.Expire(time.Now()) {
+                                um.recordDetectionDecision(decision)
                         }
-                } else {
-                        log.Printf("🚫 Rate limited by Upbit (429) - applying exponential backoff")
-                        um.applyBackoff()
+                case <-um.done:
+                        return
                 }
-                
-        case 403:
-                // Forbidden - might be IP blocked
-                log.Printf("🚫 Access forbidden by Upbit (403) - possible IP block, applying backoff")
-                um.applyBackoff()
-                
-        default:
-                log.Printf("❌ Upbit returned status code: %d", resp.StatusCode)
-                um.handleError()
         }
 }
 
-// handleError handles general errors with light backoff
-func (um *UpbitMonitor) handleError() {
-        um.failureCount++
-        if um.failureCount >= 3 {
-                // Apply light backoff after 3 consecutive failures
-                backoffDuration := time.Duration(um.failureCount) * 30 * time.Second
-                um.backoffUntil = time.Now().Add(backoffDuration)
-                log.Printf("⚠️ %d consecutive failures, backing off for %v", um.failureCount, backoffDuration)
+// scheduledPoll polls a source unless its hourly request budget is spent
+// Notice detail fetches are counted under upbit_notice_detail but never held back: they sit on the trading path
+func (um *UpbitMonitor) scheduledPoll(source ListingSource) {
+        if um.schedule != nil {
+                if wait := um.schedule.Reserve(source.Name(), time.Now()); wait > 0 {
+                        log.Printf("💸 [%s] Hourly request budget spent, skipping poll (budget frees up in %v)", source.Name(), wait.Round(time.Second))
+                        return
+                }
         }
+        um.pollSource(source)
 }
 
-// applyBackoff applies exponential backoff for rate limiting
-func (um *UpbitMonitor) applyBackoff() {
-        um.failureCount++
+// pollSource runs one poll of a source and forwards its detections into the merged stream
+func (um *UpbitMonitor) pollSource(source ListingSource) {
+        // A panicking source must not take down the other sources
+        defer func() {
+                if r := recover(); r != nil {
+                        log.Printf("🚨 PANIC RECOVERED in listing source %s: %v", source.Name(), r)
+                }
+        }()
         
-        // Exponential backoff: 1, 2, 4, 8, 10 minutes max
-        backoffMinutes := 1 << uint(um.failureCount-1)
-        if backoffMinutes > 10 {
-                backoffMinutes = 10
+        listings, err := source.Poll()
+        if err != nil {
+                log.Printf("❌ Listing source %s failed: %v", source.Name(), err)
+                return
         }
         
-        backoffDuration := time.Duration(backoffMinutes) * time.Minute
-        um.backoffUntil = time.Now().Add(backoffDuration)
-        
-        log.Printf("📉 Applying exponential backoff for %v (failure #%d)", backoffDuration, um.failureCount)
-}
-
-// parseAnnouncements extracts coin symbols from announcement titles
-func (um *UpbitMonitor) parseAnnouncements(doc *goquery.Document) {
         foundNewCoins := false
-        
-        // Look for announcement titles (adjust selector based on actual HTML structure)
-        doc.Find(".notice-list-item, .announcement-item, a[href*='notice']").Each(func(i int, s *goquery.Selection) {
-                title := strings.TrimSpace(s.Text())
-                
-                if title == "" {
-                        return
-                }
-                
-                // Detect market support announcements
-                if um.isMarketSupportAnnouncement(title) {
-                        coins := um.extractCoinSymbols(title)
-                        
-                        for _, coin := range coins {
-                                if um.isNewCoin(coin) {
-                                        log.Printf("🎯 NEW COIN DETECTED: %s from announcement: %s", coin, title)
-                                        um.markCoinAsProcessed(coin)
-                                        
-                                        // Send to channel for trading processing
-                                        select {
-                                        case um.newCoinChannel <- coin:
-                                                foundNewCoins = true
-                                        default:
-                                                log.Printf("⚠️ New coin channel full, dropping coin: %s", coin)
-                                        }
-                                }
-                        }
+        for _, listing := range listings {
+                if um.emit(source.Name(), listing) {
+                        foundNewCoins = true
                 }
-        })
+        }
         
         if !foundNewCoins {
-                log.Println("📊 No new coins detected in current check")
+                log.Printf("📊 No new coins detected in current check (%s)", source.Name())
         }
 }
 
-// isMarketSupportAnnouncement checks if title indicates market support announcement
-func (um *UpbitMonitor) isMarketSupportAnnouncement(title string) bool {
-        lowerTitle := strings.ToLower(title)
+// emit deduplicates a detection across all sources and sends it to the trading engine
+func (um *UpbitMonitor) emit(sourceName string, listing CoinListing) bool {
+        coin := listing.Symbol
         
-        // Korean patterns
-        koreanPatterns := []string{
-                "마켓 지원",
-                "거래 지원", 
-                "상장",
-                "신규 상장",
-                "원화 마켓",
-                "usdt 마켓",
+        // Fill in event metadata the source may not know
+        if listing.Kind == "" {
+                listing.Kind = ListingKindNew
         }
-        
-        // English patterns
-        englishPatterns := []string{
-                "market support",
-                "trading support",
-                "listing",
-                "new listing",
-                "krw market",
-                "usdt market",
-                "support for",
+        if listing.Source == "" {
+                listing.Source = sourceName
+        }
+        if listing.Exchange == "" {
+                listing.Exchange = models.ExchangeUpbit
+        }
+        if listing.DetectedAt.IsZero() {
+                listing.DetectedAt = time.Now()
+        }
+        if listing.MarketType == "" {
+                listing.MarketType = primaryMarket(listing.Markets)
+        }
+        if !listing.AnnouncedAt.IsZero() && listing.Latency == 0 {
+                listing.Latency = listing.DetectedAt.Sub(listing.AnnouncedAt)
         }
         
-        allPatterns := append(koreanPatterns, englishPatterns...)
+        // Make sure coins processed before a restart are known before deduplicating
+        um.ensureProcessedCoinsLoaded()
         
-        for _, pattern := range allPatterns {
-                if strings.Contains(lowerTitle, pattern) {
-                        return true
+        // Check and mark under one lock so two sources can't emit the same event
+        keys := processedKeys(listing.Exchange, listing.Kind, coin, listing.Markets)
+        um.coinMutex.Lock()
+        firstAnnouncement := false
+        upbit := listing.Exchange == models.ExchangeUpbit
+        if upbit && listing.Kind == ListingKindNew && !listing.FromMarketList && !um.announcedCoins[coin] {
+                um.announcedCoins[coin] = true
+                firstAnnouncement = true
+        }
+        if listing.FromMarketList {
+                listing.AnnouncementSeen = um.announcedCoins[coin]
+        }
+        if um.isProcessed(listing.Exchange, listing.Kind, coin, keys) {
+                um.coinMutex.Unlock()
+                if listing.Kind == ListingKindNew {
+                        um.recordConfirmation(listing, firstAnnouncement)
                 }
+                return false
         }
         
-        return false
-}
-
-// extractCoinSymbols extracts coin symbols from announcement title
-func (um *UpbitMonitor) extractCoinSymbols(title string) []string {
-        var coins []string
-        
-        // Pattern 1: "Market Support for Toshi(TOSHI) (KRW, USDT Market)"
-        re1 := regexp.MustCompile(`\(([A-Z]+)\)`)
-        matches1 := re1.FindAllStringSubmatch(title, -1)
-        for _, match := range matches1 {
-                if len(match) > 1 {
-                        symbol := strings.ToUpper(strings.TrimSpace(match[1]))
-                        if um.isValidCoinSymbol(symbol) {
-                                coins = append(coins, symbol)
-                        }
+        // An old notice (downtime, a notice list reaching back weeks) is no longer news: remember it without
+        // trading, and without closing positions over a delisting or warning that may be weeks old
+        if um.staleNotice(listing) {
+                for _, key := range keys {
+                        um.processedCoins[key] = true
                 }
+                um.coinMutex.Unlock()
+                log.Printf("⌛ Ignoring %s %s from %s announcement #%s: announced %v ago (limit %v)",
+                        listing.Kind, coin, sourceName, listing.AnnouncementID, listing.Latency.Round(time.Second), um.maxNoticeAge)
+                return false
         }
         
-        // Pattern 2: Direct symbol mentions like "TOSHI 거래 지원"
-        re2 := regexp.MustCompile(`\b([A-Z]{2,10})\b`)
-        matches2 := re2.FindAllStringSubmatch(title, -1)
-        for _, match := range matches2 {
-                if len(match) > 1 {
-                        symbol := strings.ToUpper(strings.TrimSpace(match[1]))
-                        if um.isValidCoinSymbol(symbol) && !um.isCommonWord(symbol) {
-                                coins = append(coins, symbol)
-                        }
+        // New listings wait for quorum; risk events only ever reduce exposure and go out at once
+        var decision *models.DetectionDecision
+        if listing.Kind == ListingKindNew && um.quorum != nil {
+                var confirmed *CoinListing
+                confirmed, decision = um.quorum.Observe(listing, time.Now())
+                if confirmed == nil {
+                        um.coinMutex.Unlock()
+                        return false
                 }
+                listing = *confirmed
+                keys = processedKeys(listing.Exchange, listing.Kind, coin, listing.Markets)
         }
+        for _, key := range keys {
+                um.processedCoins[key] = true
+        }
+        um.coinMutex.Unlock()
         
-        return um.removeDuplicates(coins)
-}
-
-// isValidCoinSymbol checks if a symbol looks like a valid cryptocurrency symbol
-func (um *UpbitMonitor) isValidCoinSymbol(symbol string) bool {
-        // Basic validation: 2-10 characters, all uppercase letters/numbers
-        if len(symbol) < 2 || len(symbol) > 10 {
-                return false
+        if decision != nil {
+                um.recordDetectionDecision(decision)
         }
         
-        // Must be mostly letters
-        letterCount := 0
-        for _, char := range symbol {
-                if (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9') {
-                        if char >= 'A' && char <= 'Z' {
-                                letterCount++
-                        }
-                } else {
+        // Persist before emitting so a restart never re-processes this event
+        if listing.Kind != ListingKindNew {
+                log.Printf("🚨 RISK EVENT DETECTED: %s %s from %s announcement #%s: %s",
+                        listing.Kind, coin, sourceName, listing.AnnouncementID, listing.AnnouncementTitle)
+                recordListingEvent(listing)
+                
+                select {
+                case um.riskEventChannel <- listing:
+                        return true
+                default:
+                        log.Printf("⚠️ Risk event channel full, dropping %s event for %s", listing.Kind, coin)
                         return false
                 }
         }
         
-        // At least 50% letters
-        return letterCount >= len(symbol)/2
-}
-
-// isCommonWord filters out common English words that aren't crypto symbols
-func (um *UpbitMonitor) isCommonWord(word string) bool {
-        commonWords := map[string]bool{
-                "FOR": true, "THE": true, "AND": true, "WITH": true, "MARKET": true,
-                "SUPPORT": true, "NEW": true, "TRADING": true, "KRW": true, "USDT": true,
-                "USD": true, "BTC": true, "ETH": true, "ANNOUNCEMENT": true,
+        // Only looked up for new events: sources re-return the whole notice list every poll
+        um.enrichFromNoticeDetail(&listing)
+        if upbit && !listing.FromMarketList && !listing.AddedMarket {
+                listing.AddedMarket = um.isAddedMarket(listing)
         }
         
-        return commonWords[word]
-}
-
-// isNewCoin checks if coin hasn't been processed before
-func (um *UpbitMonitor) isNewCoin(symbol string) bool {
-        um.coinMutex.RLock()
-        defer um.coinMutex.RUnlock()
+        log.Printf("🎯 NEW COIN DETECTED: %s (%s) from %s announcement #%s: %s (latency %v)",
+                coin, listing.CategoryLabel(), sourceName, listing.AnnouncementID, listing.AnnouncementTitle, listing.Latency)
         
-        return !um.processedCoins[symbol]
+        recordListingEvent(listing)
+        
+        // Send to channel for trading processing
+        select {
+        case um.newCoinChannel <- listing:
+                return true
+        default:
+                log.Printf("⚠️ New coin channel full, dropping coin: %s", coin)
+                return false
+        }
 }
 
-// markCoinAsProcessed marks a coin as already processed
-func (um *UpbitMonitor) markCoinAsProcessed(symbol string) {
-        um.coinMutex.Lock()
-        defer um.coinMutex.Unlock()
+// processedKeys returns the dedupe keys of an event
+// Listings are keyed per market ("SYMBOL@KRW") so a later KRW market for a coin first listed on BTC is
+// still traded; listings without market info use the bare symbol, risk events use "kind:SYMBOL".
+// Other exchanges have their own namespace ("bithumb|SYMBOL@KRW") so an Upbit listing doesn't hide theirs.
+func processedKeys(exchange string, kind ListingKind, symbol string, markets []string) []string {
+        symbol = exchangeSymbol(exchange, symbol)
+        if kind != "" && kind != ListingKindNew {
+                return []string{string(kind) + ":" + symbol}
+        }
+        if len(markets) == 0 {
+                return []string{symbol}
+        }
         
-        um.processedCoins[symbol] = true
+        keys := make([]string, 0, len(markets))
+        for _, market := range markets {
+                keys = append(keys, symbol+"@"+market)
+        }
+        return keys
 }
 
-// removeDuplicates removes duplicate symbols from slice
-func (um *UpbitMonitor) removeDuplicates(symbols []string) []string {
-        seen := make(map[string]bool)
-        var result []string
-        
-        for _, symbol := range symbols {
-                if !seen[symbol] {
-                        seen[symbol] = true
-                        result = append(result, symbol)
-                }
+// exchangeSymbol prefixes a symbol with its exchange, except for Upbit
+func exchangeSymbol(exchange string, symbol string) string {
+        if exchange == "" || exchange == models.ExchangeUpbit {
+                return symbol
         }
-        
-        return result
+        return exchange + "|" + symbol
 }
 
-// GetProcessedCoins returns list of processed coins (for testing/debugging)
-func (um *UpbitMonitor) GetProcessedCoins() []string {
-        um.coinMutex.RLock()
-        defer um.coinMutex.RUnlock()
-        
-        var coins []string
-        for coin := range um.processedCoins {
-                coins = append(coins, coin)
+// isProcessed reports whether every key of an event was already emitted (caller holds coinMutex)
+// A listing emitted without market info covers all markets of the coin
+func (um *UpbitMonitor) isProcessed(exchange string, kind ListingKind, symbol string, keys []string) bool {
+        if kind == ListingKindNew && um.processedCoins[exchangeSymbol(exchange, symbol)] {
+                return true
         }
-        
-        return coins
+        for _, key := range keys {
+                if !um.processedCoins[key] {
+                        return false
+                }
+        }
+        return true
 }
 
-// ClearProcessedCoins clears the processed coins list (for testing)
-func (um *UpbitMonitor) ClearProcessedCoins() {
-        um.coinMutex.Lock()
-        defer um.coinMutex.Unlock()
-        
-        um.processedCoins = make(map[string]bool)
+// processedKeySymbol returns the coin symbol a dedupe key belongs to
+func processedKeySymbol(key string) string {
+        if i := strings.Index(key, ":"); i >= 0 {
+                key = key[i+1:]
+        }
+        if i := strings.Index(key, "|"); i >= 0 {
+                key = key[i+1:]
+        }
+        if i := strings.Index(key, "@"); i >= 0 {
+                key = key[:i]
+        }
+        return key
 }
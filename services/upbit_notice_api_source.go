@@ -0,0 +1,179 @@
+package services
+
+import (
+        "encoding/json"
+        "fmt"
+        "io"
+        "log"
+        "net/http"
+        "strconv"
+        "time"
+)
+
+// UpbitNoticeAPISource reads Upbit's notice list JSON endpoint (same data the web page renders client-side)
+type UpbitNoticeAPISource struct {
+        endpoint   string
+        interval   time.Duration
+        httpClient *http.Client
+        parser     *announcementParser
+        state      httpPollState
+        baseline   noticeBaseline // Notices already published on the first run are recorded, not traded
+}
+
+// upbitNotice is a single entry of the notice list
+type upbitNotice struct {
+        ID            int64  `json:"id"`
+        Title         string `json:"title"`
+        Category      string `json:"category"`
+        ListedAt      string `json:"listed_at"`
+        FirstListedAt string `json:"first_listed_at"`
+}
+
+// upbitNoticeListResponse is the envelope returned by the announcements endpoint
+type upbitNoticeListResponse struct {
+        Success bool `json:"success"`
+        Data    struct {
+                TotalPages   int           `json:"total_pages"`
+                TotalCount   int           `json:"total_count"`
+                Notices      []upbitNotice `json:"notices"`
+                FixedNotices []upbitNotice `json:"fixed_notices"`
+        } `json:"data"`
+        ErrorCode    interface{} `json:"error_code"`
+        ErrorMessage interface{} `json:"error_message"`
+}
+
+// NewUpbitNoticeAPISource creates the JSON notice list source
+func NewUpbitNoticeAPISource(interval time.Duration, client *http.Client) *UpbitNoticeAPISource {
+        return &UpbitNoticeAPISource{
+                endpoint:   "https://api-manager.upbit.com/api/v1/announcements?os=web&page=1&per_page=20&category=trade",
+                interval:   interval,
+                httpClient: client,
+                parser:     &announcementParser{},
+                state:      httpPollState{name: "upbit_notice_api"},
+                baseline:   noticeBaseline{source: "upbit_notice_api"},
+        }
+}
+
+// Name returns the source identifier
+func (s *UpbitNoticeAPISource) Name() string {
+        return "upbit_notice_api"
+}
+
+// Interval returns the base polling interval
+func (s *UpbitNoticeAPISource) Interval() time.Duration {
+        return s.interval
+}
+
+// Poll fetches the notice list with conditional GET and rate limit handling
+func (s *UpbitNoticeAPISource) Poll() ([]CoinListing, error) {
+        if s.state.inBackoff() {
+                return nil, nil
+        }
+        
+        req, err := http.NewRequest("GET", s.endpoint, nil)
+        if err != nil {
+                s.state.handleError()
+                return nil, fmt.Errorf("failed to create request: %w", err)
+        }
+        
+        req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
+        req.Header.Set("Accept", "application/json")
+        req.Header.Set("Accept-Language", "ko-KR,ko;q=0.9,en-US;q=0.8")
+        s.state.applyConditionalHeaders(req)
+        
+        resp, err := s.httpClient.Do(req)
+        if err != nil {
+                s.state.handleError()
+                return nil, fmt.Errorf("failed to fetch Upbit notice list: %w", err)
+        }
+        defer resp.Body.Close()
+        
+        if !s.state.handleStatus(resp) {
+                return nil, nil
+        }
+        
+        body, err := io.ReadAll(resp.Body)
+        if err != nil {
+                s.state.handleError()
+                return nil, fmt.Errorf("failed to read notice list: %w", err)
+        }
+        
+        notices, err := parseUpbitNoticeList(body)
+        if err != nil {
+                s.state.handleError()
+                return nil, err
+        }
+        
+        return s.baseline.filter(s.listingsFromNotices(notices)), nil
+}
+
+// parseUpbitNoticeList decodes the endpoint payload into notices (regular + pinned)
+func parseUpbitNoticeList(body []byte) ([]upbitNotice, error) {
+        var listResp upbitNoticeListResponse
+        if err := json.Unmarshal(body, &listResp); err != nil {
+                return nil, fmt.Errorf("failed to parse notice list: %w", err)
+        }
+        
+        if !listResp.Success {
+                return nil, fmt.Errorf("notice list error: %v - %v", listResp.ErrorCode, listResp.ErrorMessage)
+        }
+        
+        notices := append([]upbitNotice{}, listResp.Data.FixedNotices...)
+        return append(notices, listResp.Data.Notices...), nil
+}
+
+// listingsFromNotices turns listing, delisting and warning notices into coin events
+func (s *UpbitNoticeAPISource) listingsFromNotices(notices []upbitNotice) []CoinListing {
+        var listings []CoinListing
+        
+        for _, notice := range notices {
+                kind := s.parser.classifyAnnouncement(notice.Title)
+                if kind == "" {
+                        continue
+                }
+                
+                announcedAt := parseUpbitTime(notice.FirstListedAt)
+                if announcedAt.IsZero() {
+                        announcedAt = parseUpbitTime(notice.ListedAt)
+                }
+                
+                noticeID := strconv.FormatInt(notice.ID, 10)
+                markets := s.parser.extractMarkets(notice.Title)
+                
+                for _, coin := range s.parser.extractCoinSymbols(notice.Title) {
+                        listings = append(listings, CoinListing{
+                                Symbol:            coin,
+                                Kind:              kind,
+                                Source:            s.Name(),
+                                AnnouncementID:    noticeID,
+                                AnnouncementURL:   upbitNoticeURL(noticeID),
+                                AnnouncementTitle: notice.Title,
+                                Markets:           markets,
+                                MarketType:        primaryMarket(markets),
+                                Category:          notice.Category,
+                                AnnouncedAt:       announcedAt,
+                                DetectedAt:        time.Now(),
+                        })
+                }
+        }
+        
+        return listings
+}
+
+// upbitNoticeURL returns the public web link of a notice
+func upbitNoticeURL(noticeID string) string {
+        return "https://upbit.com/service_center/notice?id=" + noticeID
+}
+
+// parseUpbitTime parses Upbit's RFC3339 timestamps, returns zero time when missing or invalid
+func parseUpbitTime(value string) time.Time {
+        if value == "" {
+                return time.Time{}
+        }
+        t, err := time.Parse(time.RFC3339, value)
+        if err != nil {
+                log.Printf("⚠️ Invalid Upbit timestamp %q: %v", value, err)
+                return time.Time{}
+        }
+        return t
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+        "net/http"
+        "net/http/httptest"
+        "os"
+        "testing"
+        "time"
+)
+
+// newNoticeFixtureServer serves a recorded notice list and counts requests
+func newNoticeFixtureServer(t *testing.T, fixture string, handler func(w http.ResponseWriter, r *http.Request, body []byte)) *httptest.Server {
+        t.Helper()
+        
+        body, err := os.ReadFile("testdata/" + fixture)
+        if err != nil {
+                t.Fatalf("failed to read fixture %s: %v", fixture, err)
+        }
+        
+        server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+                handler(w, r, body)
+        }))
+        t.Cleanup(server.Close)
+        return server
+}
+
+func newTestNoticeSource(endpoint string) *UpbitNoticeAPISource {
+        source := NewUpbitNoticeAPISource(time.Minute, &http.Client{Timeout: 5 * time.Second})
+        source.endpoint = endpoint
+        source.baseline.ready = true // Poll as if earlier events of the source were stored
+        return source
+}
+
+func TestUpbitNoticeAPISourceParsesFixture(t *testing.T) {
+        server := newNoticeFixtureServer(t, "upbit_notices.json", func(w http.ResponseWriter, r *http.Request, body []byte) {
+                w.Header().Set("Content-Type", "application/json")
+                w.Write(body)
+        })
+        
+        listings, err := newTestNoticeSource(server.URL).Poll()
+        if err != nil {
+                t.Fatalf("Poll returned error: %v", err)
+        }
+        
+        if len(listings) != 2 {
+                t.Fatalf("expected 2 listings, got %d: %+v", len(listings), listings)
+        }
+        
+        toshi := listings[0]
+        if toshi.Symbol != "TOSHI" || toshi.AnnouncementID != "5012" || toshi.Category != "거래" {
+                t.Errorf("unexpected first listing: %+v", toshi)
+        }
+        wantAnnounced := time.Date(2025, 3, 13, 7, 30, 0, 0, time.UTC)
+        if !toshi.AnnouncedAt.Equal(wantAnnounced) {
+                t.Errorf("expected announced at %v (first_listed_at), got %v", wantAnnounced, toshi.AnnouncedAt)
+        }
+        
+        if toshi.MarketType != MarketKRW || len(toshi.Markets) != 2 || toshi.Source != "upbit_notice_api" {
+                t.Errorf("expected KRW+USDT markets from upbit_notice_api, got %+v", toshi)
+        }
+        if toshi.AnnouncementURL != "https://upbit.com/service_center/notice?id=5012" {
+                t.Errorf("unexpected announcement URL: %s", toshi.AnnouncementURL)
+        }
+        
+        open := listings[1]
+        if open.Symbol != "OPEN" || open.AnnouncementID != "5009" {
+                t.Errorf("unexpected second listing: %+v", open)
+        }
+        if len(open.Markets) != 3 {
+                t.Errorf("expected KRW, BTC and USDT markets for OPEN, got %v", open.Markets)
+        }
+}
+
+func TestUpbitNoticeAPISourceConditionalGet(t *testing.T) {
+        requests := 0
+        server := newNoticeFixtureServer(t, "upbit_notices.json", func(w http.ResponseWriter, r *http.Request, body []byte) {
+                requests++
+                if r.Header.Get("If-None-Match") == `"v1"` && r.Header.Get("If-Modified-Since") == "Thu, 13 Mar 2025 08:02:11 GMT" {
+                        w.WriteHeader(http.StatusNotModified)
+                        return
+                }
+                w.Header().Set("ETag", `"v1"`)
+                w.Header().Set("Last-Modified", "Thu, 13 Mar 2025 08:02:11 GMT")
+                w.Write(body)
+        })
+        
+        source := newTestNoticeSource(server.URL)
+        if listings, err := source.Poll(); err != nil || len(listings) != 2 {
+                t.Fatalf("first poll: expected 2 listings, got %d (err=%v)", len(listings), err)
+        }
+        
+        listings, err := source.Poll()
+        if err != nil {
+                t.Fatalf("second poll returned error: %v", err)
+        }
+        if len(listings) != 0 {
+                t.Errorf("expected no listings on 304, got %d", len(listings))
+        }
+        if requests != 2 {
+                t.Errorf("expected 2 requests, got %d", requests)
+        }
+}
+
+func TestUpbitNoticeAPISourceRateLimitBackoff(t *testing.T) {
+        requests := 0
+        server := newNoticeFixtureServer(t, "upbit_notices_error.json", func(w http.ResponseWriter, r *http.Request, body []byte) {
+                requests++
+                w.Header().Set("Retry-After", "120")
+                w.WriteHeader(http.StatusTooManyRequests)
+                w.Write(body)
+        })
+        
+        source := newTestNoticeSource(server.URL)
+        if _, err := source.Poll(); err != nil {
+                t.Fatalf("Poll returned error: %v", err)
+        }
+        
+        remaining := time.Until(source.state.backoffUntil)
+        if remaining < 110*time.Second || remaining > 120*time.Second {
+                t.Errorf("expected Retry-After backoff of ~120s, got %v", remaining)
+        }
+        
+        // Still in backoff: no request must reach the server
+        source.Poll()
+        if requests != 1 {
+                t.Errorf("expected 1 request during backoff, got %d", requests)
+        }
+}
+
+func TestUpbitNoticeAPISourceForbiddenBackoff(t *testing.T) {
+        server := newNoticeFixtureServer(t, "upbit_notices_error.json", func(w http.ResponseWriter, r *http.Request, body []byte) {
+                w.WriteHeader(http.StatusForbidden)
+        })
+        
+        source := newTestNoticeSource(server.URL)
+        source.Poll()
+        
+        if source.state.failureCount != 1 {
+                t.Errorf("expected failure count 1, got %d", source.state.failureCount)
+        }
+        if remaining := time.Until(source.state.backoffUntil); remaining < 50*time.Second {
+                t.Errorf("expected ~1 minute exponential backoff, got %v", remaining)
+        }
+}
+
+func TestUpbitNoticeAPISourceFirstPollIsBaseline(t *testing.T) {
+        server := newNoticeFixtureServer(t, "upbit_notices.json", func(w http.ResponseWriter, r *http.Request, body []byte) {
+                w.Header().Set("Content-Type", "application/json")
+                w.Write(body)
+        })
+        
+        // No stored events (the test database is down): notices already published are recorded, not emitted
+        source := NewUpbitNoticeAPISource(time.Minute, &http.Client{Timeout: 5 * time.Second})
+        source.endpoint = server.URL
+        for poll := 1; poll <= 2; poll++ {
+                if listings, err := source.Poll(); err != nil || len(listings) != 0 {
+                        t.Fatalf("poll %d: expected no listings from baseline notices, got %d (err=%v)", poll, len(listings), err)
+                }
+        }
+        
+        fresh := source.baseline.filter([]CoinListing{
+                {Symbol: "TOSHI", Kind: ListingKindNew, AnnouncementID: "5012"},
+                {Symbol: "PENGU", Kind: ListingKindNew, AnnouncementID: "5020"},
+        })
+        if len(fresh) != 1 || fresh[0].Symbol != "PENGU" {
+                t.Errorf("expected only the notice published after the baseline, got %+v", fresh)
+        }
+}
+
+func TestEmitIgnoresStaleNotice(t *testing.T) {
+        um := NewUpbitMonitor(time.Minute, nil)
+        um.SetMaxNoticeAge(time.Hour)
+        
+        listing := CoinListing{
+                Symbol:         "TOSHI",
+                Source:         "upbit_notice_api",
+                AnnouncementID: "5012",
+                Markets:        []string{MarketKRW},
+                AnnouncedAt:    time.Now().Add(-3 * time.Hour),
+        }
+        if um.emit(listing.Source, listing) {
+                t.Fatal("a listing announced 3h ago must not be emitted with a 1h limit")
+        }
+        if len(um.newCoinChannel) != 0 {
+                t.Errorf("expected no listing on the channel, got %d", len(um.newCoinChannel))
+        }
+        if !um.processedCoins["TOSHI@KRW"] {
+                t.Error("the stale listing should be remembered so later polls don't re-check it")
+        }
+}
+
+func TestEmitIgnoresStaleRiskNotice(t *testing.T) {
+        um := NewUpbitMonitor(time.Minute, nil)
+        um.SetMaxNoticeAge(time.Hour)
+        
+        delisting := CoinListing{
+                Symbol:         "BORA",
+                Kind:           ListingKindDelisting,
+                Source:         "upbit_notice_api",
+                AnnouncementID: "4890",
+                AnnouncedAt:    time.Now().Add(-14 * 24 * time.Hour),
+        }
+        if um.emit(delisting.Source, delisting) || len(um.riskEventChannel) != 0 {
+                t.Fatal("a two-week-old delisting notice must not reach the risk event channel")
+        }
+        
+        delisting.AnnouncedAt = time.Now().Add(-time.Minute)
+        delisting.AnnouncementID = "5030"
+        delisting.Symbol = "TOSHI"
+        if !um.emit(delisting.Source, delisting) || len(um.riskEventChannel) != 1 {
+                t.Error("a fresh delisting notice should be emitted as a risk event")
+        }
+}
+
+func TestParseUpbitNoticeListError(t *testing.T) {
+        body, err := os.ReadFile("testdata/upbit_notices_error.json")
+        if err != nil {
+                t.Fatalf("failed to read fixture: %v", err)
+        }
+        
+        if _, err := parseUpbitNoticeList(body); err == nil {
+                t.Error("expected error for unsuccessful response")
+        }
+}
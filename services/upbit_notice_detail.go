@@ -0,0 +1,310 @@
+package services
+
+import (
+        "context"
+        "encoding/json"
+        "fmt"
+        "html"
+        "io"
+        "log"
+        "net/http"
+        "regexp"
+        "strconv"
+        "strings"
+        "sync"
+        "time"
+        "upbit-bitget-trading-bot/database"
+        "upbit-bitget-trading-bot/models"
+
+        "gorm.io/gorm"
+)
+
+// noticeDetail is what a listing notice body says about the listing
+type noticeDetail struct {
+        TradingStartsAt time.Time // Zero when the notice gives no trading start time
+        Markets         []string  // Markets named in the notice body (may be more than the title lists)
+}
+
+// upbitNoticeDetailResponse is the envelope returned by the announcement detail endpoint
+type upbitNoticeDetailResponse struct {
+        Success bool `json:"success"`
+        Data    struct {
+                ID            int64  `json:"id"`
+                Title         string `json:"title"`
+                Body          string `json:"body"`
+                ListedAt      string `json:"listed_at"`
+                FirstListedAt string `json:"first_listed_at"`
+        } `json:"data"`
+        ErrorCode    interface{} `json:"error_code"`
+        ErrorMessage interface{} `json:"error_message"`
+}
+
+// noticeDetailFetcher reads listing notice bodies, caching them per notice ID
+// A notice naming several coins is only fetched once
+type noticeDetailFetcher struct {
+        endpoint   string // Detail URL with %s for the notice ID
+        httpClient *http.Client
+        timeout    time.Duration // Detection is held back while the body is fetched, keep it short
+        
+        mutex sync.Mutex
+        cache map[string]*noticeDetail
+}
+
+var (
+        // Lines announcing when trading opens, e.g. "거래 지원 개시 시점 : 2025-06-26 17:00 KST 예정" or "거래 오픈 시점: 17:00 KST"
+        tradingStartLinePattern = regexp.MustCompile(`거래\s*(지원\s*)?(개시|오픈|시작)|마켓\s*(지원|오픈)\s*(개시|시작)?\s*시점`)
+        // Lines naming the listed markets, e.g. "거래 지원 마켓: KRW, BTC, USDT 마켓"
+        marketLinePattern = regexp.MustCompile(`(지원|거래|오픈)\s*마켓`)
+        
+        fullDatePattern  = regexp.MustCompile(`(\d{4})\s*[-./년]\s*(\d{1,2})\s*[-./월]\s*(\d{1,2})`)
+        monthDayPattern  = regexp.MustCompile(`(\d{1,2})\s*월\s*(\d{1,2})\s*일`)
+        clockTimePattern = regexp.MustCompile(`(오전|오후)?\s*(\d{1,2})\s*(?::\s*(\d{2})|시(?:\s*(\d{1,2})\s*분)?)`)
+        htmlTagPattern   = regexp.MustCompile(`<[^>]*>`)
+)
+
+// newNoticeDetailFetcher creates a detail fetcher for Upbit's announcement API
+func newNoticeDetailFetcher(client *http.Client) *noticeDetailFetcher {
+        return &noticeDetailFetcher{
+                endpoint:   "https://api-manager.upbit.com/api/v1/announcements/%s",
+                httpClient: client,
+                timeout:    500 * time.Millisecond,
+                cache:      make(map[string]*noticeDetail),
+        }
+}
+
+// Fetch returns the detail of a notice; reference dates start times that only give a clock time
+func (f *noticeDetailFetcher) Fetch(noticeID string, reference time.Time) (*noticeDetail, error) {
+        f.mutex.Lock()
+        cached, ok := f.cache[noticeID]
+        f.mutex.Unlock()
+        if ok {
+                return cached, nil
+        }
+        
+        ctx, cancel := context.WithTimeout(context.Background(), f.timeout)
+        defer cancel()
+        
+        req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf(f.endpoint, noticeID), nil)
+        if err != nil {
+                return nil, fmt.Errorf("failed to create request: %w", err)
+        }
+        req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
+        req.Header.Set("Accept", "application/json")
+        req.Header.Set("Accept-Language", "ko-KR,ko;q=0.9,en-US;q=0.8")
+        
+        resp, err := f.httpClient.Do(req)
+        if err != nil {
+                return nil, fmt.Errorf("failed to fetch notice %s: %w", noticeID, err)
+        }
+        defer resp.Body.Close()
+        
+        if resp.StatusCode != http.StatusOK {
+                return nil, fmt.Errorf("notice %s detail returned HTTP %d", noticeID, resp.StatusCode)
+        }
+        
+        body, err := io.ReadAll(resp.Body)
+        if err != nil {
+                return nil, fmt.Errorf("failed to read notice %s: %w", noticeID, err)
+        }
+        
+        var detailResp upbitNoticeDetailResponse
+        if err := json.Unmarshal(body, &detailResp); err != nil {
+                return nil, fmt.Errorf("failed to parse notice %s: %w", noticeID, err)
+        }
+        if !detailResp.Success {
+                return nil, fmt.Errorf("notice %s detail error: %v - %v", noticeID, detailResp.ErrorCode, detailResp.ErrorMessage)
+        }
+        
+        // Date-less start times refer to the day the notice was published
+        if publishedAt := parseUpbitTime(detailResp.Data.FirstListedAt); !publishedAt.IsZero() {
+                reference = publishedAt
+        } else if publishedAt := parseUpbitTime(detailResp.Data.ListedAt); !publishedAt.IsZero() {
+                reference = publishedAt
+        }
+        
+        detail := parseNoticeBody(detailResp.Data.Body, reference)
+        
+        f.mutex.Lock()
+        f.cache[noticeID] = detail
+        f.mutex.Unlock()
+        
+        return detail, nil
+}
+
+// parseNoticeBody extracts the trading start time and listed markets from a notice body (HTML or plain text)
+func parseNoticeBody(body string, reference time.Time) *noticeDetail {
+        detail := &noticeDetail{}
+        parser := &announcementParser{}
+        
+        text := html.UnescapeString(htmlTagPattern.ReplaceAllString(body, "\n"))
+        for _, line := range strings.Split(text, "\n") {
+                line = strings.TrimSpace(line)
+                if line == "" {
+                        continue
+                }
+                
+                if detail.TradingStartsAt.IsZero() && tradingStartLinePattern.MatchString(line) && !strings.Contains(line, "입출금") {
+                        detail.TradingStartsAt = parseKSTDateTime(line, reference)
+                }
+                if len(detail.Markets) == 0 && marketLinePattern.MatchString(line) {
+                        detail.Markets = parser.extractMarkets(line)
+                }
+        }
+        
+        return detail
+}
+
+// parseKSTDateTime reads a Korean date/time such as "2025-06-26 17:00", "6월 26일 오후 5시" or "17:00 KST"
+// Missing dates are taken from the reference day in KST; returns zero when no clock time is found
+func parseKSTDateTime(text string, reference time.Time) time.Time {
+        location := koreaLocation()
+        local := reference.In(location)
+        year, month, day := local.Year(), int(local.Month()), local.Day()
+        datedText := text
+        hasDate := false
+        
+        if match := fullDatePattern.FindStringSubmatchIndex(text); match != nil {
+                year, _ = strconv.Atoi(text[match[2]:match[3]])
+                month, _ = strconv.Atoi(text[match[4]:match[5]])
+                day, _ = strconv.Atoi(text[match[6]:match[7]])
+                datedText = text[match[1]:]
+                hasDate = true
+        } else if match := monthDayPattern.FindStringSubmatchIndex(text); match != nil {
+                month, _ = strconv.Atoi(text[match[2]:match[3]])
+                day, _ = strconv.Atoi(text[match[4]:match[5]])
+                datedText = text[match[1]:]
+                hasDate = true
+        }
+        
+        clock := clockTimePattern.FindStringSubmatch(datedText)
+        if clock == nil {
+                return time.Time{}
+        }
+        
+        hour, _ := strconv.Atoi(clock[2])
+        minute := 0
+        if clock[3] != "" {
+                minute, _ = strconv.Atoi(clock[3])
+        } else if clock[4] != "" {
+                minute, _ = strconv.Atoi(clock[4])
+        }
+        if clock[1] == "오후" && hour < 12 {
+                hour += 12
+        } else if clock[1] == "오전" && hour == 12 {
+                hour = 0
+        }
+        if month < 1 || month > 12 || day < 1 || day > 31 || hour > 23 || minute > 59 {
+                return time.Time{}
+        }
+        
+        startsAt := time.Date(year, time.Month(month), day, hour, minute, 0, 0, location)
+        
+        // "17:00 KST" in a notice published at 18:00 means the next day; "6월 26일" in December means next year
+        if !hasDate && startsAt.Before(local) {
+                startsAt = startsAt.AddDate(0, 0, 1)
+        } else if hasDate && !fullDatePattern.MatchString(text) && startsAt.Before(local.AddDate(0, -1, 0)) {
+                startsAt = startsAt.AddDate(1, 0, 0)
+        }
+        return startsAt
+}
+
+// enrichFromNoticeDetail adds the trading start time and body markets of an announced listing
+// Markets found only in the body are marked processed so the next poll doesn't re-emit them
+func (um *UpbitMonitor) enrichFromNoticeDetail(listing *CoinListing) {
+        if listing.FromMarketList || !listing.TradingStartsAt.IsZero() || !strings.HasPrefix(listing.Source, "upbit_notice") {
+                return
+        }
+        if _, err := strconv.ParseInt(listing.AnnouncementID, 10, 64); err != nil {
+                return
+        }
+        // Only scheduled entries use the trading start; immediate users must not wait for the body
+        if !scheduledEntriesActive() {
+                return
+        }
+        
+        reference := listing.AnnouncedAt
+        if reference.IsZero() {
+                reference = listing.DetectedAt
+        }
+        
+        start := time.Now()
+        detail, err := um.noticeDetails.Fetch(listing.AnnouncementID, reference)
+        if err != nil {
+                log.Printf("⚠️ Notice detail for %s unavailable, entering without trading start time: %v", listing.Symbol, err)
+                return
+        }
+        
+        listing.TradingStartsAt = detail.TradingStartsAt
+        
+        var added []string
+        for _, market := range detail.Markets {
+                if !containsString(listing.Markets, market) {
+                        added = append(added, market)
+                }
+        }
+        if len(added) > 0 && len(listing.Markets) > 0 {
+                um.coinMutex.Lock()
+                for _, key := range processedKeys(listing.Exchange, listing.Kind, listing.Symbol, added) {
+                        um.processedCoins[key] = true
+                }
+                um.coinMutex.Unlock()
+                listing.Markets = append(append([]string{}, listing.Markets...), added...)
+                listing.MarketType = primaryMarket(listing.Markets)
+        } else if len(added) > 0 {
+                // The title named no market: the bare symbol key already covers every market
+                listing.Markets = added
+                listing.MarketType = primaryMarket(added)
+        }
+        
+        if detail.TradingStartsAt.IsZero() {
+                log.Printf("📄 Notice #%s detail: no trading start time found (markets %v, %v)", listing.AnnouncementID, listing.Markets, time.Since(start).Round(time.Millisecond))
+        } else {
+                log.Printf("📄 Notice #%s detail: trading starts %s (markets %v, %v)", listing.AnnouncementID,
+                        detail.TradingStartsAt.Format("2006-01-02 15:04 MST"), listing.Markets, time.Since(start).Round(time.Millisecond))
+        }
+}
+
+// scheduledEntriesActive reports whether an active user enters at or around trading start (false without a database,
+// when nothing is traded anyway)
+func scheduledEntriesActive() bool {
+        var count int64
+        err := database.WithDB(func(db *gorm.DB) error {
+                return db.Model(&models.User{}).
+                        Where("is_active = ? AND entry_mode IN ?", true, []string{models.EntryModeTradingStart, models.EntryModeOffset}).
+                        Count(&count).Error
+        })
+        if err != nil {
+                if err.Error() != "database not available" {
+                        log.Printf("⚠️ Failed to look up scheduled entry users: %v", err)
+                }
+                return false
+        }
+        return count > 0
+}
+
+// containsString reports whether a slice holds a value
+func containsString(values []string, value string) bool {
+        for _, candidate := range values {
+                if candidate == value {
+                        return true
+                }
+        }
+        return false
+}
+
+// koreaLocation returns the Asia/Seoul zone, or a fixed +9 zone when tzdata is unavailable (KST has no DST)
+func koreaLocation() *time.Location {
+        koreaLocationOnce.Do(func() {
+                location, err := time.LoadLocation("Asia/Seoul")
+                if err != nil {
+                        location = time.FixedZone("KST", 9*60*60)
+                }
+                koreaLocationValue = location
+        })
+        return koreaLocationValue
+}
+
+var (
+        koreaLocationOnce  sync.Once
+        koreaLocationValue *time.Location
+)
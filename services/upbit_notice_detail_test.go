@@ -0,0 +1,150 @@
+package services
+
+import (
+        "net/http"
+        "strings"
+        "sync/atomic"
+        "testing"
+        "time"
+        "upbit-bitget-trading-bot/models"
+)
+
+func TestParseNoticeBodyTradingStart(t *testing.T) {
+        kst := koreaLocation()
+        // Notice published 2025-06-26 14:00 KST
+        reference := time.Date(2025, 6, 26, 14, 0, 0, 0, kst)
+        
+        cases := []struct {
+                name    string
+                body    string
+                want    time.Time
+                markets []string
+        }{
+                {
+                        name:    "full date",
+                        body:    "- 거래 지원 마켓 : KRW, USDT 마켓\n- 거래 지원 개시 시점 : 2025-06-26 17:00 KST 예정",
+                        want:    time.Date(2025, 6, 26, 17, 0, 0, 0, kst),
+                        markets: []string{MarketKRW, MarketUSDT},
+                },
+                {
+                        name: "clock time only",
+                        body: "거래 오픈 시점: 17:00 KST",
+                        want: time.Date(2025, 6, 26, 17, 0, 0, 0, kst),
+                },
+                {
+                        name: "clock time before publication is the next day",
+                        body: "거래 오픈 시점: 10:30 KST",
+                        want: time.Date(2025, 6, 27, 10, 30, 0, 0, kst),
+                },
+                {
+                        name: "korean month day and afternoon hour",
+                        body: "거래지원 개시 시점: 6월 27일 오후 5시",
+                        want: time.Date(2025, 6, 27, 17, 0, 0, 0, kst),
+                },
+                {
+                        name: "korean year month day",
+                        body: "거래 지원 개시 : 2025년 7월 1일 16시 30분 (KST)",
+                        want: time.Date(2025, 7, 1, 16, 30, 0, 0, kst),
+                },
+                {
+                        name: "deposit schedule is not trading start",
+                        body: "입출금 개시 시점 : 2025-06-26 15:00 KST\n거래 지원 개시 시점은 추후 공지 예정",
+                },
+                {
+                        name: "no schedule",
+                        body: "<p>안녕하세요, 업비트입니다.</p>",
+                },
+        }
+        
+        for _, tc := range cases {
+                t.Run(tc.name, func(t *testing.T) {
+                        detail := parseNoticeBody(tc.body, reference)
+                        if !detail.TradingStartsAt.Equal(tc.want) {
+                                t.Errorf("trading start: got %v, want %v", detail.TradingStartsAt, tc.want)
+                        }
+                        if !sameStrings(detail.Markets, tc.markets) {
+                                t.Errorf("markets: got %v, want %v", detail.Markets, tc.markets)
+                        }
+                })
+        }
+}
+
+func TestNoticeDetailFetcherParsesFixtureOnce(t *testing.T) {
+        var hits int32
+        server := newNoticeFixtureServer(t, "upbit_notice_detail.json", func(w http.ResponseWriter, r *http.Request, body []byte) {
+                atomic.AddInt32(&hits, 1)
+                if !strings.HasSuffix(r.URL.Path, "/5120") {
+                        http.NotFound(w, r)
+                        return
+                }
+                w.Header().Set("Content-Type", "application/json")
+                w.Write(body)
+        })
+        
+        fetcher := newNoticeDetailFetcher(&http.Client{Timeout: 5 * time.Second})
+        fetcher.endpoint = server.URL + "/announcements/%s"
+        
+        for i := 0; i < 2; i++ {
+                detail, err := fetcher.Fetch("5120", time.Now())
+                if err != nil {
+                        t.Fatalf("Fetch returned error: %v", err)
+                }
+                want := time.Date(2025, 6, 26, 8, 0, 0, 0, time.UTC)
+                if !detail.TradingStartsAt.Equal(want) {
+                        t.Errorf("trading start: got %v, want %v", detail.TradingStartsAt, want)
+                }
+                if !sameStrings(detail.Markets, []string{MarketKRW, MarketBTC, MarketUSDT}) {
+                        t.Errorf("markets: got %v", detail.Markets)
+                }
+        }
+        
+        if hits != 1 {
+                t.Errorf("expected the detail to be fetched once and cached, got %d requests", hits)
+        }
+        
+        if _, err := fetcher.Fetch("404", time.Now()); err == nil {
+                t.Error("expected an error for a missing notice")
+        }
+}
+
+func TestEnrichSkipsBodyWithoutScheduledEntries(t *testing.T) {
+        var hits int32
+        server := newNoticeFixtureServer(t, "upbit_notice_detail.json", func(w http.ResponseWriter, r *http.Request, body []byte) {
+                atomic.AddInt32(&hits, 1)
+                w.Write(body)
+        })
+        
+        um := NewUpbitMonitor(time.Minute, nil)
+        um.noticeDetails.endpoint = server.URL + "/announcements/%s"
+        
+        // No user waits for trading start (the test database is down): the listing goes out without the body fetch
+        listing := CoinListing{Symbol: "NEW", Source: "upbit_notice_api", AnnouncementID: "5120", Markets: []string{MarketBTC}}
+        um.enrichFromNoticeDetail(&listing)
+        if hits != 0 || !listing.TradingStartsAt.IsZero() {
+                t.Errorf("notice body fetched for immediate entries only (%d requests, start %v)", hits, listing.TradingStartsAt)
+        }
+}
+
+func TestUserEntryTime(t *testing.T) {
+        start := time.Date(2025, 6, 26, 8, 0, 0, 0, time.UTC)
+        
+        cases := []struct {
+                mode   string
+                offset int
+                start  time.Time
+                want   time.Time
+        }{
+                {"immediate", 30, start, time.Time{}},
+                {"trading_start", 30, start, start},
+                {"offset", -10, start, start.Add(-10 * time.Second)},
+                {"offset", 30, time.Time{}, time.Time{}},
+                {"", 0, start, time.Time{}},
+        }
+        
+        for _, tc := range cases {
+                user := models.User{EntryMode: tc.mode, EntryOffsetSeconds: tc.offset}
+                if got := user.EntryTime(tc.start); !got.Equal(tc.want) {
+                        t.Errorf("mode %q offset %d start %v: got %v, want %v", tc.mode, tc.offset, tc.start, got, tc.want)
+                }
+        }
+}
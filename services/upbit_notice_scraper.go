@@ -0,0 +1,146 @@
+package services
+
+import (
+        "fmt"
+        "log"
+        "net/http"
+        "net/url"
+        "strings"
+        "time"
+
+        "github.com/PuerkitoBio/goquery"
+)
+
+// UpbitNoticeScraper scrapes the Upbit service center notice page (HTML)
+type UpbitNoticeScraper struct {
+        pageURL    string
+        interval   time.Duration
+        httpClient *http.Client
+        parser     *announcementParser
+        state      httpPollState
+        baseline   noticeBaseline // Notices already on the page on the first run are recorded, not traded
+}
+
+// NewUpbitNoticeScraper creates the HTML notice page source
+func NewUpbitNoticeScraper(interval time.Duration, client *http.Client) *UpbitNoticeScraper {
+        return &UpbitNoticeScraper{
+                pageURL:    "https://upbit.com/service_center/notice",
+                interval:   interval,
+                httpClient: client,
+                parser:     &announcementParser{},
+                state:      httpPollState{name: "upbit_notice_html"},
+                baseline:   noticeBaseline{source: "upbit_notice_html"},
+        }
+}
+
+// Name returns the source identifier
+func (s *UpbitNoticeScraper) Name() string {
+        return "upbit_notice_html"
+}
+
+// Interval returns the base polling interval
+func (s *UpbitNoticeScraper) Interval() time.Duration {
+        return s.interval
+}
+
+// Poll scrapes Upbit announcements page with rate limiting and caching
+func (s *UpbitNoticeScraper) Poll() ([]CoinListing, error) {
+        // Check if we're in backoff period
+        if s.state.inBackoff() {
+                return nil, nil
+        }
+        
+        log.Println("🔍 Checking Upbit announcements...")
+        
+        req, err := http.NewRequest("GET", s.pageURL, nil)
+        if err != nil {
+                s.state.handleError()
+                return nil, fmt.Errorf("failed to create request: %w", err)
+        }
+        
+        // Set proper headers to mimic browser
+        req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
+        req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8")
+        req.Header.Set("Accept-Language", "en-US,en;q=0.5")
+        
+        // Add conditional GET headers if we have cached data
+        s.state.applyConditionalHeaders(req)
+        
+        resp, err := s.httpClient.Do(req)
+        if err != nil {
+                s.state.handleError()
+                return nil, fmt.Errorf("failed to fetch Upbit announcements: %w", err)
+        }
+        defer resp.Body.Close()
+        
+        if !s.state.handleStatus(resp) {
+                return nil, nil
+        }
+        
+        // Parse HTML
+        doc, err := goquery.NewDocumentFromReader(resp.Body)
+        if err != nil {
+                s.state.handleError()
+                return nil, fmt.Errorf("failed to parse HTML: %w", err)
+        }
+        
+        // Extract announcements
+        return s.baseline.filter(s.parseAnnouncements(doc)), nil
+}
+
+// parseAnnouncements extracts coin listings from announcement titles
+func (s *UpbitNoticeScraper) parseAnnouncements(doc *goquery.Document) []CoinListing {
+        var listings []CoinListing
+        
+        // Look for announcement titles (adjust selector based on actual HTML structure)
+        doc.Find(".notice-list-item, .announcement-item, a[href*='notice']").Each(func(i int, sel *goquery.Selection) {
+                title := strings.TrimSpace(sel.Text())
+                
+                if title == "" {
+                        return
+                }
+                
+                // Detect market support, delisting and warning announcements
+                kind := s.parser.classifyAnnouncement(title)
+                if kind == "" {
+                        return
+                }
+                
+                href, _ := sel.Attr("href")
+                announcementID := noticeIDFromHref(href)
+                markets := s.parser.extractMarkets(title)
+                
+                announcementURL := ""
+                if announcementID != "" {
+                        announcementURL = upbitNoticeURL(announcementID)
+                }
+                
+                for _, coin := range s.parser.extractCoinSymbols(title) {
+                        listings = append(listings, CoinListing{
+                                Symbol:            coin,
+                                Kind:              kind,
+                                Source:            s.Name(),
+                                AnnouncementID:    announcementID,
+                                AnnouncementURL:   announcementURL,
+                                AnnouncementTitle: title,
+                                Markets:           markets,
+                                MarketType:        primaryMarket(markets),
+                                DetectedAt:        time.Now(),
+                        })
+                }
+        })
+        
+        return listings
+}
+
+// noticeIDFromHref extracts the notice ID from links like /service_center/notice?id=4800
+func noticeIDFromHref(href string) string {
+        if href == "" {
+                return ""
+        }
+        parsed, err := url.Parse(href)
+        if err != nil {
+                return ""
+        }
+        return parsed.Query().Get("id")
+}
@@ -0,0 +1,359 @@
+package services
+
+import (
+        "fmt"
+        "log"
+        "sort"
+        "strconv"
+        "strings"
+        "sync"
+        "time"
+        "upbit-bitget-trading-bot/database"
+        "upbit-bitget-trading-bot/models"
+
+        "gorm.io/gorm"
+        "gorm.io/gorm/clause"
+)
+
+// WatchlistConfig configures the wait-and-enter watchlist for coins without a Bitget futures contract yet
+type WatchlistConfig struct {
+        Deadline     time.Duration // How long after detection to keep waiting for the contract
+        PollInterval time.Duration // How often Bitget's contract list is checked
+        MaxPriceMove float64       // Max % rise from the contract's opening price at which entry is still allowed (0 = no limit)
+}
+
+// defaultWatchlistConfig is used until SetWatchlistConfig is called
+func defaultWatchlistConfig() WatchlistConfig {
+        return WatchlistConfig{
+                Deadline:     24 * time.Hour,
+                PollInterval: 30 * time.Second,
+                MaxPriceMove: 30,
+        }
+}
+
+// SetWatchlistConfig replaces the watchlist settings; must be called before Start
+func (te *TradingEngine) SetWatchlistConfig(config WatchlistConfig) {
+        defaults := defaultWatchlistConfig()
+        if config.Deadline <= 0 {
+                config.Deadline = defaults.Deadline
+        }
+        if config.PollInterval <= 0 {
+                config.PollInterval = defaults.PollInterval
+        }
+        if config.MaxPriceMove < 0 {
+                config.MaxPriceMove = 0
+        }
+        te.watchlist = config
+}
+
+// queueForWatchlist stores a user's entry for a coin Bitget doesn't list yet and tells the user
+func (te *TradingEngine) queueForWatchlist(user models.User, listing CoinListing, bitgetSymbol string) {
+        detectedAt := listing.DetectedAt
+        if detectedAt.IsZero() {
+                detectedAt = time.Now()
+        }
+        
+        entry := &models.WatchlistEntry{
+                UserID:            user.ID,
+                CoinSymbol:        listing.Symbol,
+                AnnouncementID:    announcementKey(listing),
+                BitgetSymbol:      bitgetSymbol,
+                Status:            models.WatchlistWaiting,
+                AnnouncementTitle: listing.AnnouncementTitle,
+                AnnouncementURL:   listing.AnnouncementURL,
+                Source:            listing.Source,
+                Exchange:          listing.Exchange,
+                MarketType:        listing.MarketType,
+                Markets:           strings.Join(listing.Markets, ","),
+                AddedMarket:       listing.AddedMarket,
+                DetectedAt:        detectedAt,
+                ExpiresAt:         detectedAt.Add(te.watchlist.Deadline),
+        }
+        if !listing.AnnouncedAt.IsZero() {
+                announcedAt := listing.AnnouncedAt
+                entry.AnnouncedAt = &announcedAt
+        }
+        if !listing.TradingStartsAt.IsZero() {
+                tradingStartsAt := listing.TradingStartsAt
+                entry.TradingStartsAt = &tradingStartsAt
+        }
+        
+        var created int64
+        err := database.WithDB(func(db *gorm.DB) error {
+                result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(entry)
+                created = result.RowsAffected
+                return result.Error
+        })
+        if err != nil {
+                log.Printf("❌ Failed to queue %s on the watchlist for user %d: %v", listing.Symbol, user.TelegramID, err)
+                te.telegramBot.sendMessage(user.TelegramID,
+                        fmt.Sprintf("⚠️ %s Bitget'te henüz yok ve izleme listesine alınamadı, pozisyon açılmadı.", listing.Symbol))
+                return
+        }
+        if created == 0 {
+                log.Printf("ℹ️ %s already on the watchlist for user %d", listing.Symbol, user.TelegramID)
+                return
+        }
+        
+        log.Printf("👀 %s queued on the watchlist for user %d (waiting for %s until %s)",
+                listing.Symbol, user.TelegramID, bitgetSymbol, entry.ExpiresAt.Format(time.RFC3339))
+        te.telegramBot.SendWatchlistQueuedNotification(user.TelegramID, listing, bitgetSymbol, entry.ExpiresAt)
+}
+
+// monitorWatchlist polls Bitget's contract list for queued coins until the engine stops
+func (te *TradingEngine) monitorWatchlist() {
+        log.Printf("👀 Starting watchlist monitoring (every %v, deadline %v, max price move %.0f%%)",
+                te.watchlist.PollInterval, te.watchlist.Deadline, te.watchlist.MaxPriceMove)
+        
+        ticker := time.NewTicker(te.watchlist.PollInterval)
+        defer ticker.Stop()
+        
+        for {
+                select {
+                case <-ticker.C:
+                        te.checkWatchlist()
+                case <-te.done:
+                        return
+                }
+        }
+}
+
+// checkWatchlist expires overdue entries and enters coins whose contract has appeared
+func (te *TradingEngine) checkWatchlist() {
+        if !database.IsConnected() {
+                return
+        }
+        
+        var entries []models.WatchlistEntry
+        err := database.WithDB(func(db *gorm.DB) error {
+                return db.Preload("User").Where("status = ?", models.WatchlistWaiting).Order("created_at").Find(&entries).Error
+        })
+        if err != nil {
+                log.Printf("❌ Failed to load watchlist: %v", err)
+                return
+        }
+        if len(entries) == 0 {
+                return
+        }
+        
+        now := time.Now()
+        byCoin := make(map[string][]models.WatchlistEntry)
+        for _, entry := range entries {
+                // Waiting for its entry time after the contract appeared
+                if _, scheduled := te.watchlistScheduled.Load(entry.ID); scheduled {
+                        continue
+                }
+                if now.After(entry.ExpiresAt) {
+                        te.resolveWatchlistEntry(&entry, models.WatchlistExpired, "Son tarihe kadar Bitget'te kontrat açılmadı")
+                        continue
+                }
+                byCoin[entry.CoinSymbol] = append(byCoin[entry.CoinSymbol], entry)
+        }
+        if len(byCoin) == 0 {
+                return
+        }
+        
+        live, err := te.symbolMapper.CheckContracts()
+        if err != nil {
+                log.Printf("⚠️ Watchlist check skipped: %v", err)
+                return
+        }
+        
+        coins := make([]string, 0, len(byCoin))
+        for coin := range byCoin {
+                coins = append(coins, coin)
+        }
+        sort.Strings(coins)
+        
+        for _, coin := range coins {
+                decision := te.symbolMapper.Resolve(coin)
+                if !decision.Tradeable {
+                        for _, entry := range byCoin[coin] {
+                                entry := entry
+                                te.resolveWatchlistEntry(&entry, models.WatchlistFailed, "Sembol eşleştirmesi devre dışı: "+decision.Reason)
+                        }
+                        continue
+                }
+                if !live[decision.BitgetSymbol] {
+                        continue
+                }
+                
+                log.Printf("🎉 Watchlist coin %s is now on Bitget as %s (%d queued entries)", coin, decision.BitgetSymbol, len(byCoin[coin]))
+                te.enterWatchlistCoin(decision, byCoin[coin])
+        }
+}
+
+// enterWatchlistCoin applies the price-move guard and runs the normal per-user entry for each queued user
+func (te *TradingEngine) enterWatchlistCoin(decision SymbolDecision, entries []models.WatchlistEntry) {
+        move, err := contractPriceMove(te.newExchange("", "", ""), decision.BitgetSymbol)
+        if err != nil {
+                log.Printf("⚠️ Price check for %s failed, retrying next watchlist poll: %v", decision.BitgetSymbol, err)
+                return
+        }
+        
+        if te.watchlist.MaxPriceMove > 0 && move > te.watchlist.MaxPriceMove {
+                note := fmt.Sprintf("Fiyat kontrat açılışından beri %%%.1f yükseldi (limit %%%.0f)", move, te.watchlist.MaxPriceMove)
+                log.Printf("🚫 Skipping watchlist entries for %s: price up %.1f%% from contract open (limit %.0f%%)", decision.UpbitSymbol, move, te.watchlist.MaxPriceMove)
+                for _, entry := range entries {
+                        entry := entry
+                        te.resolveWatchlistEntry(&entry, models.WatchlistSkipped, note)
+                }
+                return
+        }
+        
+        var wg sync.WaitGroup
+        for _, entry := range entries {
+                entry := entry
+                if !entry.User.IsActive {
+                        te.resolveWatchlistEntry(&entry, models.WatchlistFailed, "Hesap pasif")
+                        continue
+                }
+                // The user may have turned the exchange off or filtered the market out while the entry was queued
+                listing := watchlistListing(entry)
+                if !userTakesListing(entry.User, listing) {
+                        te.resolveWatchlistEntry(&entry, models.WatchlistSkipped, "Borsa veya market ayarlarınız bu listelemeyi hariç tutuyor")
+                        continue
+                }
+                
+                // Bitget can list the contract before Upbit trading starts: a user entering at trading start waits for it
+                // without holding up the watchlist poll, and later polls leave the entry alone meanwhile
+                if time.Until(entry.User.EntryTime(listing.TradingStartsAt)) > 0 {
+                        te.scheduleWatchlistEntry(entry, listing, move)
+                        continue
+                }
+                
+                wg.Add(1)
+                go func() {
+                        defer wg.Done()
+                        defer func() {
+                                if r := recover(); r != nil {
+                                        log.Printf("🚨 PANIC RECOVERED in watchlist entry %d: %v", entry.ID, r)
+                                }
+                        }()
+                        
+                        // The wait for the contract is recorded apart from the entry's decision latency
+                        listing.ReleasedAt = time.Now()
+                        te.enterWatchlistEntry(entry, entry.User, listing, move)
+                }()
+        }
+        wg.Wait()
+}
+
+// scheduleWatchlistEntry waits in the background for a queued user's entry time (per EntryMode), then enters
+// An entry whose wait ends without entering is skipped, unless the engine is stopping: it stays queued for the restart
+func (te *TradingEngine) scheduleWatchlistEntry(entry models.WatchlistEntry, listing CoinListing, move float64) {
+        if _, scheduled := te.watchlistScheduled.LoadOrStore(entry.ID, true); scheduled {
+                return
+        }
+        
+        go func() {
+                defer te.watchlistScheduled.Delete(entry.ID)
+                defer func() {
+                        if r := recover(); r != nil {
+                                log.Printf("🚨 PANIC RECOVERED in scheduled watchlist entry %d: %v", entry.ID, r)
+                        }
+                }()
+                
+                user, ok := te.waitForEntry(entry.User, &listing)
+                if !ok {
+                        select {
+                        case <-te.done:
+                        default:
+                                te.resolveWatchlistEntry(&entry, models.WatchlistSkipped, "Planlanan giriş zamanında giriş yapılmadı")
+                        }
+                        return
+                }
+                te.enterWatchlistEntry(entry, user, listing, move)
+        }()
+}
+
+// enterWatchlistEntry runs the normal entry for a queued user and resolves the entry with its outcome
+func (te *TradingEngine) enterWatchlistEntry(entry models.WatchlistEntry, user models.User, listing CoinListing, move float64) {
+        te.apiWorkerPool <- struct{}{}
+        defer func() { <-te.apiWorkerPool }()
+        
+        userMutex := te.getUserMutex(user.TelegramID)
+        userMutex.Lock()
+        defer userMutex.Unlock()
+        
+        outcome := te.processUserTrade(user, listing)
+        switch {
+        case outcome.Opened:
+                te.resolveWatchlistEntry(&entry, models.WatchlistFilled, fmt.Sprintf("Kontrat açılışından %%%.1f uzaklıkta girildi", move))
+        case outcome.Skipped:
+                te.resolveWatchlistEntry(&entry, models.WatchlistSkipped, outcome.Note)
+        default:
+                te.resolveWatchlistEntry(&entry, models.WatchlistFailed, "Kontrat açıldı ancak pozisyon açılamadı: "+outcome.Note)
+        }
+}
+
+// contractPriceMove returns how far (%) the last price is above the contract's opening price
+// For a contract younger than a day open24h is its first traded price
+func contractPriceMove(api Exchange, symbol string) (float64, error) {
+        ticker, err := api.GetTicker(symbol)
+        if err != nil {
+                return 0, err
+        }
+        
+        last, err := strconv.ParseFloat(ticker.LastPr, 64)
+        if err != nil || last <= 0 {
+                return 0, fmt.Errorf("invalid last price %q", ticker.LastPr)
+        }
+        open, err := strconv.ParseFloat(ticker.Open24h, 64)
+        if err != nil || open <= 0 {
+                return 0, fmt.Errorf("invalid open price %q", ticker.Open24h)
+        }
+        
+        return (last/open - 1) * 100, nil
+}
+
+// watchlistListing rebuilds the detection event from a queued entry
+func watchlistListing(entry models.WatchlistEntry) CoinListing {
+        listing := CoinListing{
+                Symbol:            entry.CoinSymbol,
+                Kind:              ListingKindNew,
+                Source:            entry.Source,
+                Exchange:          entry.Exchange,
+                AnnouncementID:    entry.AnnouncementID,
+                AnnouncementURL:   entry.AnnouncementURL,
+                AnnouncementTitle: entry.AnnouncementTitle,
+                MarketType:        entry.MarketType,
+                DetectedAt:        entry.DetectedAt,
+                AddedMarket:       entry.AddedMarket,
+                QueuedAt:          entry.CreatedAt,
+        }
+        if entry.Markets != "" {
+                listing.Markets = strings.Split(entry.Markets, ",")
+        }
+        if entry.AnnouncedAt != nil {
+                listing.AnnouncedAt = *entry.AnnouncedAt
+                listing.Latency = listing.DetectedAt.Sub(listing.AnnouncedAt)
+        }
+        if entry.TradingStartsAt != nil {
+                listing.TradingStartsAt = *entry.TradingStartsAt
+        }
+        return listing
+}
+
+// resolveWatchlistEntry closes a watchlist entry and tells the user unless the trade notification already did
+// Notes are shown to the user as the reason, so they are in Turkish
+func (te *TradingEngine) resolveWatchlistEntry(entry *models.WatchlistEntry, status string, note string) {
+        now := time.Now()
+        entry.Status = status
+        entry.Note = note
+        entry.ResolvedAt = &now
+        
+        err := database.WithDB(func(db *gorm.DB) error {
+                return db.Model(&models.WatchlistEntry{}).Where("id = ?", entry.ID).
+                        Updates(map[string]interface{}{"status": status, "note": note, "resolved_at": now}).Error
+        })
+        if err != nil {
+                log.Printf("❌ Failed to update watchlist entry %d: %v", entry.ID, err)
+        }
+        
+        log.Printf("👀 Watchlist entry %d (%s, user %d): %s - %s", entry.ID, entry.CoinSymbol, entry.User.TelegramID, status, note)
+        
+        if status != models.WatchlistFilled && entry.User.TelegramID != 0 {
+                te.telegramBot.SendWatchlistResolvedNotification(entry.User.TelegramID, entry)
+        }
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+        "math"
+        "net/http"
+        "net/http/httptest"
+        "testing"
+        "time"
+        "upbit-bitget-trading-bot/models"
+)
+
+func TestContractPriceMove(t *testing.T) {
+        server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+                if r.URL.Path != "/api/v2/mix/market/ticker" || r.URL.Query().Get("symbol") != "NEWUSDT" {
+                        t.Errorf("unexpected request %s", r.URL.String())
+                }
+                w.Write([]byte(`{"code":"00000","msg":"success","data":[{"symbol":"NEWUSDT","lastPr":"1.25","open24h":"1.0"}]}`))
+        }))
+        defer server.Close()
+        
+        api := NewBitgetAPI("", "", "")
+        api.BaseURL = server.URL
+        
+        move, err := contractPriceMove(api, "NEWUSDT")
+        if err != nil {
+                t.Fatalf("contractPriceMove: %v", err)
+        }
+        if math.Abs(move-25) > 1e-9 {
+                t.Errorf("move = %.4f, want 25", move)
+        }
+}
+
+func TestWatchlistListingRoundTrip(t *testing.T) {
+        detected := time.Date(2025, 6, 26, 8, 0, 5, 0, time.UTC)
+        announced := detected.Add(-5 * time.Second)
+        entry := models.WatchlistEntry{
+                CoinSymbol:     "NEW",
+                AnnouncementID: "5120",
+                Source:         "upbit_notice",
+                MarketType:     "KRW",
+                Markets:        "KRW,USDT",
+                DetectedAt:     detected,
+                AnnouncedAt:    &announced,
+                CreatedAt:      detected.Add(time.Second),
+        }
+        tradingStarts := detected.Add(time.Hour)
+        entry.TradingStartsAt = &tradingStarts
+        
+        listing := watchlistListing(entry)
+        if listing.Symbol != "NEW" || listing.Kind != ListingKindNew || listing.AnnouncementID != "5120" {
+                t.Errorf("unexpected listing %+v", listing)
+        }
+        if len(listing.Markets) != 2 || listing.Markets[1] != "USDT" {
+                t.Errorf("markets = %v", listing.Markets)
+        }
+        if listing.Latency != 5*time.Second {
+                t.Errorf("latency = %v, want 5s", listing.Latency)
+        }
+        if listing.QueuedAt.IsZero() {
+                t.Error("QueuedAt not set, entry would be queued again")
+        }
+        if !listing.TradingStartsAt.Equal(tradingStarts) {
+                t.Errorf("trading start = %v, want %v", listing.TradingStartsAt, tradingStarts)
+        }
+}
+
+func TestCheckContractsPicksUpNewContract(t *testing.T) {
+        contracts := testContracts()
+        mapper := NewSymbolMapper()
+        mapper.fetchContracts = func() ([]ContractInfo, error) { return contracts, nil }
+        if err := mapper.Sync(); err != nil {
+                t.Fatalf("Sync: %v", err)
+        }
+        
+        contracts = append(contracts, ContractInfo{Symbol: "1000NEWUSDT", BaseCoin: "1000NEW", QuoteCoin: "USDT", SymbolStatus: "normal"})
+        live, err := mapper.CheckContracts()
+        if err != nil {
+                t.Fatalf("CheckContracts: %v", err)
+        }
+        if !live["1000NEWUSDT"] || live["OLDUSDT"] {
+                t.Errorf("live contracts = %v", live)
+        }
+        
+        decision := mapper.Resolve("NEW")
+        if decision.BitgetSymbol != "1000NEWUSDT" || decision.Multiplier != 1000 {
+                t.Errorf("new contract not mapped: %+v", decision)
+        }
+}
+
+func TestEnterWatchlistCoinRecordsSkipReason(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setPrice("NEWUSDT", 2)
+        engine, telegram := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        engine.symbolMapper.overrides["NEW"] = models.SymbolMapping{UpbitSymbol: "NEW", Source: models.SymbolMappingAdmin, Disabled: true}
+        
+        entry := models.WatchlistEntry{CoinSymbol: "NEW", BitgetSymbol: "NEWUSDT", User: user}
+        engine.enterWatchlistCoin(SymbolDecision{UpbitSymbol: "NEW", BitgetSymbol: "NEWUSDT", Tradeable: true}, []models.WatchlistEntry{entry})
+        
+        if !telegram.sentContaining(user.TelegramID, "GİRİŞ YAPILMADI") || !telegram.sentContaining(user.TelegramID, "eşleştirmesi devre dışı") {
+                t.Errorf("mapping skip not recorded as skipped: %v", telegram.sent(user.TelegramID))
+        }
+        if telegram.sentContaining(user.TelegramID, "BAŞARISIZ") {
+                t.Error("mapping skip recorded as a failure")
+        }
+}
+
+func TestEnterWatchlistCoinRechecksUserFilters(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setPrice("NEWUSDT", 2)
+        engine, telegram := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        user.TradeBithumb = false // Bithumb turned off after the entry was queued
+        
+        entry := models.WatchlistEntry{CoinSymbol: "NEW", BitgetSymbol: "NEWUSDT", Exchange: models.ExchangeBithumb, User: user}
+        engine.enterWatchlistCoin(SymbolDecision{UpbitSymbol: "NEW", BitgetSymbol: "NEWUSDT", Tradeable: true}, []models.WatchlistEntry{entry})
+        
+        if !telegram.sentContaining(user.TelegramID, "GİRİŞ YAPILMADI") {
+                t.Errorf("filtered entry not skipped: %v", telegram.sent(user.TelegramID))
+        }
+        if fake.requestCount("/api/v2/mix/order/place-order") != 0 {
+                t.Error("order placed for a user who turned Bithumb off")
+        }
+}
+
+func TestEnterWatchlistCoinWaitsForEntryTime(t *testing.T) {
+        fake := newFakeBitget(t)
+        fake.setPrice("NEWUSDT", 2)
+        engine, telegram := newTestEngine(t, fake)
+        user := newTestUser(t, fake, fake.apiSecret)
+        user.EntryMode = models.EntryModeTradingStart
+        
+        // Bitget listed the contract before Upbit trading starts
+        tradingStarts := time.Now().Add(50 * time.Millisecond)
+        entry := models.WatchlistEntry{ID: 42, CoinSymbol: "NEW", BitgetSymbol: "NEWUSDT", TradingStartsAt: &tradingStarts, User: user}
+        engine.enterWatchlistCoin(SymbolDecision{UpbitSymbol: "NEW", BitgetSymbol: "NEWUSDT", Tradeable: true}, []models.WatchlistEntry{entry})
+        
+        if _, scheduled := engine.watchlistScheduled.Load(entry.ID); !scheduled {
+                t.Fatal("entry not left waiting for the trading start")
+        }
+        if fake.requestCount("/api/v2/mix/order/place-order") != 0 {
+                t.Fatal("entered before the trading start")
+        }
+        
+        // Without a database the settings at entry time are unknown: the entry is skipped, not traded
+        deadline := time.Now().Add(2 * time.Second)
+        for time.Now().Before(deadline) {
+                if _, scheduled := engine.watchlistScheduled.Load(entry.ID); !scheduled {
+                        break
+                }
+                time.Sleep(10 * time.Millisecond)
+        }
+        if !telegram.sentContaining(user.TelegramID, "GİRİŞ PLANLANDI") || !telegram.sentContaining(user.TelegramID, "GİRİŞ YAPILMADI") {
+                t.Errorf("unexpected messages: %v", telegram.sent(user.TelegramID))
+        }
+        if fake.requestCount("/api/v2/mix/order/place-order") != 0 {
+                t.Error("entered with the user snapshot from queue time")
+        }
+}